@@ -0,0 +1,36 @@
+package docker
+
+import "time"
+
+const (
+	// defaultStartBackoffInitial and defaultStartBackoffMax define the default [Options.StartBackoff] strategy.
+	defaultStartBackoffInitial = 100 * time.Millisecond
+	defaultStartBackoffMax     = 2 * time.Second
+)
+
+// Backoff returns the delay before the next check, given the number of checks which have already failed,
+// starting from 1. A custom strategy can be set as a function literal, e.g.
+// `func(attempt int) time.Duration { return time.Duration(attempt) * time.Second }`.
+type Backoff func(attempt int) time.Duration
+
+// ConstantBackoff returns a [Backoff] strategy waiting the given delay between checks.
+func ConstantBackoff(delay time.Duration) Backoff {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// ExponentialBackoff returns a [Backoff] strategy doubling the delay between checks starting from initial,
+// up to max.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			return max
+		}
+		return delay
+	}
+}
@@ -0,0 +1,80 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	var delays []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		delays = append(delays, backoff(attempt))
+	}
+	require.Equal(t, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second,
+	}, delays)
+	require.Equal(t, time.Second, backoff(1000))
+	require.Equal(t, 5*time.Second, ConstantBackoff(5*time.Second)(3))
+}
+
+func Test_StartBackoff(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	var delays []time.Duration
+	advance := sleepFn
+	sleepFn = func(d time.Duration) {
+		delays = append(delays, d)
+		advance(d)
+	}
+	running := []types.Container{mockedRunningContainer.asTypesContainer()}
+	tests := []struct {
+		name           string
+		backoff        Backoff
+		startTimeout   int
+		checksToStart  int
+		expectedDelays []time.Duration
+		expectedError  error
+	}{
+		{"default_started", nil, 3, 3, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, nil},
+		{"default_timeout", nil, 3, 0, []time.Duration{
+			100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond,
+			1500 * time.Millisecond,
+		}, errContainerStartTimeout},
+		{"constant_timeout", ConstantBackoff(time.Second), 3, 0, []time.Duration{
+			time.Second, time.Second, time.Second,
+		}, errContainerStartTimeout},
+		{"custom_started", func(attempt int) time.Duration {
+			return time.Duration(attempt) * 500 * time.Millisecond
+		}, 60, 4, []time.Duration{500 * time.Millisecond, time.Second, 1500 * time.Millisecond}, nil},
+		{"custom_capped_by_timeout", func(attempt int) time.Duration {
+			return time.Duration(attempt) * 700 * time.Millisecond
+		}, 2, 0, []time.Duration{700 * time.Millisecond, 1300 * time.Millisecond}, errContainerStartTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			delays = nil
+			values := []containerListMockValue{{mockedCreatedInContainerList, nil}}
+			for i := 1; i < test.checksToStart; i++ {
+				values = append(values, containerListMockValue{mockedCreatedInContainerList, nil})
+			}
+			if test.checksToStart > 0 {
+				values = append(values, containerListMockValue{running, nil})
+			}
+			mockedContainerListValues = newContainerListMockValues(values...)
+			c := NewContainerWithOptions(mockedImageName, Options{
+				Name:         mockedContainerName,
+				StartTimeout: test.startTimeout,
+				StartBackoff: test.backoff,
+			})
+			require.ErrorIs(t, c.Start(context.Background()), test.expectedError)
+			require.Equal(t, test.expectedDelays, delays)
+		})
+	}
+}
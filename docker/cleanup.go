@@ -0,0 +1,99 @@
+package docker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+const (
+	// labelManaged marks Docker objects created by the package.
+	labelManaged = "testutils.managed"
+	// labelRunID holds the run id set using SetRunID.
+	labelRunID = "testutils.run"
+)
+
+var (
+	// runID holds the run id applied to created containers, guarded by runIDMu.
+	runID   string
+	runIDMu sync.RWMutex
+
+	errCleanup = errors.New("cannot remove some of the containers")
+)
+
+// SetRunID sets the run id which is applied as a `testutils.run=<id>` label to all subsequently created containers.
+// CleanupAll removes only containers labeled with the current run id, so that concurrent CI jobs sharing one
+// Docker daemon do not remove each other's containers. Empty id resets the run id.
+func SetRunID(id string) {
+	runIDMu.Lock()
+	defer runIDMu.Unlock()
+	runID = id
+}
+
+// getRunID returns the current run id.
+func getRunID() string {
+	runIDMu.RLock()
+	defer runIDMu.RUnlock()
+	return runID
+}
+
+// containerLabels returns labels applied to the created Docker objects: the given custom labels and the package
+// ones, which take precedence.
+func containerLabels(custom map[string]string) map[string]string {
+	labels := make(map[string]string, len(custom)+2)
+	for key, value := range custom {
+		labels[key] = value
+	}
+	labels[labelManaged] = "true"
+	if id := getRunID(); len(id) > 0 {
+		labels[labelRunID] = id
+	}
+	return labels
+}
+
+// managedFilters returns filters matching Docker objects created by the package within the current run.
+func managedFilters() dockerContainerFilters.Args {
+	filters := dockerContainerFilters.NewArgs(dockerContainerFilters.Arg("label", labelManaged+"=true"))
+	if id := getRunID(); len(id) > 0 {
+		filters.Add("label", labelRunID+"="+id)
+	}
+	return filters
+}
+
+// CleanupAll force-removes all containers created by the package. If run id has been set using SetRunID,
+// only containers created within the run are removed. Removal continues past individual failures, which are
+// reported together with the names of the tests which have created the containers, if known.
+func CleanupAll(ctx context.Context) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	containers, err := c.listContainers(ctx, managedFilters())
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, container := range containers {
+		if err = c.forceRemoveContainer(ctx, container.ID); err != nil {
+			failed = append(failed, describeContainer(container.ID, container.Labels)+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Wrapf(errCleanup, "%v", failed)
+	}
+	return nil
+}
+
+// listContainers calls Docker client ContainerList method with the given filters.
+func (c *defaultClient) listContainers(ctx context.Context, filters dockerContainerFilters.Args) ([]types.Container, error) {
+	return c.handler.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: filters})
+}
+
+// forceRemoveContainer removes Docker container, even if it is running, together with its anonymous volumes.
+func (c *defaultClient) forceRemoveContainer(ctx context.Context, id string) error {
+	return c.handler.ContainerRemove(ctx, id, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+}
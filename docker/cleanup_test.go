@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runIDLabel(t *testing.T) {
+	defer SetRunID("")
+	tests := []struct {
+		name           string
+		runID          string
+		expectedLabels map[string]string
+	}{
+		{"no_run_id", "", map[string]string{labelManaged: "true"}},
+		{"run_id", "ci-job-42", map[string]string{labelManaged: "true", labelRunID: "ci-job-42"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			SetRunID(test.runID)
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{Name: mockedContainerName})
+			require.NoError(t, err)
+			require.Equal(t, test.expectedLabels, mockedContainerCreateConfig.Labels)
+		})
+	}
+}
+
+func Test_CleanupAll(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetRunID("")
+	errRemoveMock := errors.New("mockedContainerRemoveError")
+	listed := []types.Container{{ID: "first"}, {ID: "second"}, {ID: "third"}}
+	tests := []struct {
+		name            string
+		runID           string
+		removeErrors    map[string]error
+		expectedFilters []string
+		expectedError   error
+	}{
+		{"all", "", nil, []string{labelManaged + "=true"}, nil},
+		{"run_scoped", "ci-job-42", nil, []string{labelManaged + "=true", labelRunID + "=ci-job-42"}, nil},
+		{"partial_failure", "", map[string]error{"second": errRemoveMock}, []string{labelManaged + "=true"}, errCleanup},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			SetRunID(test.runID)
+			mockedContainerListValues = newContainerListMockValues(containerListMockValue{listed, nil})
+			mockedContainerRemoveErrors = test.removeErrors
+			require.ErrorIs(t, CleanupAll(context.Background()), test.expectedError)
+			require.ElementsMatch(t, test.expectedFilters, mockedContainerListOptions.Filters.Get("label"))
+			require.Equal(t, []string{"first", "second", "third"}, mockedRemovedContainers)
+			require.Equal(t, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}, *mockedContainerRemoveOptions)
+		})
+	}
+}
@@ -6,33 +6,71 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	dockerContainer "github.com/docker/docker/api/types/container"
 	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	dockerClient "github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
 )
 
 // client defines client methods.
 type client interface {
 	pullImage(ctx context.Context, name string) error
+	ensureImage(ctx context.Context, name string, policy PullPolicy) error
 	createContainer(ctx context.Context, image string, options *Options) (string, error)
 	startContainer(ctx context.Context, id string) error
 	createStartContainer(ctx context.Context, image string, options *Options) (string, error)
+	createStartContainerInfo(ctx context.Context, image string, options *Options) (ContainerInfo, error)
 	fetchContainerData(ctx context.Context, container *container) error
-	stopContainer(ctx context.Context, id string) error
+	stopContainer(ctx context.Context, id string, timeout *int) error
 	removeContainer(ctx context.Context, id string) error
-	stopRemoveContainer(ctx context.Context, id string) error
+	stopRemoveContainer(ctx context.Context, id string, timeout *int) error
 	execCommand(ctx context.Context, id string, command string, buffer *bytes.Buffer) error
+	execWithResult(ctx context.Context, id string, config types.ExecConfig, stdin io.Reader) (ExecResult, error)
+	inspectContainer(ctx context.Context, id string) (types.ContainerJSON, error)
+	updateContainer(ctx context.Context, id string, resources dockerContainer.Resources) error
+	containerLogs(ctx context.Context, id string, options types.ContainerLogsOptions) (string, error)
+	streamLogs(ctx context.Context, id string, options types.ContainerLogsOptions, dst io.Writer) error
+	containerTop(ctx context.Context, id string, psArgs string) ([][]string, error)
+	pathExists(ctx context.Context, id, path string) (bool, error)
+	exportContainer(ctx context.Context, id string, dst io.Writer) error
+	daemonHost() string
+	listContainers(ctx context.Context, filters dockerContainerFilters.Args) ([]types.Container, error)
+	listNetworks(ctx context.Context, filters dockerContainerFilters.Args) ([]types.NetworkResource, error)
+	listVolumes(ctx context.Context, filters dockerContainerFilters.Args) ([]*volume.Volume, error)
+	forceRemoveContainer(ctx context.Context, id string) error
+	createNetwork(ctx context.Context, name string, options NetworkOptions) (string, error)
+	removeNetwork(ctx context.Context, id string) error
+	connectNetwork(ctx context.Context, network, containerID string, aliases []string) error
+	ensureVolume(ctx context.Context, name string, labels map[string]string) (bool, error)
+	removeVolume(ctx context.Context, name string) error
+	pruneVolumes(ctx context.Context) error
+	commitContainer(ctx context.Context, id string) (string, error)
+	tagImage(ctx context.Context, source, target string) error
+	pushImage(ctx context.Context, ref string) error
+	imageMetadata(ctx context.Context, ref string) (ImageInfo, error)
+	hostGateway(ctx context.Context) (string, error)
 	close()
 }
 
 // defaultClient holds Docker client handler. Implements client interface.
 type defaultClient struct {
 	handler dockerClient.CommonAPIClient
+	// apiVersion and osType cache Docker daemon API version and operating system, guarded by versionMu.
+	apiVersion string
+	osType     string
+	versionMu  sync.Mutex
+	// images caches images metadata by image reference, guarded by imagesMu.
+	images   map[string]ImageInfo
+	imagesMu sync.Mutex
 }
 
 var (
@@ -42,6 +80,11 @@ var (
 	ok  bool
 	// newClientFn is used to simplify testability of newClient function.
 	newClientFn func(ops ...dockerClient.Opt) (*dockerClient.Client, error) = dockerClient.NewClientWithOpts
+	// sleepFn and nowFn are used to simplify testability of polling loops.
+	sleepFn = time.Sleep
+	nowFn   = time.Now
+	// goos holds the host operating system name. It is a variable to simplify testability of platform-specific code.
+	goos = runtime.GOOS
 )
 
 // newClient creates a new client object with a new Docker client handler.
@@ -52,6 +95,7 @@ func newClient() (client, error) {
 	c, err = newClientFn(
 		dockerClient.FromEnv,
 		dockerClient.WithAPIVersionNegotiation(),
+		dockerClient.WithHTTPHeaders(map[string]string{"User-Agent": getUserAgent()}),
 	)
 	if err != nil {
 		return nil, err
@@ -74,64 +118,135 @@ func (c *defaultClient) close() {
 	c.handler.Close()
 }
 
-// pullImage calls Docker client ImagePull method. Ignores method execution output.
+// pullImage calls Docker client ImagePull method. Ignores method execution output, except for daemon resource errors.
 func (c *defaultClient) pullImage(ctx context.Context, name string) error {
-	var reader io.ReadCloser
-	if reader, err = c.handler.ImagePull(ctx, name, types.ImagePullOptions{}); err != nil {
+	// The rate limit token is taken before the concurrency slot, so that pulls waiting for the rate limit do not
+	// block other pulls.
+	if limiter := getPullLimiter(); limiter != nil {
+		if err := limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	sem := getLimiter().pulls
+	if err := sem.acquire(ctx); err != nil {
 		return err
 	}
+	defer sem.release()
+	reader, err := c.handler.ImagePull(ctx, mirroredImage(name), types.ImagePullOptions{})
+	if err != nil {
+		return c.wrapDaemonError(ctx, err)
+	}
 	defer reader.Close()
-	io.ReadAll(reader) // nolint: errcheck
+	if err = pullStreamError(reader); err != nil {
+		return c.wrapDaemonError(ctx, err)
+	}
+	c.markPulled(name)
+	c.forgetImageMetadata(name)
 	return nil
 }
 
 // createContainer creates a new Docker container and returns its id.
 func (c *defaultClient) createContainer(ctx context.Context, image string, options *Options) (string, error) {
-	var (
-		hostPortString, containerPortString string
-		healthcheck                         dockerContainer.HealthConfig
-	)
-	exposedPorts := make(nat.PortSet, len(options.ExposedPorts))
-	portBindings := make(nat.PortMap, len(options.ExposedPorts))
-	for _, port := range options.ExposedPorts {
-		if hostPortString, containerPortString, ok = strings.Cut(port, ":"); !ok {
-			return "", errIncorrectPortConfig
+	if err := waitHostDependencies(ctx, options.PreStartWait); err != nil {
+		return "", err
+	}
+	rendered, err := renderOptions(options)
+	if err != nil {
+		return "", err
+	}
+	if err = expandEnvironment(image, &rendered); err != nil {
+		return "", err
+	}
+	applyFakeTime(&rendered)
+	if rendered.AddHostGatewayAlias && rendered.EnableHostGateway {
+		return "", errHostGatewayConflict
+	}
+	if rendered.AddHostGatewayAlias {
+		host, err := c.hostGatewayExtraHost(ctx)
+		if err != nil {
+			return "", err
 		}
-		containerPort := nat.Port(containerPortString + "/tcp")
-		exposedPorts[containerPort] = struct{}{}
-		portBindings[containerPort] = []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: hostPortString}}
-	}
-	if len(options.Healthcheck) > 0 {
-		healthcheck.Test = strings.Split("CMD-SHELL "+options.Healthcheck, " ")
-		healthcheck.Retries = 29
-		healthcheck.StartPeriod = time.Second * 2
-		healthcheck.Interval = time.Second * 2
-		healthcheck.Timeout = time.Second * 10
-	}
-	if err := c.pullImage(ctx, image); err != nil {
+		if len(host) > 0 {
+			hosts := rendered.ExtraHosts
+			rendered.ExtraHosts = append(hosts[:len(hosts):len(hosts)], host)
+		}
+	}
+	applyNormalizedEnv(&rendered)
+	config, err := containerConfig(mirroredImage(image), &rendered)
+	if err != nil {
 		return "", err
 	}
-	resp, err := c.handler.ContainerCreate(
-		ctx,
-		&dockerContainer.Config{
-			Image:        image,
-			Env:          options.EnvironmentVariables,
-			ExposedPorts: exposedPorts,
-			Healthcheck:  &healthcheck,
-		},
-		&dockerContainer.HostConfig{PortBindings: portBindings},
-		nil, nil, options.Name,
-	)
+	if name := testNameLabel(ctx, options.TB); len(name) > 0 {
+		config.Labels[labelTest] = name
+	}
+	daemonOS, err := c.daemonOS(ctx)
+	if err != nil {
+		return "", err
+	}
+	hostConfig, err := containerHostConfig(&rendered, daemonOS)
+	if err != nil {
+		return "", err
+	}
+	if err = c.checkDaemonFeatures(ctx, hostConfig, options.StrictDaemonFeatures); err != nil {
+		return "", err
+	}
+	if err = c.ensureImage(ctx, image, options.PullPolicy); err != nil {
+		return "", err
+	}
+	if err = c.applyImageMetadata(ctx, image, &rendered, config, hostConfig); err != nil {
+		return "", err
+	}
+	if err = c.checkImage(ctx, image, options); err != nil {
+		return "", err
+	}
+	resp, err := c.containerCreate(ctx, config, hostConfig, containerNetworkingConfig(&rendered), options.Name)
 	if err != nil {
 		return "", err
 	}
+	for _, name := range additionalNetworks(&rendered) {
+		if err = c.connectNetwork(ctx, name, resp.ID, rendered.NetworkAliases[name]); err != nil {
+			return "", errors.Wrapf(err, "connecting container to network %s", name)
+		}
+	}
+	if len(options.Name) == 0 {
+		// Docker assigns a random name to the container. It is saved, so that the container can be looked up by name.
+		data, err := c.inspectContainer(ctx, resp.ID)
+		if err != nil {
+			return "", err
+		}
+		if data.ContainerJSONBase != nil {
+			options.Name = strings.TrimPrefix(data.Name, "/")
+		}
+	}
 
 	return resp.ID, nil
 }
 
-// startContainer calls Docker client ContainerStart method.
+// containerCreate calls Docker client ContainerCreate method respecting the creates concurrency limit.
+func (c *defaultClient) containerCreate(
+	ctx context.Context,
+	config *dockerContainer.Config,
+	hostConfig *dockerContainer.HostConfig,
+	networkingConfig *dockerNetwork.NetworkingConfig,
+	name string,
+) (dockerContainer.CreateResponse, error) {
+	sem := getLimiter().creates
+	if err := sem.acquire(ctx); err != nil {
+		return dockerContainer.CreateResponse{}, err
+	}
+	defer sem.release()
+	resp, err := c.handler.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, name)
+	return resp, c.wrapDaemonError(ctx, err)
+}
+
+// startContainer calls Docker client ContainerStart method respecting the starts concurrency limit.
 func (c *defaultClient) startContainer(ctx context.Context, id string) error {
-	return c.handler.ContainerStart(ctx, id, types.ContainerStartOptions{})
+	sem := getLimiter().starts
+	if err := sem.acquire(ctx); err != nil {
+		return err
+	}
+	defer sem.release()
+	return c.wrapDaemonError(ctx, c.handler.ContainerStart(ctx, id, types.ContainerStartOptions{}))
 }
 
 // createStartContainer creates a new Docker container and starts it. Returns created container id.
@@ -171,9 +286,10 @@ func (c *defaultClient) fetchContainerData(ctx context.Context, container *conta
 	return nil
 }
 
-// stopContainer calls Docker client ContainerStop method.
-func (c *defaultClient) stopContainer(ctx context.Context, id string) error {
-	return c.handler.ContainerStop(ctx, id, dockerContainer.StopOptions{})
+// stopContainer calls Docker client ContainerStop method. timeout sets the number of seconds to wait for
+// the container to stop gracefully before killing it. If it is nil, the daemon default is used.
+func (c *defaultClient) stopContainer(ctx context.Context, id string, timeout *int) error {
+	return c.handler.ContainerStop(ctx, id, dockerContainer.StopOptions{Timeout: timeout})
 }
 
 // removeContainer calls Docker client ContainerRemove method.
@@ -181,9 +297,9 @@ func (c *defaultClient) removeContainer(ctx context.Context, id string) error {
 	return c.handler.ContainerRemove(ctx, id, types.ContainerRemoveOptions{})
 }
 
-// stopRemoveContainer stops and removes Docker container.
-func (c *defaultClient) stopRemoveContainer(ctx context.Context, id string) error {
-	if err := c.stopContainer(ctx, id); err != nil {
+// stopRemoveContainer stops Docker container with the given stop timeout and removes it.
+func (c *defaultClient) stopRemoveContainer(ctx context.Context, id string, timeout *int) error {
+	if err := c.stopContainer(ctx, id, timeout); err != nil {
 		return err
 	}
 	return c.removeContainer(ctx, id)
@@ -205,10 +321,144 @@ func (c *defaultClient) execCommand(ctx context.Context, id string, command stri
 	}
 	defer resp.Close()
 
-	_, err = io.Copy(buffer, resp.Reader)
+	limited := newLimitedBuffer(buffer)
+	_, err = io.Copy(limited, resp.Reader)
+	limited.close()
+	return err
+}
+
+// execWithResult executes command in Docker container and returns its demultiplexed output and exit code.
+// If stdin is not nil, it is copied to the command standard input. If config.Tty is set, the command output
+// is not multiplexed and is returned as stdout.
+func (c *defaultClient) execWithResult(
+	ctx context.Context,
+	id string,
+	config types.ExecConfig,
+	stdin io.Reader,
+) (ExecResult, error) {
+	config.AttachStdout = true
+	config.AttachStderr = true
+	config.AttachStdin = stdin != nil
+	r, err := c.handler.ContainerExecCreate(ctx, id, config)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	resp, err := c.handler.ContainerExecAttach(ctx, r.ID, types.ExecStartCheck{Tty: config.Tty})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	if stdin != nil {
+		// Input is copied concurrently, so that a command writing output before reading all its input does not block.
+		copied := make(chan struct{})
+		defer func() { <-copied }()
+		go func() {
+			defer close(copied)
+			io.Copy(resp.Conn, stdin) // nolint: errcheck
+			resp.CloseWrite()         // nolint: errcheck
+		}()
+	}
+	defer resp.Close()
+
+	var stdout, stderr bytes.Buffer
+	limitedStdout, limitedStderr := newLimitedBuffer(&stdout), newLimitedBuffer(&stderr)
+	if config.Tty {
+		_, err = io.Copy(limitedStdout, resp.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(limitedStdout, limitedStderr, resp.Reader)
+	}
+	if err != nil {
+		return ExecResult{}, err
+	}
+	truncated := limitedStdout.close()
+	truncated = limitedStderr.close() || truncated
+	inspect, err := c.handler.ContainerExecInspect(ctx, r.ID)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return ExecResult{
+		ExitCode:  inspect.ExitCode,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: truncated,
+	}, nil
+}
+
+// inspectContainer calls Docker client ContainerInspect method.
+func (c *defaultClient) inspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	return c.handler.ContainerInspect(ctx, id)
+}
+
+// updateContainer calls Docker client ContainerUpdate method with the given resources limits.
+func (c *defaultClient) updateContainer(ctx context.Context, id string, resources dockerContainer.Resources) error {
+	_, err := c.handler.ContainerUpdate(ctx, id, dockerContainer.UpdateConfig{Resources: resources})
 	return err
 }
 
+// containerLogs calls Docker client ContainerLogs method and returns demultiplexed stdout and stderr output.
+func (c *defaultClient) containerLogs(ctx context.Context, id string, options types.ContainerLogsOptions) (string, error) {
+	reader, err := c.handler.ContainerLogs(ctx, id, options)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	buffer := bytes.Buffer{}
+	limited := newLimitedBuffer(&buffer)
+	if _, err = stdcopy.StdCopy(limited, limited, reader); err != nil {
+		return "", err
+	}
+	limited.close()
+	return buffer.String(), nil
+}
+
+// streamLogs calls Docker client ContainerLogs method and copies demultiplexed stdout and stderr output to dst
+// without buffering it.
+func (c *defaultClient) streamLogs(ctx context.Context, id string, options types.ContainerLogsOptions, dst io.Writer) error {
+	reader, err := c.handler.ContainerLogs(ctx, id, options)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = stdcopy.StdCopy(dst, dst, reader)
+	return err
+}
+
+// containerTop calls Docker client ContainerTop method and returns process table rows.
+// psArgs are passed to `ps` command in container, e.g. "aux".
+func (c *defaultClient) containerTop(ctx context.Context, id string, psArgs string) ([][]string, error) {
+	top, err := c.handler.ContainerTop(ctx, id, strings.Fields(psArgs))
+	if err != nil {
+		return nil, err
+	}
+	return top.Processes, nil
+}
+
+// pathExists calls Docker client ContainerStatPath method and reports whether the path exists in container.
+func (c *defaultClient) pathExists(ctx context.Context, id, path string) (bool, error) {
+	if _, err := c.handler.ContainerStatPath(ctx, id, path); err != nil {
+		if dockerClient.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// exportContainer calls Docker client ContainerExport method and copies container filesystem tar stream to dst.
+func (c *defaultClient) exportContainer(ctx context.Context, id string, dst io.Writer) error {
+	reader, err := c.handler.ContainerExport(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(dst, reader)
+	return err
+}
+
+// daemonHost returns Docker daemon host address the client is connected to.
+func (c *defaultClient) daemonHost() string {
+	return c.handler.DaemonHost()
+}
+
 // PullImage pulls a Docker image with the given name.
 func PullImage(ctx context.Context, name string) error {
 	if len(name) == 0 {
@@ -264,12 +514,17 @@ func fetchContainerData(ctx context.Context, container *container) error {
 
 // StopContainer stops Docker container.
 func StopContainer(ctx context.Context, id string) error {
+	return stopContainerWithTimeout(ctx, id, nil)
+}
+
+// stopContainerWithTimeout stops Docker container waiting for the given number of seconds before killing it.
+func stopContainerWithTimeout(ctx context.Context, id string, timeout *int) error {
 	c, err := getClient()
 	if err != nil {
 		return err
 	}
 	defer c.close()
-	return c.stopContainer(ctx, id)
+	return c.stopContainer(ctx, id, timeout)
 }
 
 // RemoveContainer removes Docker container.
@@ -279,17 +534,23 @@ func RemoveContainer(ctx context.Context, id string) error {
 		return err
 	}
 	defer c.close()
-	return c.stopContainer(ctx, id)
+	return c.removeContainer(ctx, id)
 }
 
 // StopRemoveContainer stops and removes Docker container.
 func StopRemoveContainer(ctx context.Context, id string) error {
+	return stopRemoveContainerWithTimeout(ctx, id, nil)
+}
+
+// stopRemoveContainerWithTimeout stops Docker container waiting for the given number of seconds before killing it,
+// and removes it.
+func stopRemoveContainerWithTimeout(ctx context.Context, id string, timeout *int) error {
 	c, err := getClient()
 	if err != nil {
 		return err
 	}
 	defer c.close()
-	return c.stopRemoveContainer(ctx, id)
+	return c.stopRemoveContainer(ctx, id, timeout)
 }
 
 // ExecCommand executes given shell command in Docker container.
@@ -301,3 +562,76 @@ func ExecCommand(ctx context.Context, id string, command string, buffer *bytes.B
 	defer c.close()
 	return c.execCommand(ctx, id, command, buffer)
 }
+
+// ExecWithResult executes command in Docker container and returns its output and exit code.
+func ExecWithResult(ctx context.Context, id string, config types.ExecConfig) (ExecResult, error) {
+	c, err := getClient()
+	if err != nil {
+		return ExecResult{}, err
+	}
+	defer c.close()
+	return c.execWithResult(ctx, id, config, nil)
+}
+
+// InspectContainer returns Docker container low-level information.
+func InspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	c, err := getClient()
+	if err != nil {
+		return types.ContainerJSON{}, err
+	}
+	defer c.close()
+	return c.inspectContainer(ctx, id)
+}
+
+// ContainerLogs returns Docker container stdout and stderr output.
+func ContainerLogs(ctx context.Context, id string) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	return c.containerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+}
+
+// ContainerTop returns processes running in Docker container as `ps` command output rows.
+// psArgs are passed to `ps` command, e.g. "aux", an empty value means the daemon default, "-ef".
+func ContainerTop(ctx context.Context, id string, psArgs string) ([][]string, error) {
+	c, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+	return c.containerTop(ctx, id, psArgs)
+}
+
+// ExportContainer writes Docker container filesystem as a tar stream to dst.
+func ExportContainer(ctx context.Context, id string, dst io.Writer) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.exportContainer(ctx, id, dst)
+}
+
+// UpdateContainerResources updates memory limit (in bytes) and CPU quota (in units of 1e-9 CPUs) of Docker container.
+// Zero values leave the corresponding limits unchanged.
+func UpdateContainerResources(ctx context.Context, id string, memBytes, nanoCPUs int64) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.updateContainer(ctx, id, resourcesLimits(memBytes, nanoCPUs))
+}
+
+// resourcesLimits returns Docker container resources with the given memory and CPU limits.
+// Memory swap limit is set equal to memory limit, so that the container cannot exceed it using swap.
+func resourcesLimits(memBytes, nanoCPUs int64) dockerContainer.Resources {
+	resources := dockerContainer.Resources{NanoCPUs: nanoCPUs}
+	if memBytes > 0 {
+		resources.Memory = memBytes
+		resources.MemorySwap = memBytes
+	}
+	return resources
+}
@@ -1,10 +1,18 @@
 package docker
 
 import (
+	"context"
 	"errors"
+	"runtime"
 	"testing"
 
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
 	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/require"
 )
 
@@ -43,3 +51,372 @@ func Test_NewClient(t *testing.T) {
 		})
 	}
 }
+
+func Test_createContainerMountDockerSocket(t *testing.T) {
+	defer func() { goos = runtime.GOOS }()
+	unixSocket := []mount.Mount{{Type: mount.TypeBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"}}
+	tests := []struct {
+		name          string
+		goos          string
+		daemonOS      string
+		options       Options
+		expectedMount []mount.Mount
+	}{
+		{"linux", "linux", "linux", Options{MountDockerSocket: true}, unixSocket},
+		{"darwin", "darwin", "linux", Options{MountDockerSocket: true}, unixSocket},
+		{"windows", "windows", "windows", Options{MountDockerSocket: true}, []mount.Mount{
+			{Type: mount.TypeNamedPipe, Source: `\\.\pipe\docker_engine`, Target: `\\.\pipe\docker_engine`},
+		}},
+		// Docker Desktop on Windows runs Linux containers by default.
+		{"windows_linux_daemon", "windows", "linux", Options{MountDockerSocket: true}, unixSocket},
+		{"os_not_reported", "linux", "", Options{MountDockerSocket: true}, unixSocket},
+		{"disabled", "linux", "linux", Options{}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			goos = test.goos
+			mockedDaemonInfo.OSType = test.daemonOS
+			l := &mockedLogger{}
+			SetLogger(l)
+			defer SetLogger(nil)
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMount, mockedContainerCreateHostConfig.Mounts)
+			require.Len(t, l.messages, len(test.expectedMount))
+		})
+	}
+}
+
+func Test_createContainerDNSAndMacAddress(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       Options
+		expectedError error
+	}{
+		{"dns_and_mac", Options{
+			DNSSearch:  []string{"corp.example.com", "test.example.com"},
+			DNSOptions: []string{"ndots:2"},
+			MacAddress: "02:42:ac:11:00:02",
+		}, nil},
+		{"not_set", Options{}, nil},
+		{"invalid_mac", Options{MacAddress: "02:42:ac:11:00"}, errInvalidMacAddress},
+		{"eui64_mac", Options{MacAddress: "02:42:ac:11:00:02:00:01"}, errInvalidMacAddress},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError != nil {
+				require.Nil(t, mockedContainerCreateConfig)
+				return
+			}
+			require.Equal(t, test.options.DNSSearch, mockedContainerCreateHostConfig.DNSSearch)
+			require.Equal(t, test.options.DNSOptions, mockedContainerCreateHostConfig.DNSOptions)
+			require.Equal(t, test.options.MacAddress, mockedContainerCreateConfig.MacAddress)
+		})
+	}
+}
+
+func Test_createContainerUser(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	_, err := c.createContainer(context.Background(), mockedImageName, &Options{User: "1000:1000"})
+	require.NoError(t, err)
+	require.Equal(t, "1000:1000", mockedContainerCreateConfig.User)
+}
+
+func Test_createContainerEnvExpansion(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       Options
+		expectedEnv   []string
+		expectedError error
+	}{
+		{
+			"references",
+			Options{Name: "db", EnvironmentVariables: []string{
+				"DB_HOST=localhost", "DB_PORT=5432",
+				"DATABASE_URL=postgres://${DB_HOST}:${DB_PORT}/${CONTAINER_NAME}",
+			}},
+			[]string{"DATABASE_URL=postgres://localhost:5432/db", "DB_HOST=localhost", "DB_PORT=5432"},
+			nil,
+		},
+		{
+			"image_and_escaped",
+			Options{EnvironmentVariables: []string{"IMAGE_NAME=${IMAGE}", "PASSWORD=pa$$${word}", "HOME"}},
+			[]string{"HOME", "IMAGE_NAME=" + mockedImageName, "PASSWORD=pa$${word}"},
+			nil,
+		},
+		{"unknown", Options{EnvironmentVariables: []string{"URL=http://${HOST}:${PORT}"}}, nil, errUnknownEnvVariable},
+		{"defined_later", Options{EnvironmentVariables: []string{"URL=http://${HOST}", "HOST=localhost"}}, nil, errUnknownEnvVariable},
+		{"generated_name", Options{EnvironmentVariables: []string{"NAME=${CONTAINER_NAME}"}}, nil, errUnknownEnvVariable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			env := append([]string{}, test.options.EnvironmentVariables...)
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				require.Equal(t, test.expectedEnv, mockedContainerCreateConfig.Env)
+			}
+			// The original options are not modified.
+			require.Equal(t, env, test.options.EnvironmentVariables)
+		})
+	}
+}
+
+func Test_createContainerPorts(t *testing.T) {
+	tests := []struct {
+		name             string
+		ports            []string
+		expectedBindings nat.PortMap
+		expectedError    error
+	}{
+		{
+			"multiple_host_ports",
+			[]string{"8080:80", "9090:80"},
+			nat.PortMap{"80/tcp": {{HostIP: "0.0.0.0", HostPort: "8080"}, {HostIP: "0.0.0.0", HostPort: "9090"}}},
+			nil,
+		},
+		{
+			"explicit_protocol",
+			[]string{"5353:53/udp", "5353:53"},
+			nat.PortMap{"53/udp": {{HostIP: "0.0.0.0", HostPort: "5353"}}, "53/tcp": {{HostIP: "0.0.0.0", HostPort: "5353"}}},
+			nil,
+		},
+		{"duplicate", []string{"8080:80", "8080:80"}, nil, errDuplicatePortConfig},
+		{"host_port_bound_twice", []string{"8080:80", "8080:8000"}, nil, errDuplicatePortConfig},
+		{"incorrect", []string{"80"}, nil, errIncorrectPortConfig},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{ExposedPorts: test.ports})
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				require.Equal(t, test.expectedBindings, mockedContainerCreateHostConfig.PortBindings)
+			}
+		})
+	}
+}
+
+func Test_createContainerCmd(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	_, err := c.createContainer(context.Background(), mockedImageName, &Options{Cmd: []string{"server", "start-dev"}})
+	require.NoError(t, err)
+	require.Equal(t, strslice.StrSlice{"server", "start-dev"}, mockedContainerCreateConfig.Cmd)
+}
+
+func Test_createContainerEntrypointAndRestartPolicy(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	_, err := c.createContainer(context.Background(), mockedImageName, &Options{Entrypoint: []string{"/bin/sh"}, RestartPolicy: "on-failure:3"})
+	require.NoError(t, err)
+	require.Equal(t, strslice.StrSlice{"/bin/sh"}, mockedContainerCreateConfig.Entrypoint)
+	require.Equal(t, dockerContainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}, mockedContainerCreateHostConfig.RestartPolicy)
+
+	_, err = c.createContainer(context.Background(), mockedImageName, &Options{RestartPolicy: "sometimes"})
+	require.ErrorIs(t, err, errInvalidRestartPolicy)
+}
+
+func Test_createContainerCgroupParentAndRuntime(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       Options
+		expectedError error
+	}{
+		{"not_set", Options{}, nil},
+		{"cgroupfs", Options{CgroupParent: "/testutils"}, nil},
+		{"systemd_and_runsc", Options{CgroupParent: "testutils.slice", Runtime: "runsc"}, nil},
+		{"containerd_runtime", Options{Runtime: "io.containerd.runc.v2"}, nil},
+		{"invalid_cgroup_parent", Options{CgroupParent: "test utils.slice"}, errInvalidCgroupParent},
+		{"invalid_runtime", Options{Runtime: "run sc"}, errInvalidRuntime},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				require.Equal(t, test.options.CgroupParent, mockedContainerCreateHostConfig.CgroupParent)
+				require.Equal(t, test.options.Runtime, mockedContainerCreateHostConfig.Runtime)
+			} else {
+				require.Nil(t, mockedContainerCreateHostConfig)
+			}
+		})
+	}
+}
+
+func Test_createContainerSecurityOpts(t *testing.T) {
+	tests := []struct {
+		name          string
+		securityOpts  []string
+		expectedError error
+	}{
+		{"not_set", nil, nil},
+		{"unconfined", []string{"seccomp=unconfined", "apparmor=unconfined"}, nil},
+		{"hardened", []string{"apparmor=docker-default", "label=level:s0:c100,c200", "no-new-privileges"}, nil},
+		{"no_new_privileges_value", []string{"no-new-privileges=true", "no-new-privileges:false"}, nil},
+		{"seccomp_profile", []string{`seccomp={"defaultAction":"SCMP_ACT_ALLOW"}`}, nil},
+		{"unknown_kind", []string{"privileged"}, errInvalidSecurityOpt},
+		{"empty_value", []string{"seccomp="}, errInvalidSecurityOpt},
+		{"invalid_no_new_privileges", []string{"no-new-privileges=maybe"}, errInvalidSecurityOpt},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{SecurityOpts: test.securityOpts})
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				require.Equal(t, test.securityOpts, mockedContainerCreateHostConfig.SecurityOpt)
+			} else {
+				require.Nil(t, mockedContainerCreateHostConfig)
+			}
+		})
+	}
+}
+
+func Test_createContainerInit(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name string
+		init *bool
+	}{
+		{"default", nil},
+		{"enabled", &enabled},
+		{"disabled", &disabled},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{Init: test.init})
+			require.NoError(t, err)
+			require.Equal(t, test.init, mockedContainerCreateHostConfig.Init)
+		})
+	}
+}
+
+func Test_createContainerHostGateway(t *testing.T) {
+	defer func() { goos = runtime.GOOS }()
+	tests := []struct {
+		name               string
+		goos               string
+		daemonOS           string
+		apiVersion         string
+		options            Options
+		expectedExtraHosts []string
+	}{
+		{"linux", "linux", "linux", "1.42", Options{EnableHostGateway: true, ExtraHosts: []string{"db:10.0.0.2"}},
+			[]string{"db:10.0.0.2", "host.docker.internal:host-gateway"}},
+		// Docker Desktop daemons run on Linux.
+		{"darwin", "darwin", "linux", "1.42", Options{EnableHostGateway: true, ExtraHosts: []string{"db:10.0.0.2"}},
+			[]string{"db:10.0.0.2", "host.docker.internal:host-gateway"}},
+		{"windows", "windows", "windows", "1.42", Options{EnableHostGateway: true}, nil},
+		{"linux_remote_windows_daemon", "linux", "windows", "1.42", Options{EnableHostGateway: true}, nil},
+		{"linux_disabled", "linux", "linux", "1.42", Options{ExtraHosts: []string{"db:10.0.0.2"}}, []string{"db:10.0.0.2"}},
+		{"linux_old_daemon", "linux", "linux", "1.40", Options{EnableHostGateway: true, ExtraHosts: []string{"db:10.0.0.2"}},
+			[]string{"db:10.0.0.2"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			goos = test.goos
+			mockedDaemonInfo.OSType = test.daemonOS
+			mockedServerAPIVersion = test.apiVersion
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedExtraHosts, mockedContainerCreateHostConfig.ExtraHosts)
+		})
+	}
+}
+
+func Test_createContainerNetworks(t *testing.T) {
+	aliases := map[string][]string{"frontend": {"web"}, "backend": {"api", "app"}}
+	tests := []struct {
+		name                   string
+		options                Options
+		expectedMode           string
+		expectedPrimaryAliases *network.NetworkingConfig
+		expectedConnects       []string
+		expectedSettings       []*network.EndpointSettings
+	}{
+		{"not_set", Options{}, "", nil, nil, nil},
+		{"first_is_primary", Options{Networks: []string{"frontend", "backend", "monitoring"}, NetworkAliases: aliases},
+			"frontend",
+			&network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{"frontend": {Aliases: []string{"web"}}}},
+			[]string{"backend " + mockedContainerID, "monitoring " + mockedContainerID},
+			[]*network.EndpointSettings{{Aliases: []string{"api", "app"}}, nil},
+		},
+		{"network_is_primary", Options{Network: "shared", Networks: []string{"frontend", "backend"}},
+			"shared",
+			nil,
+			[]string{"frontend " + mockedContainerID, "backend " + mockedContainerID},
+			[]*network.EndpointSettings{nil, nil},
+		},
+		{"network_listed", Options{Network: "backend", Networks: []string{"frontend", "backend"}, NetworkAliases: aliases},
+			"backend",
+			&network.NetworkingConfig{EndpointsConfig: map[string]*network.EndpointSettings{
+				"backend": {Aliases: []string{"api", "app"}},
+			}},
+			[]string{"frontend " + mockedContainerID},
+			[]*network.EndpointSettings{{Aliases: []string{"web"}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMode, string(mockedContainerCreateHostConfig.NetworkMode))
+			require.Equal(t, test.expectedPrimaryAliases, mockedContainerCreateNetworkingConfig)
+			require.Equal(t, test.expectedConnects, mockedNetworkConnects)
+			require.Equal(t, test.expectedSettings, mockedNetworkConnectSettings)
+		})
+	}
+}
+
+func Test_RemoveContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedDaemonContainers = map[string]string{"db": "exited"}
+
+	require.NoError(t, RemoveContainer(context.Background(), "db"))
+	require.Equal(t, []string{"remove db"}, mockedDaemonCalls)
+	require.Nil(t, mockedContainerStopOptions)
+	require.Equal(t, types.ContainerRemoveOptions{}, *mockedContainerRemoveOptions)
+	require.NotContains(t, mockedDaemonContainers, "db")
+}
+
+func Test_RemoveStoppedContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedDaemonContainers = map[string]string{"db": "exited"}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: "db"})
+
+	require.NoError(t, c.Remove(context.Background()))
+	require.Equal(t, []string{"remove db"}, mockedDaemonCalls)
+	require.Nil(t, mockedContainerStopOptions)
+	require.Empty(t, mockedDaemonContainers)
+}
@@ -0,0 +1,122 @@
+package docker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Limits holds the maximum numbers of Docker operations of each kind performed concurrently by the package.
+// Zero value means unlimited.
+type Limits struct {
+	Pulls, Creates, Starts int
+}
+
+// defaultLimits are concurrency limits used until SetConcurrencyLimits is called.
+var defaultLimits = Limits{Pulls: 8, Creates: 32, Starts: 32}
+
+// semaphore limits the number of concurrently performed operations. nil semaphore does not limit operations.
+type semaphore chan struct{}
+
+// newSemaphore returns a semaphore allowing n concurrent operations. Returns nil if n is not positive.
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// acquire waits until an operation can be performed or the context is done.
+func (s semaphore) acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release marks an operation acquired earlier as finished.
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// limiter holds semaphores of the limited operations.
+type limiter struct {
+	pulls, creates, starts semaphore
+}
+
+var (
+	// currentLimiter holds the semaphores consulted by Docker operations, guarded by limiterMu.
+	currentLimiter = newLimiter(defaultLimits)
+	limiterMu      sync.RWMutex
+
+	errPrePull = errors.New("cannot pull some of the images")
+)
+
+// newLimiter returns a limiter with semaphores built from the given limits.
+func newLimiter(limits Limits) *limiter {
+	return &limiter{
+		pulls:   newSemaphore(limits.Pulls),
+		creates: newSemaphore(limits.Creates),
+		starts:  newSemaphore(limits.Starts),
+	}
+}
+
+// getLimiter returns the current limiter.
+func getLimiter() *limiter {
+	limiterMu.RLock()
+	defer limiterMu.RUnlock()
+	return currentLimiter
+}
+
+// SetConcurrencyLimits sets the maximum numbers of image pulls, container creations, and container starts performed
+// concurrently by the package, e.g. to avoid saturating the network of small CI runners. Zero value means unlimited.
+// Operations already in progress are not affected.
+func SetConcurrencyLimits(limits Limits) {
+	limiterMu.Lock()
+	defer limiterMu.Unlock()
+	currentLimiter = newLimiter(limits)
+}
+
+// PrePull pulls the given images concurrently, respecting the pulls concurrency limit.
+// Pulling continues past individual failures.
+func PrePull(ctx context.Context, images ...string) error {
+	for _, image := range images {
+		if len(image) == 0 {
+			return errEmptyImageName
+		}
+	}
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []string
+	)
+	for _, image := range images {
+		wg.Add(1)
+		go func(image string) {
+			defer wg.Done()
+			if err := c.pullImage(ctx, image); err != nil {
+				mu.Lock()
+				failed = append(failed, image+": "+err.Error())
+				mu.Unlock()
+			}
+		}(image)
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		return errors.Wrapf(errPrePull, "%v", failed)
+	}
+	return nil
+}
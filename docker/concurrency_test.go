@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PrePullConcurrencyLimit(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetConcurrencyLimits(defaultLimits)
+	images := []string{"postgres", "redis", "nginx", "alpine", "busybox", "mysql"}
+	tests := []struct {
+		name                string
+		limits              Limits
+		expectedMaxInFlight int32
+	}{
+		{"limited", Limits{Pulls: 2}, 2},
+		{"single", Limits{Pulls: 1}, 1},
+		{"unlimited", Limits{}, int32(len(images))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImagePullDelay = time.Millisecond * 20
+			SetConcurrencyLimits(test.limits)
+			require.NoError(t, PrePull(context.Background(), images...))
+			if test.limits.Pulls > 0 {
+				require.Equal(t, test.expectedMaxInFlight, mockedImagePullsMaxInFlight)
+			} else {
+				require.Greater(t, mockedImagePullsMaxInFlight, int32(1))
+			}
+		})
+	}
+}
+
+func Test_PrePullErrors(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	require.ErrorIs(t, PrePull(context.Background(), "postgres", ""), errEmptyImageName)
+	mockedImagePullError = errInvalidImagePullMock
+	err := PrePull(context.Background(), "postgres", "redis")
+	require.ErrorIs(t, err, errPrePull)
+	require.Contains(t, err.Error(), "postgres: mockedInvalidImagePullError")
+	require.Contains(t, err.Error(), "redis: mockedInvalidImagePullError")
+}
+
+func Test_semaphoreContextDone(t *testing.T) {
+	sem := newSemaphore(1)
+	require.NoError(t, sem.acquire(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, sem.acquire(ctx), context.Canceled)
+	sem.release()
+	require.NoError(t, sem.acquire(context.Background()))
+}
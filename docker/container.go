@@ -3,34 +3,90 @@ package docker
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
 	"strings"
+	"sync"
+	"testing"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
 	"github.com/pkg/errors"
 )
 
 const (
 	containerStateRunning        = "running"
 	defaultContainerStartTimeout = 60
+	// removedPollInterval is the interval between container existence checks in WaitRemoved.
+	removedPollInterval = time.Millisecond * 200
+	// filePollInterval is the interval between container path checks in WaitForFile.
+	filePollInterval = time.Millisecond * 200
+	// oomPollInterval is the interval between container state checks in WaitForOOM.
+	oomPollInterval = time.Millisecond * 200
 )
 
 // Container defines container methods.
 type Container interface {
+	Name() string
 	Create(ctx context.Context) error
 	Start(ctx context.Context) error
 	CreateStart(ctx context.Context) error
 	Stop(ctx context.Context) error
 	Remove(ctx context.Context) error
 	StopRemove(ctx context.Context) error
+	EnsureStarted(ctx context.Context) error
 	HasStarted(ctx context.Context) (bool, error)
+	Exists(ctx context.Context) (bool, error)
+	WaitRemoved(ctx context.Context, timeout time.Duration) error
+	WaitForFile(ctx context.Context, path string, timeout time.Duration) error
+	WasOOMKilled(ctx context.Context) (bool, error)
+	WaitForOOM(ctx context.Context, timeout time.Duration) error
+	WaitForGRPCHealth(ctx context.Context, containerPort string, service string, timeout time.Duration) error
 	Exec(ctx context.Context, command string, buffer *bytes.Buffer) error
+	ExecAsRoot(ctx context.Context, cmd []string) (ExecResult, error)
+	ExecScript(ctx context.Context, script string) (ExecResult, error)
+	ExecRetry(ctx context.Context, command string, attempts int, delay time.Duration) (ExecResult, error)
+	InstallPackages(ctx context.Context, names ...string) error
+	UpdateResources(ctx context.Context, memBytes, nanoCPUs int64) error
+	Inspect(ctx context.Context) (types.ContainerJSON, error)
+	Logs(ctx context.Context) (string, error)
+	LogsWithOptions(ctx context.Context, options LogsOptions) (string, error)
+	LogsWithTimestamps(ctx context.Context) (string, error)
+	LogsGzip(ctx context.Context, dst io.Writer) error
+	RestartCount(ctx context.Context) (int, error)
+	Top(ctx context.Context, psArgs string) ([][]string, error)
+	Export(ctx context.Context, dst io.Writer) error
+	Snapshot(ctx context.Context, imageRef string) (string, error)
+	Env(ctx context.Context) (map[string]string, error)
+	Healthcheck(ctx context.Context) (*dockerContainer.HealthConfig, error)
+	MappedPort(ctx context.Context, containerPort string) (string, error)
+	Endpoint(ctx context.Context, containerPort string) (string, error)
+	HTTPEndpoint(ctx context.Context, containerPort string) (string, error)
+	HTTPSEndpoint(ctx context.Context, containerPort string) (string, error)
+	ValidateExposedPorts(ctx context.Context) error
 }
 
 // container holds container data. Implements Container interface.
 type container struct {
 	id, image, state, status string
 	options                  Options
+	// eventsMu serializes options.OnEvent callback invocations.
+	eventsMu sync.Mutex
+	// sidecars holds created sidecar containers and sidecarsNetwork holds the name of the network created for them.
+	sidecars        []*container
+	sidecarsNetwork string
+}
+
+// ExecResult holds command execution result.
+type ExecResult struct {
+	ExitCode       int
+	Stdout, Stderr string
+	// Truncated is set if Stdout or Stderr exceeded the limit set with [SetMaxOutputBytes] and has been truncated.
+	Truncated bool
 }
 
 // Options holds container optional attributes values which can be set on new container object creation.
@@ -38,63 +94,316 @@ type Options struct {
 	Name, Healthcheck                  string
 	EnvironmentVariables, ExposedPorts []string
 	StartTimeout                       int
+	// Cmd overrides the image default command, e.g. `["server", "start-dev"]`. Arguments are passed to the image
+	// entrypoint, if it is set.
+	Cmd []string
+	// Entrypoint overrides the image default entrypoint, e.g. `["/bin/sh", "-c"]`.
+	Entrypoint []string
+	// RestartPolicy sets Docker daemon restart policy in `docker run --restart` format: "no", "always",
+	// "unless-stopped", or "on-failure" with optional maximum retry count, e.g. "on-failure:3".
+	RestartPolicy string
+	// StartBackoff defines delays between container state checks while waiting for it to start, exponential
+	// from 100 milliseconds to 2 seconds by default. See [ConstantBackoff] and [ExponentialBackoff].
+	StartBackoff Backoff
+	// StopTimeout sets the number of seconds to wait for the container to stop gracefully on Stop and StopRemove
+	// before it is killed. Zero value means Docker daemon default, 10 seconds.
+	StopTimeout int
+	// HealthcheckInterval, HealthcheckTimeout, and HealthcheckStartPeriod override the healthcheck probe timing,
+	// 2 seconds, 10 seconds, and 2 seconds by default. HealthcheckRetries overrides the number of consecutive
+	// probe failures after which the container is considered unhealthy, 29 by default.
+	HealthcheckInterval, HealthcheckTimeout, HealthcheckStartPeriod time.Duration
+	HealthcheckRetries                                              int
+	// PostReadyDelay is the time Start waits after the service inside the container has started, e.g. for services
+	// which report readiness a few seconds before accepting connections.
+	PostReadyDelay time.Duration
+	// CgroupnsMode sets container cgroup namespace mode: "private" or "host". Requires Docker API 1.41 or later.
+	CgroupnsMode string
+	// CgroupParent sets the parent cgroup of the container, e.g. for resource accounting of test containers.
+	CgroupParent string
+	// Runtime sets the OCI runtime used to run the container, e.g. `runsc` for gVisor sandboxing. The runtime must be
+	// configured in Docker daemon.
+	Runtime string
+	// SecurityOpts holds container security options, e.g. "seccomp=unconfined", "apparmor=docker-default",
+	// "label=disable", or "no-new-privileges", to test hardened or unconfined workloads. Custom seccomp profiles
+	// are passed as JSON content, e.g. "seccomp={...}", not as file paths.
+	SecurityOpts []string
+	// Init runs an init process, tini, as PID 1 in the container, which forwards signals and reaps zombie processes.
+	// Nil value means Docker daemon default.
+	Init *bool
+	// StrictDaemonFeatures makes container creation fail if Docker daemon does not support some of the requested
+	// features. By default, unsupported features are dropped with a logged warning.
+	StrictDaemonFeatures bool
+	// MountDockerSocket mounts host Docker daemon socket into container, e.g. for Docker-in-Docker-style tests.
+	// Processes inside the container get full control over the host Docker daemon.
+	MountDockerSocket bool
+	// Mounts holds host paths bind mounted into container.
+	Mounts []Mount
+	// Isolation sets container isolation technology on Windows hosts: "default", "process", or "hyperv".
+	Isolation string
+	// OnEvent is called on container lifecycle events. It is never called concurrently for the same container.
+	OnEvent func(e LifecycleEvent)
+	// DNSSearch holds DNS search domains and DNSOptions holds DNS resolver options set in container
+	// `/etc/resolv.conf`, e.g. "ndots:2".
+	DNSSearch, DNSOptions []string
+	// MacAddress sets container MAC address, e.g. "02:42:ac:11:00:02".
+	MacAddress string
+	// User sets the user and, optionally, the group container processes run as, e.g. "postgres" or "1000:1000".
+	// By default, the image user is used.
+	User string
+	// ExtraHosts holds custom host-to-IP mappings added to container `/etc/hosts` in "host:ip" format.
+	ExtraHosts []string
+	// Labels holds custom labels applied to the container in addition to the ones set by the package.
+	Labels map[string]string
+	// PullPolicy defines when the image is pulled before container creation, [PullAlways] by default.
+	PullPolicy PullPolicy
+	// ImageCheck is called with the image metadata after the image is pulled and before the container is created,
+	// e.g. to enforce an image size budget with [MaxImageSize] or to run a vulnerability scanner. Container is not
+	// created if it returns an error.
+	ImageCheck func(ctx context.Context, info ImageInfo) error
+	// PublishAllExposedPorts binds tcp and udp ports exposed by the image, which are not specified in ExposedPorts,
+	// to ephemeral host ports. Bound ports can be looked up using [Container.MappedPort].
+	PublishAllExposedPorts bool
+	// Network sets the name of the network the container is connected to instead of the default one.
+	Network string
+	// Networks holds names of networks the container is connected to, e.g. a frontend and a backend one. If Network
+	// is not set, the first one is the primary network set on creation. The others are connected after creation.
+	Networks []string
+	// NetworkAliases holds the container aliases in networks set in Network and Networks, by network name.
+	NetworkAliases map[string][]string
+	// IsolatedNetwork makes Create create a dedicated bridge network named after the container, e.g. `db-net`,
+	// and connect the container and its sidecars to it instead of the default one. The network is removed by Remove
+	// and StopRemove, or by Create if it fails. It cannot be combined with Network and Networks.
+	IsolatedNetwork bool
+	// Sidecars holds containers which are created and started before the container on a shared network,
+	// and stopped and removed after it. If Network is not set, a `<name>-net` network is created for them.
+	Sidecars []SidecarSpec
+	// EnableHostGateway makes the host reachable from container as `host.docker.internal` on all platforms.
+	// For Linux daemons, it requires Docker API 1.41 or later.
+	EnableHostGateway bool
+	// AddHostGatewayAlias makes the host reachable from container as `host.docker.internal` by adding an extra host
+	// entry with the address returned by [HostGatewayAddress]. Unlike EnableHostGateway, it does not require
+	// `host-gateway` support and works with rootless daemons. It cannot be combined with EnableHostGateway, as both
+	// add a `host.docker.internal` entry.
+	AddHostGatewayAlias bool
+	// FakeTime makes container processes believe the current time started at the given time, using libfaketime.
+	// The image must be glibc-based. libfaketime is preloaded from FakeTimeLibrary path in container which defaults
+	// to the `faketime` Debian package library path. If FakeTimeHostLibrary is set, the host shared object at this
+	// path is mounted into container at FakeTimeLibrary path.
+	FakeTime                             *time.Time
+	FakeTimeLibrary, FakeTimeHostLibrary string
+	// Shell overrides the shell used to run healthcheck command and ExecScript scripts, e.g. `["/bin/ash", "-c"]`.
+	// By default, healthcheck command is run with the image default shell and scripts with `/bin/sh -c`.
+	Shell []string
+	// KeepOnFailure keeps the container created by [StartNew] if it fails to start, e.g. to inspect its logs.
+	// By default, such a container is removed.
+	KeepOnFailure bool
+	// ForwardPorts makes WaitForGRPCHealth, and WaitReady, WaitConnectable, and WaitReadyTCP of database containers
+	// reach container ports through [ForwardPort], e.g. if Docker daemon is accessed over SSH and mapped ports are only
+	// reachable on the remote host. A single forward is opened per wait. Endpoints and database connection strings
+	// are not forwarded.
+	ForwardPorts bool
+	// GRPCTLSConfig makes WaitForGRPCHealth connect to the gRPC server with TLS using the given configuration.
+	// By default, plaintext connections are used.
+	GRPCTLSConfig *tls.Config
+	// PreStartWait lists services on host the container depends on. Container creation waits until they are
+	// available and fails naming the first unmet dependency otherwise.
+	PreStartWait []HostWait
+	// DebugHold makes Start block if the service inside the container does not start in time, so that a debugger
+	// can be attached or commands run in the container before it is torn down. The hold ends on interrupt signal,
+	// context cancellation, or after the duration set in TESTUTILS_DEBUG_HOLD_TIMEOUT environment variable,
+	// 10 minutes by default.
+	DebugHold bool
+	// TB, if set, is the test using the container. Debug hold instructions are written to its log, and the hold
+	// ends before its deadline, so that the container teardown still runs. The container is labeled with the test
+	// name, but its name is not derived from the test.
+	TB testing.TB
 }
 
+// defaultShell is the shell used to run ExecScript scripts if [Options.Shell] is not set.
+var defaultShell = []string{"/bin/sh", "-c"}
+
 var (
 	errEmptyContainerNameAndID = errors.New("empty container name and id")
 	errEmptyImageName          = errors.New("empty image name")
 	errContainerNotFound       = errors.New("container not found")
 	errContainerStartTimeout   = errors.New("container start timeout")
 	errIncorrectPortConfig     = errors.New(`incorrect port configuration, expected format is: "containerPort:hostPort"`)
+	errPortNotMapped           = errors.New("container port is not mapped to a host port")
+	errDuplicatePortConfig     = errors.New("duplicate port configuration")
+	errInvalidMacAddress       = errors.New("invalid MAC address")
+	errInvalidCgroupParent     = errors.New("invalid cgroup parent")
+	errInvalidRuntime          = errors.New("invalid container runtime name")
+	errInvalidSecurityOpt      = errors.New("invalid container security option")
+	errInvalidRestartPolicy    = errors.New("invalid container restart policy")
+	errWaitRemovedTimeout      = errors.New("container removal wait timeout")
+	errWaitForFileTimeout      = errors.New("container file wait timeout")
+	errWaitForOOMTimeout       = errors.New("container OOM kill wait timeout")
+	errExitedWithoutOOM        = errors.New("container has exited without being OOM killed")
+	errExecRetriesExhausted    = errors.New("command has not succeeded in the given number of attempts")
+	errIsolatedNetworkConflict = errors.New("isolated network cannot be combined with explicit networks")
+	errHostGatewayConflict     = errors.New("host gateway alias cannot be combined with host gateway entry")
+
+	// ErrContainerUnhealthy is returned by Start if the container healthcheck has been failing until start timeout.
+	ErrContainerUnhealthy = errors.New("container is unhealthy")
 )
 
 // Create creates a new Docker container and saves its id to the container object.
 func (c *container) Create(ctx context.Context) error {
-	if err = PullImage(ctx, c.image); err != nil {
-		return err
+	// The name is checked before sidecars are created and the image is pulled, so that nothing is left behind.
+	if err := validateContainerName(c.options.Name); err != nil {
+		return c.emitResult(PhaseCreated, err)
+	}
+	if len(c.options.Sidecars) > 0 || c.options.IsolatedNetwork {
+		if err := c.createSidecars(ctx); err != nil {
+			return c.emitResult(PhaseCreated, c.teardownIsolatedNetwork(ctx, err))
+		}
+	}
+	c.emit(PhasePullStarted, nil)
+	if err := PullImageWithPolicy(ctx, c.image, c.options.PullPolicy); err != nil {
+		return c.emitResult(PhasePullFinished, c.teardownIsolatedNetwork(ctx, err))
+	}
+	c.emit(PhasePullFinished, nil)
+	options := c.creationOptions()
+	// The image has been pulled above, so that each Create pulls it once.
+	options.PullPolicy = PullNever
+	id, err := CreateContainer(ctx, c.image, options)
+	// The name assigned by Docker daemon to an unnamed container is saved in options.
+	c.id, c.options.Name = id, options.Name
+	if err != nil {
+		err = c.teardownIsolatedNetwork(ctx, err)
 	}
-	c.id, err = CreateContainer(ctx, c.image, &c.options)
-	return err
+	return c.emitResult(PhaseCreated, err)
 }
 
 // Start starts Docker container and waits until it is in `running` state. In case healthcheck is defined for the container,
 // also waits for service inside the container to finish starting.
 func (c *container) Start(ctx context.Context) error {
-	var started bool
-	started, err = c.HasStarted(ctx)
+	started, err := c.launch(ctx)
 	if err != nil {
-		return err
+		return c.emitResult(PhaseStarted, err)
 	} else if started {
 		return nil
 	}
 
-	if err = StartContainer(ctx, c.id); err != nil {
-		return err
+	if !c.waitStarted(ctx) {
+		err = c.startTimeoutError(ctx)
+		if c.options.DebugHold {
+			c.debugHold(ctx, err)
+		}
+		return c.emitResult(PhaseHealthy, err)
+	}
+	if c.options.PostReadyDelay > 0 {
+		sleepFn(c.options.PostReadyDelay)
 	}
 
-	t := 0
-	for t < c.options.StartTimeout {
-		if started, _ = c.HasStarted(ctx); started {
-			break
+	return c.emitResult(PhaseHealthy, nil)
+}
+
+// waitStarted checks whether the container has started with delays set in [Options.StartBackoff] until it has
+// or the start timeout passes. Check errors are ignored, as the container state may not be known right after start.
+func (c *container) waitStarted(ctx context.Context) bool {
+	backoff := c.options.StartBackoff
+	if backoff == nil {
+		backoff = ExponentialBackoff(defaultStartBackoffInitial, defaultStartBackoffMax)
+	}
+	deadline := nowFn().Add(time.Duration(c.options.StartTimeout) * time.Second)
+	for attempt := 1; ; attempt++ {
+		if started, _ := c.HasStarted(ctx); started {
+			return true
 		}
-		time.Sleep(time.Second * 1)
-		t++
+		remaining := deadline.Sub(nowFn())
+		if remaining <= 0 {
+			return false
+		}
+		delay := backoff(attempt)
+		if delay > remaining {
+			delay = remaining
+		}
+		sleepFn(delay)
 	}
-	if !started {
-		return errContainerStartTimeout
+}
+
+// launch starts the container sidecars and the container itself without waiting for the service inside
+// the container to start. Returns true if the container has already been started.
+func (c *container) launch(ctx context.Context) (bool, error) {
+	if err := c.startSidecars(ctx); err != nil {
+		return false, err
 	}
+	started, err := c.HasStarted(ctx)
+	if err != nil || started {
+		return started, err
+	}
+	if err = StartContainer(ctx, c.id); err != nil {
+		return false, err
+	}
+	if err = c.checkFakeTime(ctx); err != nil {
+		return false, err
+	}
+	c.emit(PhaseStarted, nil)
+	return false, nil
+}
 
-	return nil
+// Name returns the container name. Before the container is created, the name set in [Options.Name] is returned,
+// which is empty if the name is not set. After creation, the name assigned by Docker daemon is returned
+// if the name has not been set.
+func (c *container) Name() string {
+	return c.options.Name
+}
+
+// isUnhealthy returns true if the last fetched container status reports failing healthcheck.
+func (c *container) isUnhealthy() bool {
+	return strings.Contains(c.status, "("+types.Unhealthy+")")
+}
+
+// startTimeoutError returns an error describing why the container has not started in time.
+// If the container has been marked unhealthy, [ErrContainerUnhealthy] with the last healthcheck probe details is
+// returned, otherwise, the container is considered to be still starting and a timeout error is returned.
+func (c *container) startTimeoutError(ctx context.Context) error {
+	if !c.isUnhealthy() {
+		return errContainerStartTimeout
+	}
+	data, err := c.Inspect(ctx)
+	if err != nil || data.ContainerJSONBase == nil || data.State == nil || data.State.Health == nil {
+		return ErrContainerUnhealthy
+	}
+	health := data.State.Health
+	var lastOutput string
+	if len(health.Log) > 0 {
+		lastOutput = strings.TrimSpace(health.Log[len(health.Log)-1].Output)
+	}
+	return errors.Wrapf(ErrContainerUnhealthy, "failing streak: %d, last probe output: %q", health.FailingStreak, lastOutput)
 }
 
 // CreateStart creates a new Docker container and starts it.
 func (c *container) CreateStart(ctx context.Context) error {
-	if err = c.Create(ctx); err != nil {
+	if err := c.Create(ctx); err != nil {
 		return err
 	}
 	return c.Start(ctx)
 }
 
+// EnsureStarted makes sure Docker container is running. If a container with the configured name is already running
+// and healthy, it is reused as is. A stopped container is started, and an unhealthy one is recreated.
+// If the container does not exist, it is created and started.
+func (c *container) EnsureStarted(ctx context.Context) error {
+	started, err := c.HasStarted(ctx)
+	switch {
+	case err == errContainerNotFound || err == errEmptyContainerNameAndID:
+		return c.CreateStart(ctx)
+	case err != nil:
+		return err
+	case c.isUnhealthy():
+		if err = c.StopRemove(ctx); err != nil {
+			return err
+		}
+		c.id = ""
+		return c.CreateStart(ctx)
+	case started:
+		return nil
+	}
+	return c.Start(ctx)
+}
+
 // fetchData fetches Docker container data and stores it in the container object.
 func (c *container) fetchData(ctx context.Context) error {
 	return fetchContainerData(ctx, c)
@@ -102,55 +411,429 @@ func (c *container) fetchData(ctx context.Context) error {
 
 // Stop stops Docker container.
 func (c *container) Stop(ctx context.Context) error {
-	if len(c.id) == 0 {
-		if err = c.fetchData(ctx); err != nil {
-			return err
-		}
+	err := c.resolveID(ctx)
+	if err == nil {
+		err = stopContainerWithTimeout(ctx, c.id, c.stopTimeout())
+	}
+	return c.emitResult(PhaseStopped, c.withSidecarsTeardown(ctx, err, (*container).Stop, false))
+}
+
+// stopTimeout returns the container stop timeout or nil if the daemon default is used.
+func (c *container) stopTimeout() *int {
+	if c.options.StopTimeout == 0 {
+		return nil
 	}
-	return StopContainer(ctx, c.id)
+	timeout := c.options.StopTimeout
+	return &timeout
 }
 
 // Remove removes Docker container.
 func (c *container) Remove(ctx context.Context) error {
 	// fetchData is called in any case, even if container id is non-empty, because fetchData can return errContainerNotFound.
 	// In this way, we avoid returning this error to the caller and allow him to proceed the program normal flow execution.
-	err = c.fetchData(ctx)
+	err := c.fetchData(ctx)
 	switch err {
 	case errContainerNotFound:
-		return nil
+		return c.withSidecarsTeardown(ctx, nil, (*container).Remove, true)
 	case nil:
-		return RemoveContainer(ctx, c.id)
+		return c.emitResult(PhaseRemoved, c.withSidecarsTeardown(ctx, RemoveContainer(ctx, c.id), (*container).Remove, true))
 	}
-	return err
+	return c.emitResult(PhaseRemoved, err)
 }
 
 // StopRemove stops Docker container and removes it.
 func (c *container) StopRemove(ctx context.Context) error {
 	// fetchData is called in any case, even if container id is non-empty, because fetchData can return errContainerNotFound.
 	// In this way, we avoid returning this error to the caller and allow him to proceed the program normal flow execution.
-	err = c.fetchData(ctx)
+	err := c.fetchData(ctx)
 	switch err {
 	case errContainerNotFound:
-		return nil
+		return c.withSidecarsTeardown(ctx, nil, (*container).StopRemove, true)
 	case nil:
-		return StopRemoveContainer(ctx, c.id)
+		err = c.withSidecarsTeardown(ctx, stopRemoveContainerWithTimeout(ctx, c.id, c.stopTimeout()), (*container).StopRemove, true)
+		if err != nil {
+			return c.emitResult(PhaseRemoved, err)
+		}
+		c.emit(PhaseStopped, nil)
+		c.emit(PhaseRemoved, nil)
+		return nil
 	}
-	return err
+	return c.emitResult(PhaseRemoved, err)
 }
 
 // HasStarted returns container state and healthiness check status. Can be used to check whether both, a container
 // and a service inside it have started.
-// Container is considered as started if its state is 'running' and not 'health: starting'.
+// Container is considered as started if its state is 'running' and its health is neither 'health: starting'
+// nor 'unhealthy'.
 func (c *container) HasStarted(ctx context.Context) (bool, error) {
-	if err = c.fetchData(ctx); err != nil {
+	if err := c.fetchData(ctx); err != nil {
+		return false, err
+	}
+	return c.state == containerStateRunning && !strings.Contains(c.status, "health: "+types.Starting) && !c.isUnhealthy(), nil
+}
+
+// Exists returns true if Docker container exists on host.
+func (c *container) Exists(ctx context.Context) (bool, error) {
+	switch err := c.fetchData(ctx); err {
+	case nil:
+		return true, nil
+	case errContainerNotFound:
+		return false, nil
+	default:
 		return false, err
 	}
-	return c.state == containerStateRunning && !strings.Contains(c.status, "health: "+types.Starting), nil
 }
 
-// Exec executes shell command in container.
+// WaitRemoved waits until the container no longer exists on host or the timeout expires. It is useful after Remove,
+// as the container may still be being torn down, and creating a new container with the same name would fail.
+func (c *container) WaitRemoved(ctx context.Context, timeout time.Duration) error {
+	deadline := nowFn().Add(timeout)
+	for {
+		exists, err := c.Exists(ctx)
+		switch {
+		case err != nil:
+			return err
+		case !exists:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !nowFn().Before(deadline):
+			return errors.Wrapf(errWaitRemovedTimeout, "%s", c.options.Name)
+		}
+		sleepFn(removedPollInterval)
+	}
+}
+
+// WaitForFile waits until the given path exists in container or the timeout expires. It is useful for services
+// signaling readiness with a sentinel file, e.g. a socket or a pid file.
+func (c *container) WaitForFile(ctx context.Context, path string, timeout time.Duration) error {
+	if err := c.resolveID(ctx); err != nil {
+		return err
+	}
+	cl, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer cl.close()
+	return poll(ctx, timeout, filePollInterval, errWaitForFileTimeout, func(ctx context.Context) error {
+		exists, err := cl.pathExists(ctx, c.id, path)
+		switch {
+		case err != nil:
+			return &stopPolling{err}
+		case !exists:
+			return errors.Errorf("%s does not exist", path)
+		}
+		return nil
+	})
+}
+
+// WasOOMKilled returns true if the container process has been killed for exceeding the container memory limit.
+func (c *container) WasOOMKilled(ctx context.Context) (bool, error) {
+	state, err := c.inspectState(ctx)
+	if err != nil || state == nil {
+		return false, err
+	}
+	return state.OOMKilled, nil
+}
+
+// WaitForOOM waits until the container is killed for exceeding its memory limit or the timeout expires, e.g. to
+// check that the memory limit is enforced. Fails early if the container exits without being OOM killed.
+func (c *container) WaitForOOM(ctx context.Context, timeout time.Duration) error {
+	return poll(ctx, timeout, oomPollInterval, errWaitForOOMTimeout, func(ctx context.Context) error {
+		state, err := c.inspectState(ctx)
+		switch {
+		case err != nil:
+			return &stopPolling{err}
+		case state != nil && state.OOMKilled:
+			return nil
+		case state != nil && (state.Status == "exited" || state.Status == "dead"):
+			return &stopPolling{errors.Wrapf(errExitedWithoutOOM, "%s: exit code %d", c.options.Name, state.ExitCode)}
+		}
+		return errors.Errorf("%s has not been OOM killed", c.options.Name)
+	})
+}
+
+// inspectState returns the container state, nil if it is not known.
+func (c *container) inspectState(ctx context.Context) (*types.ContainerState, error) {
+	data, err := c.Inspect(ctx)
+	if err != nil || data.ContainerJSONBase == nil {
+		return nil, err
+	}
+	return data.State, nil
+}
+
+// Exec executes shell command in container and writes its stdout followed by stderr to the buffer.
 func (c *container) Exec(ctx context.Context, command string, buffer *bytes.Buffer) error {
-	return ExecCommand(ctx, c.id, command, buffer)
+	result, err := ExecCommandWithOptions(ctx, c.id, ExecOptions{Cmd: []string{"bash", "-c", command}})
+	if err != nil {
+		return err
+	}
+	buffer.WriteString(result.Stdout)
+	buffer.WriteString(result.Stderr)
+	return nil
+}
+
+// resolveID fetches Docker container data if container id is unknown.
+func (c *container) resolveID(ctx context.Context) error {
+	if len(c.id) > 0 {
+		return nil
+	}
+	return c.fetchData(ctx)
+}
+
+// Inspect returns Docker container low-level information.
+func (c *container) Inspect(ctx context.Context) (types.ContainerJSON, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return types.ContainerJSON{}, err
+	}
+	return InspectContainer(ctx, c.id)
+}
+
+// Logs returns container stdout and stderr output.
+func (c *container) Logs(ctx context.Context) (string, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return "", err
+	}
+	return ContainerLogs(ctx, c.id)
+}
+
+// RestartCount returns the number of times the container has been restarted by Docker daemon.
+func (c *container) RestartCount(ctx context.Context) (int, error) {
+	data, err := c.Inspect(ctx)
+	if err != nil || data.ContainerJSONBase == nil {
+		return 0, err
+	}
+	return data.RestartCount, nil
+}
+
+// Top returns processes running in container as `ps` command output rows, e.g. for debugging a hung container.
+// psArgs are passed to `ps` command, e.g. "aux".
+func (c *container) Top(ctx context.Context, psArgs string) ([][]string, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return nil, err
+	}
+	return ContainerTop(ctx, c.id, psArgs)
+}
+
+// Export writes the container filesystem as a tar stream to dst, e.g. for analysis of a failed test container.
+func (c *container) Export(ctx context.Context, dst io.Writer) error {
+	if err := c.fetchData(ctx); err != nil {
+		return err
+	}
+	return ExportContainer(ctx, c.id, dst)
+}
+
+// Snapshot commits the current container state to a new image tagged with the given reference, e.g.
+// `myapp-seeded:test`, so that later tests can start from a prepared state instead of repeating slow setup.
+// Returns the image id.
+func (c *container) Snapshot(ctx context.Context, imageRef string) (string, error) {
+	if _, err := parseImageRef(imageRef); err != nil {
+		return "", err
+	}
+	if err := c.resolveID(ctx); err != nil {
+		return "", err
+	}
+	cl, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer cl.close()
+	imageID, err := cl.commitContainer(ctx, c.id)
+	if err != nil {
+		return "", err
+	}
+	if err = cl.tagImage(ctx, imageID, imageRef); err != nil {
+		return "", err
+	}
+	return imageID, nil
+}
+
+// Env returns the effective container environment, including variables set in the image. If a variable is set
+// several times, the last value is returned.
+func (c *container) Env(ctx context.Context) (map[string]string, error) {
+	data, err := c.Inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	env := map[string]string{}
+	if data.Config == nil {
+		return env, nil
+	}
+	for _, variable := range data.Config.Env {
+		name, value, _ := strings.Cut(variable, "=")
+		env[name] = value
+	}
+	return env, nil
+}
+
+// Healthcheck returns the effective container healthcheck configuration, including the one set in the image,
+// e.g. to check that the configured healthcheck command and timings took effect. Returns nil if the container
+// has no healthcheck configured.
+func (c *container) Healthcheck(ctx context.Context) (*dockerContainer.HealthConfig, error) {
+	data, err := c.Inspect(ctx)
+	if err != nil || data.Config == nil {
+		return nil, err
+	}
+	return data.Config.Healthcheck, nil
+}
+
+// MappedPort returns the host port bound to the given container port. Container port protocol defaults to `tcp`,
+// e.g. "5432" is the same as "5432/tcp".
+func (c *container) MappedPort(ctx context.Context, containerPort string) (string, error) {
+	_, port, err := c.mappedHostPort(ctx, containerPort)
+	return port, err
+}
+
+// Endpoint returns `host:port` address of the given container port on host.
+func (c *container) Endpoint(ctx context.Context, containerPort string) (string, error) {
+	host, port, err := c.mappedHostPort(ctx, containerPort)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// HTTPEndpoint returns `http://host:port` address of the given container port.
+func (c *container) HTTPEndpoint(ctx context.Context, containerPort string) (string, error) {
+	return c.endpoint(ctx, "http", containerPort)
+}
+
+// HTTPSEndpoint returns `https://host:port` address of the given container port.
+func (c *container) HTTPSEndpoint(ctx context.Context, containerPort string) (string, error) {
+	return c.endpoint(ctx, "https", containerPort)
+}
+
+// endpoint returns the given container port address on host, prefixed with scheme.
+func (c *container) endpoint(ctx context.Context, scheme, containerPort string) (string, error) {
+	address, err := c.Endpoint(ctx, containerPort)
+	if err != nil {
+		return "", err
+	}
+	return scheme + "://" + address, nil
+}
+
+// mappedHostPort returns host address and host port bound to the given container port.
+func (c *container) mappedHostPort(ctx context.Context, containerPort string) (string, string, error) {
+	data, err := c.Inspect(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	if !strings.Contains(containerPort, "/") {
+		containerPort += "/tcp"
+	}
+	if data.NetworkSettings == nil {
+		return "", "", errors.Wrap(errPortNotMapped, containerPort)
+	}
+	bindings := data.NetworkSettings.Ports[nat.Port(containerPort)]
+	if len(bindings) == 0 {
+		return "", "", errors.Wrap(errPortNotMapped, containerPort)
+	}
+	host, err := endpointHost(bindings[0].HostIP)
+	if err != nil {
+		return "", "", err
+	}
+	return host, bindings[0].HostPort, nil
+}
+
+// probeEndpoint returns a function resolving `host:port` address of the given container port used by readiness
+// probes, and a function releasing it. If [Options.ForwardPorts] is set, the port is reached through [ForwardPort].
+// The forward is opened on the first successful resolution and kept until release, so that all probes of a wait
+// share it.
+func (c *container) probeEndpoint(containerPort string) (func(ctx context.Context) (string, error), func()) {
+	if !c.options.ForwardPorts {
+		return func(ctx context.Context) (string, error) { return c.Endpoint(ctx, containerPort) }, func() {}
+	}
+	var address string
+	release := func() {}
+	resolve := func(ctx context.Context) (string, error) {
+		if len(address) == 0 {
+			forwarded, closeForward, err := ForwardPort(ctx, c, containerPort)
+			if err != nil {
+				return "", err
+			}
+			address, release = forwarded, closeForward
+		}
+		return address, nil
+	}
+	return resolve, func() { release() }
+}
+
+// endpointHost returns a host address which can be used to reach a port bound on the given host IP.
+// For remote Docker daemons, the daemon host name is used. Wildcard host IPs are replaced with `localhost`.
+func endpointHost(hostIP string) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	return daemonEndpointHost(c.daemonHost(), hostIP), nil
+}
+
+// daemonEndpointHost returns a host address which can be used to reach a port bound on the given host IP
+// of the given Docker daemon.
+func daemonEndpointHost(daemonHost, hostIP string) string {
+	if u, err := url.Parse(daemonHost); err == nil {
+		switch u.Scheme {
+		case "tcp", "http", "https", "ssh":
+			return u.Hostname()
+		}
+	}
+	switch hostIP {
+	case "", "0.0.0.0", "::":
+		return "localhost"
+	}
+	return hostIP
+}
+
+// ExecAsRoot executes command in container as root user. Command output and exit code are returned in [ExecResult].
+func (c *container) ExecAsRoot(ctx context.Context, cmd []string) (ExecResult, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return ExecResult{}, err
+	}
+	return ExecWithResult(ctx, c.id, types.ExecConfig{User: "0", Cmd: cmd})
+}
+
+// ExecScript executes the given shell script in container using [Options.Shell], `/bin/sh -c` by default,
+// and returns its output and exit code.
+func (c *container) ExecScript(ctx context.Context, script string) (ExecResult, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return ExecResult{}, err
+	}
+	shell := c.options.Shell
+	if len(shell) == 0 {
+		shell = defaultShell
+	}
+	return ExecWithResult(ctx, c.id, types.ExecConfig{Cmd: append(append([]string{}, shell...), script)})
+}
+
+// ExecRetry executes the given shell command in container using [Options.Shell] until it exits with zero code
+// or the given number of attempts is exhausted, waiting for delay between attempts. It is useful for setup
+// commands failing until the service inside the container warms up. The last execution result is returned.
+func (c *container) ExecRetry(ctx context.Context, command string, attempts int, delay time.Duration) (ExecResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := c.ExecScript(ctx, command)
+		switch {
+		case err != nil:
+			return result, err
+		case result.ExitCode == 0:
+			return result, nil
+		case attempt >= attempts:
+			return result, errors.Wrapf(errExecRetriesExhausted, "%d attempts, last exit code %d: %s",
+				attempt, result.ExitCode, strings.TrimSpace(result.Stderr))
+		case ctx.Err() != nil:
+			return result, ctx.Err()
+		}
+		sleepFn(delay)
+	}
+}
+
+// UpdateResources updates memory limit (in bytes) and CPU quota (in units of 1e-9 CPUs) of the running container.
+// Zero values leave the corresponding limits unchanged.
+func (c *container) UpdateResources(ctx context.Context, memBytes, nanoCPUs int64) error {
+	if err := c.fetchData(ctx); err != nil {
+		return err
+	}
+	return UpdateContainerResources(ctx, c.id, memBytes, nanoCPUs)
 }
 
 // NewContainer creates a new [Container] object.
@@ -165,3 +848,22 @@ func NewContainerWithOptions(image string, options Options) Container {
 	}
 	return &container{image: image, options: options}
 }
+
+// StartNew creates a new [Container] object, creates and starts the corresponding Docker container, and returns
+// the started container. If the created container fails to start, it is removed unless [Options.KeepOnFailure] is set.
+func StartNew(ctx context.Context, image string, options *Options) (Container, error) {
+	var o Options
+	if options != nil {
+		o = *options
+	}
+	c := NewContainerWithOptions(image, o).(*container)
+	if err := c.CreateStart(ctx); err != nil {
+		if len(c.id) > 0 && !c.options.KeepOnFailure {
+			if rollbackErr := stopRemoveContainerWithTimeout(ctx, c.id, c.stopTimeout()); rollbackErr != nil {
+				warnf("cannot remove container %s which failed to start: %v", c.id, rollbackErr)
+			}
+		}
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,276 @@
+package docker
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// containerConfig returns Docker container configuration built from the given image name and options.
+func containerConfig(image string, options *Options) (*dockerContainer.Config, error) {
+	exposedPorts, _, err := containerPorts(options.ExposedPorts, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = validateMacAddress(options.MacAddress); err != nil {
+		return nil, err
+	}
+	if err = validateContainerName(options.Name); err != nil {
+		return nil, err
+	}
+	return &dockerContainer.Config{
+		Image:        image,
+		Env:          options.EnvironmentVariables,
+		Cmd:          options.Cmd,
+		Entrypoint:   options.Entrypoint,
+		ExposedPorts: exposedPorts,
+		Healthcheck:  containerHealthcheck(options),
+		Labels:       containerLabels(options.Labels),
+		MacAddress:   options.MacAddress,
+		User:         options.User,
+	}, nil
+}
+
+// containerHostConfig returns Docker container host configuration built from the given options for a daemon running
+// on the given operating system.
+func containerHostConfig(options *Options, daemonOS string) (*dockerContainer.HostConfig, error) {
+	_, portBindings, err := containerPorts(options.ExposedPorts, bindingHostIP(daemonOS))
+	if err != nil {
+		return nil, err
+	}
+	if err = validateIsolation(options.Isolation); err != nil {
+		return nil, err
+	}
+	if err = validateCgroupParentAndRuntime(options.CgroupParent, options.Runtime); err != nil {
+		return nil, err
+	}
+	if err = validateSecurityOpts(options.SecurityOpts); err != nil {
+		return nil, err
+	}
+	mounts, err := containerMounts(options.Mounts)
+	if err != nil {
+		return nil, err
+	}
+	restartPolicy, err := containerRestartPolicy(options.RestartPolicy)
+	if err != nil {
+		return nil, err
+	}
+	hostConfig := dockerContainer.HostConfig{
+		PortBindings:  portBindings,
+		CgroupnsMode:  dockerContainer.CgroupnsMode(options.CgroupnsMode),
+		Isolation:     dockerContainer.Isolation(options.Isolation),
+		Mounts:        mounts,
+		DNSSearch:     options.DNSSearch,
+		DNSOptions:    options.DNSOptions,
+		ExtraHosts:    containerExtraHosts(options, daemonOS),
+		NetworkMode:   dockerContainer.NetworkMode(primaryNetwork(options)),
+		Init:          options.Init,
+		Runtime:       options.Runtime,
+		SecurityOpt:   options.SecurityOpts,
+		RestartPolicy: restartPolicy,
+	}
+	hostConfig.CgroupParent = options.CgroupParent
+	if options.MountDockerSocket {
+		warnf("Docker socket is mounted into container %q, processes inside it get full control over Docker daemon", options.Name)
+		hostConfig.Mounts = append(hostConfig.Mounts, dockerSocketMount(daemonOS))
+	}
+	return &hostConfig, nil
+}
+
+// primaryNetwork returns the network the container is connected to on creation: Network or, if it is not set,
+// the first one of Networks.
+func primaryNetwork(options *Options) string {
+	if len(options.Network) == 0 && len(options.Networks) > 0 {
+		return options.Networks[0]
+	}
+	return options.Network
+}
+
+// additionalNetworks returns networks set in Networks the container is connected to after creation.
+func additionalNetworks(options *Options) []string {
+	primary := primaryNetwork(options)
+	networks := make([]string, 0, len(options.Networks))
+	for _, name := range options.Networks {
+		if name != primary {
+			networks = append(networks, name)
+		}
+	}
+	return networks
+}
+
+// containerNetworkingConfig returns Docker container networking configuration holding the primary network aliases,
+// or nil if there are none.
+func containerNetworkingConfig(options *Options) *dockerNetwork.NetworkingConfig {
+	primary := primaryNetwork(options)
+	aliases := options.NetworkAliases[primary]
+	if len(primary) == 0 || len(aliases) == 0 {
+		return nil
+	}
+	return &dockerNetwork.NetworkingConfig{
+		EndpointsConfig: map[string]*dockerNetwork.EndpointSettings{primary: {Aliases: aliases}},
+	}
+}
+
+// containerRestartPolicy converts the given restart policy in `docker run --restart` format, e.g. "on-failure:3",
+// into Docker restart policy. Empty policy means the daemon default one.
+func containerRestartPolicy(policy string) (dockerContainer.RestartPolicy, error) {
+	name, retries, hasRetries := strings.Cut(policy, ":")
+	switch name {
+	case "", "no", "always", "unless-stopped":
+		if !hasRetries {
+			return dockerContainer.RestartPolicy{Name: name}, nil
+		}
+	case "on-failure":
+		if !hasRetries {
+			return dockerContainer.RestartPolicy{Name: name}, nil
+		}
+		if count, err := strconv.Atoi(retries); err == nil && count >= 0 {
+			return dockerContainer.RestartPolicy{Name: name, MaximumRetryCount: count}, nil
+		}
+	}
+	return dockerContainer.RestartPolicy{}, errors.Wrap(errInvalidRestartPolicy, policy)
+}
+
+// validateMacAddress checks that the given MAC address, if set, is a valid 48-bit MAC address.
+func validateMacAddress(address string) error {
+	if len(address) == 0 {
+		return nil
+	}
+	if hw, err := net.ParseMAC(address); err != nil || len(hw) != 6 {
+		return errors.Wrap(errInvalidMacAddress, address)
+	}
+	return nil
+}
+
+// runtimeName matches OCI runtime names, e.g. `runsc` or `io.containerd.runc.v2`.
+var runtimeName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// validateCgroupParentAndRuntime checks cgroup parent and runtime name syntax. Whether they exist is checked
+// by Docker daemon.
+func validateCgroupParentAndRuntime(cgroupParent, runtime string) error {
+	if strings.ContainsAny(cgroupParent, " \t\n\r") {
+		return errors.Wrapf(errInvalidCgroupParent, "%q", cgroupParent)
+	}
+	if len(runtime) > 0 && !runtimeName.MatchString(runtime) {
+		return errors.Wrapf(errInvalidRuntime, "%q", runtime)
+	}
+	return nil
+}
+
+// validateSecurityOpts checks that each security option is one of the kinds supported by Docker daemon:
+// `seccomp=`, `apparmor=`, and `label=` options with a value, and `no-new-privileges` with an optional boolean one.
+func validateSecurityOpts(securityOpts []string) error {
+	for _, option := range securityOpts {
+		key, value, hasValue := strings.Cut(option, "=")
+		if !hasValue && key != "no-new-privileges" {
+			key, value, hasValue = strings.Cut(option, ":")
+		}
+		switch key {
+		case "seccomp", "apparmor", "label":
+			if len(value) > 0 {
+				continue
+			}
+		case "no-new-privileges":
+			if _, err := strconv.ParseBool(value); !hasValue || err == nil {
+				continue
+			}
+		}
+		return errors.Wrapf(errInvalidSecurityOpt, "%q", option)
+	}
+	return nil
+}
+
+// hostGatewayHost is the host entry resolving to the host gateway IP. Docker Desktop resolves it natively,
+// for other Linux daemons, it has to be added to container `/etc/hosts` explicitly.
+const hostGatewayHost = "host.docker.internal:host-gateway"
+
+// containerExtraHosts returns container extra hosts, including the host gateway entry if it is enabled and
+// the daemon runs on Linux. Windows daemons do not support the `host-gateway` value.
+func containerExtraHosts(options *Options, daemonOS string) []string {
+	if !options.EnableHostGateway || daemonOS != "linux" {
+		return options.ExtraHosts
+	}
+	return append(append(make([]string, 0, len(options.ExtraHosts)+1), options.ExtraHosts...), hostGatewayHost)
+}
+
+// containerPorts parses exposed ports specified in "hostPort:containerPort" format. Container port protocol
+// defaults to `tcp`, e.g. "5353:53/udp" binds an udp port. Several host ports can be bound to one container port,
+// repeated specs and host ports bound to several container ports are rejected. Ports are bound to the given host IP.
+func containerPorts(ports []string, hostIP string) (nat.PortSet, nat.PortMap, error) {
+	exposedPorts := make(nat.PortSet, len(ports))
+	portBindings := make(nat.PortMap, len(ports))
+	hostPorts := make(map[string]nat.Port, len(ports))
+	for _, port := range ports {
+		hostPort, containerPortString, ok := strings.Cut(port, ":")
+		if !ok {
+			return nil, nil, errIncorrectPortConfig
+		}
+		containerPort := nat.Port(containerPortString)
+		if !strings.Contains(containerPortString, "/") {
+			containerPort += "/tcp"
+		}
+		if len(hostPort) > 0 {
+			hostKey := hostPort + "/" + containerPort.Proto()
+			if bound, found := hostPorts[hostKey]; found {
+				return nil, nil, errors.Wrapf(errDuplicatePortConfig, "host port %s is already bound to %s", hostPort, bound)
+			}
+			hostPorts[hostKey] = containerPort
+		}
+		exposedPorts[containerPort] = struct{}{}
+		portBindings[containerPort] = append(portBindings[containerPort], nat.PortBinding{HostIP: hostIP, HostPort: hostPort})
+	}
+	return exposedPorts, portBindings, nil
+}
+
+// containerHealthcheck returns Docker container healthcheck configuration running the options healthcheck command.
+// If shell is set, the command is run with it instead of the image default shell used by `CMD-SHELL` healthchecks.
+// Timing options override the default probe timing.
+func containerHealthcheck(options *Options) *dockerContainer.HealthConfig {
+	healthcheck := dockerContainer.HealthConfig{}
+	if command := options.Healthcheck; len(command) > 0 {
+		if len(options.Shell) > 0 {
+			healthcheck.Test = append(append([]string{"CMD"}, options.Shell...), command)
+		} else {
+			healthcheck.Test = []string{"CMD-SHELL", command}
+		}
+		healthcheck.Retries = orDefault(options.HealthcheckRetries, 29)
+		healthcheck.StartPeriod = orDefault(options.HealthcheckStartPeriod, time.Second*2)
+		healthcheck.Interval = orDefault(options.HealthcheckInterval, time.Second*2)
+		healthcheck.Timeout = orDefault(options.HealthcheckTimeout, time.Second*10)
+	}
+	return &healthcheck
+}
+
+// orDefault returns value if it is positive, and defaultValue otherwise.
+func orDefault[T int | time.Duration](value, defaultValue T) T {
+	if value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// bindingHostIP returns the host IP container ports are bound to by a daemon running on the given operating system.
+// For Windows daemons, the host IP is left empty, so that the daemon default is used.
+func bindingHostIP(daemonOS string) string {
+	if daemonOS == "windows" {
+		return ""
+	}
+	return "0.0.0.0"
+}
+
+// dockerSocketMount returns a mount binding Docker daemon socket into container: the named pipe for daemons running
+// on Windows and the Unix socket otherwise.
+func dockerSocketMount(daemonOS string) mount.Mount {
+	if daemonOS == "windows" {
+		return mount.Mount{Type: mount.TypeNamedPipe, Source: `\\.\pipe\docker_engine`, Target: `\\.\pipe\docker_engine`}
+	}
+	return mount.Mount{Type: mount.TypeBind, Source: "/var/run/docker.sock", Target: "/var/run/docker.sock"}
+}
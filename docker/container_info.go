@@ -0,0 +1,76 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// ContainerInfo holds data of a created and started Docker container.
+type ContainerInfo struct {
+	ID, Name, State string
+	// Ports maps container ports in "port/protocol" format, e.g. "5432/tcp", to their addresses on host
+	// in "host:port" format, e.g. "localhost:49153".
+	Ports map[string]string
+}
+
+// Endpoint returns the given container port address on host, e.g. `localhost:8080`. Container port protocol defaults
+// to `tcp`, e.g. "5432" is the same as "5432/tcp".
+func (i ContainerInfo) Endpoint(containerPort string) (string, error) {
+	if !strings.Contains(containerPort, "/") {
+		containerPort += "/tcp"
+	}
+	address, found := i.Ports[containerPort]
+	if !found {
+		return "", errors.Wrap(errPortNotMapped, containerPort)
+	}
+	return address, nil
+}
+
+// createStartContainerInfo creates a new Docker container, starts it, and returns its data.
+func (c *defaultClient) createStartContainerInfo(ctx context.Context, image string, options *Options) (ContainerInfo, error) {
+	id, err := c.createStartContainer(ctx, image, options)
+	if err != nil {
+		return ContainerInfo{ID: id}, err
+	}
+	data, err := c.inspectContainer(ctx, id)
+	if err != nil {
+		return ContainerInfo{ID: id}, err
+	}
+	return containerInfo(data, c.daemonHost()), nil
+}
+
+// containerInfo converts Docker container low-level information into [ContainerInfo].
+func containerInfo(data types.ContainerJSON, daemonHost string) ContainerInfo {
+	info := ContainerInfo{Ports: map[string]string{}}
+	if data.ContainerJSONBase != nil {
+		info.ID = data.ID
+		info.Name = strings.TrimPrefix(data.Name, "/")
+		if data.State != nil {
+			info.State = data.State.Status
+		}
+	}
+	if data.NetworkSettings == nil {
+		return info
+	}
+	for port, bindings := range data.NetworkSettings.Ports {
+		if len(bindings) > 0 {
+			info.Ports[string(port)] = net.JoinHostPort(daemonEndpointHost(daemonHost, bindings[0].HostIP), bindings[0].HostPort)
+		}
+	}
+	return info
+}
+
+// CreateStartContainerInfo creates a new Docker container, starts it, and returns its id, name, state,
+// and host addresses of mapped ports.
+func CreateStartContainerInfo(ctx context.Context, image string, options *Options) (ContainerInfo, error) {
+	c, err := getClient()
+	if err != nil {
+		return ContainerInfo{}, err
+	}
+	defer c.close()
+	return c.createStartContainerInfo(ctx, image, options)
+}
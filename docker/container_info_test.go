@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateStartContainerInfo(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:    mockedContainerID,
+			Name:  "/" + mockedContainerName,
+			State: &types.ContainerState{Status: "running", Running: true},
+		},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": {{HostIP: "0.0.0.0", HostPort: "49153"}},
+			"8008/tcp": {{HostIP: "127.0.0.1", HostPort: "8008"}},
+			"9187/tcp": nil,
+		}}},
+	}
+
+	info, err := CreateStartContainerInfo(context.Background(), mockedImageName, &Options{Name: mockedContainerName})
+	require.NoError(t, err)
+	require.Equal(t, ContainerInfo{
+		ID:    mockedContainerID,
+		Name:  mockedContainerName,
+		State: "running",
+		Ports: map[string]string{"5432/tcp": "localhost:49153", "8008/tcp": "127.0.0.1:8008"},
+	}, info)
+
+	endpoint, err := info.Endpoint("5432")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:49153", endpoint)
+	_, err = info.Endpoint("9187/tcp")
+	require.ErrorIs(t, err, errPortNotMapped)
+
+	resetMocks()
+	mockedContainerStartError = errInvalidImagePullMock
+	info, err = CreateStartContainerInfo(context.Background(), mockedImageName, &Options{Name: mockedContainerName})
+	require.ErrorIs(t, err, errInvalidImagePullMock)
+	require.Equal(t, mockedContainerID, info.ID)
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// containerNameChars is the pattern Docker daemon checks container names against, without the optional leading `/`.
+const containerNameChars = `[a-zA-Z0-9][a-zA-Z0-9_.-]+`
+
+var (
+	// ErrInvalidContainerName is returned by Create if the container name does not match Docker naming rules.
+	ErrInvalidContainerName = errors.New("invalid container name")
+
+	// containerNamePattern matches valid container names.
+	containerNamePattern = regexp.MustCompile(`^/?` + containerNameChars + `$`)
+	// unsafeNameChars matches characters replaced in sanitized container names.
+	unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+)
+
+// validateContainerName checks that the given container name, if set, matches Docker naming rules, so that
+// an invalid name is reported before any Docker object is created and without a daemon regex error.
+func validateContainerName(name string) error {
+	if len(name) == 0 || containerNamePattern.MatchString(name) {
+		return nil
+	}
+	return errors.Wrapf(ErrInvalidContainerName, "%q does not match %s", name, containerNameChars)
+}
+
+// SanitizeName returns the given string turned into a valid container name, e.g. to derive a name from
+// a test name: runs of characters other than letters, digits, `_`, `.`, and `-`, e.g. subtest separators, are
+// replaced with `_`, and leading characters other than letters and digits are removed, e.g. `TestA/case one` is
+// replaced with `TestA_case_one`. Names shorter than two characters, which Docker rejects, are prefixed with `c-`.
+// Valid names are returned unchanged.
+func SanitizeName(s string) string {
+	if containerNamePattern.MatchString(s) && !strings.HasPrefix(s, "/") {
+		return s
+	}
+	name := strings.TrimLeft(unsafeNameChars.ReplaceAllString(s, "_"), "_.-")
+	if len(name) < 2 {
+		return "c-" + name
+	}
+	return name
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_createContainerInvalidName(t *testing.T) {
+	tests := []struct {
+		name          string
+		containerName string
+		expectedError error
+	}{
+		{"not_set", "", nil},
+		{"valid", "orders-db_1.test", nil},
+		{"leading_slash", "/orders-db", nil},
+		{"space", "orders db", ErrInvalidContainerName},
+		{"subtest_separator", "TestOrders/Create", ErrInvalidContainerName},
+		{"leading_dash", "-orders", ErrInvalidContainerName},
+		{"single_character", "a", ErrInvalidContainerName},
+		{"non_ascii", "bestellungen-ü", ErrInvalidContainerName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{Name: test.containerName})
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError != nil {
+				require.ErrorContains(t, err, test.containerName)
+				require.ErrorContains(t, err, containerNameChars)
+				require.Nil(t, mockedContainerCreateConfig)
+			}
+		})
+	}
+}
+
+func Test_SanitizeName(t *testing.T) {
+	tests := []struct {
+		name, expected string
+	}{
+		{"orders-db_1.test", "orders-db_1.test"},
+		{"TestOrders/create order #1", "TestOrders_create_order_1"},
+		{"/orders-db", "orders-db"},
+		{"_.-orders", "orders"},
+		{"--", "c-"},
+		{"", "c-"},
+		{"a", "c-a"},
+		{"bestellungen-ü", "bestellungen-_"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sanitized := SanitizeName(test.name)
+			require.Equal(t, test.expected, sanitized)
+			require.NoError(t, validateContainerName(sanitized))
+			require.Equal(t, sanitized, SanitizeName(sanitized))
+		})
+	}
+}
+
+func Test_createInvalidNameWithSidecars(t *testing.T) {
+	resetMocks()
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	mockedDaemonContainers = map[string]string{}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: "orders db", Sidecars: []SidecarSpec{{Image: "shipper"}}})
+	require.ErrorIs(t, c.Create(context.Background()), ErrInvalidContainerName)
+	require.Empty(t, mockedDaemonCalls)
+	require.Equal(t, 0, mockedImagePulls)
+}
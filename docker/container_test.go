@@ -1,16 +1,29 @@
 package docker
 
 import (
+	"archive/tar"
+	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
 	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/require"
 )
@@ -23,59 +36,409 @@ type mockedDockerClient struct {
 // ImagePull is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ImagePull(
 	_ context.Context,
-	_ string,
+	ref string,
 	_ types.ImagePullOptions,
 ) (io.ReadCloser, error) {
-	return io.NopCloser(strings.NewReader("")), mockedImagePullError
+	mockedDaemonMu.Lock()
+	mockedImagePulls++
+	mockedImagePullRefs = append(mockedImagePullRefs, ref)
+	mockedDaemonMu.Unlock()
+	if mockedImagePullDelay > 0 {
+		inFlight := atomic.AddInt32(&mockedImagePullsInFlight, 1)
+		for {
+			maxInFlight := atomic.LoadInt32(&mockedImagePullsMaxInFlight)
+			if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&mockedImagePullsMaxInFlight, maxInFlight, inFlight) {
+				break
+			}
+		}
+		time.Sleep(mockedImagePullDelay)
+		atomic.AddInt32(&mockedImagePullsInFlight, -1)
+	}
+	return io.NopCloser(strings.NewReader(mockedImagePullStream)), mockedImagePullError
+}
+
+// ImageInspectWithRaw is a mocked [dockerClient.Client] type method. Returns a not found error
+// mockedImageInspectNotFound times, then mockedImageInspect.
+func (mdc *mockedDockerClient) ImageInspectWithRaw(_ context.Context, _ string) (types.ImageInspect, []byte, error) {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedImageInspectCalls++
+	if mockedImageInspectNotFound > 0 {
+		mockedImageInspectNotFound--
+		return types.ImageInspect{}, nil, errdefs.NotFound(errors.New("no such image"))
+	}
+	return mockedImageInspect, nil, nil
+}
+
+// DiskUsage is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) DiskUsage(_ context.Context, _ types.DiskUsageOptions) (types.DiskUsage, error) {
+	return mockedDiskUsage, mockedDiskUsageError
 }
 
 // ContainerCreate is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ContainerCreate(
 	_ context.Context,
-	_ *dockerContainer.Config,
-	_ *dockerContainer.HostConfig,
-	_ *network.NetworkingConfig,
+	config *dockerContainer.Config,
+	hostConfig *dockerContainer.HostConfig,
+	networkingConfig *network.NetworkingConfig,
 	_ *specs.Platform,
-	_ string,
+	name string,
 ) (dockerContainer.CreateResponse, error) {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedContainerCreateConfig = config
+	mockedContainerCreateHostConfig = hostConfig
+	mockedContainerCreateNetworkingConfig = networkingConfig
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "create "+name+" "+string(hostConfig.NetworkMode))
+		if err := mockedDaemonCreateErrors[name]; err != nil {
+			return dockerContainer.CreateResponse{}, err
+		}
+		mockedDaemonContainers[name] = "created"
+		return dockerContainer.CreateResponse{ID: name}, nil
+	}
 	return dockerContainer.CreateResponse{ID: mockedContainerID}, mockedContainerCreateError
 }
 
 // ContainerStart is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ContainerStart(
 	_ context.Context,
-	_ string,
+	id string,
 	_ types.ContainerStartOptions,
 ) error {
-	return nil
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "start "+id)
+		if err := mockedDaemonStartErrors[id]; err != nil {
+			return err
+		}
+		mockedDaemonContainers[id] = containerStateRunning
+		return nil
+	}
+	return mockedContainerStartError
 }
 
 // ContainerList is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ContainerList(
 	_ context.Context,
-	_ types.ContainerListOptions,
+	options types.ContainerListOptions,
 ) ([]types.Container, error) {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedContainerListOptions = &options
+	if mockedDaemonContainers != nil {
+		name := strings.TrimPrefix(options.Filters.Get("name")[0], "/")
+		if state, found := mockedDaemonContainers[name]; found {
+			return []types.Container{{ID: name, Names: []string{"/" + name}, State: state, Status: mockedDaemonStatuses[name]}}, nil
+		}
+		return []types.Container{}, nil
+	}
 	return mockedContainerListValues.next()
 }
 
 // ContainerStop is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ContainerStop(
 	_ context.Context,
-	_ string,
-	_ dockerContainer.StopOptions,
+	id string,
+	options dockerContainer.StopOptions,
 ) error {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedContainerStopOptions = &options
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "stop "+id)
+		mockedDaemonContainers[id] = "exited"
+	}
 	return nil
 }
 
 // ContainerRemove is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) ContainerRemove(
+	_ context.Context,
+	id string,
+	options types.ContainerRemoveOptions,
+) error {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedRemovedContainers = append(mockedRemovedContainers, id)
+	mockedContainerRemoveOptions = &options
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "remove "+id)
+		delete(mockedDaemonContainers, id)
+	}
+	return mockedContainerRemoveErrors[id]
+}
+
+// ContainerInspect is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) ContainerInspect(_ context.Context, id string) (types.ContainerJSON, error) {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	if sequence := mockedContainerInspectSequences[id]; len(sequence) > 0 {
+		if len(sequence) > 1 {
+			mockedContainerInspectSequences[id] = sequence[1:]
+		}
+		return sequence[0], nil
+	}
+	return mockedContainerInspect, nil
+}
+
+// ContainerTop is a mocked [dockerClient.Client] type method. Records ps arguments and returns mockedContainerTop.
+func (mdc *mockedDockerClient) ContainerTop(
+	_ context.Context,
+	_ string,
+	arguments []string,
+) (dockerContainer.ContainerTopOKBody, error) {
+	mockedContainerTopArguments = arguments
+	return mockedContainerTop, nil
+}
+
+// ContainerStatPath is a mocked [dockerClient.Client] type method. Returns a not found error for the first
+// mockedStatPathMisses calls.
+func (mdc *mockedDockerClient) ContainerStatPath(_ context.Context, _, path string) (types.ContainerPathStat, error) {
+	mockedStatPathCalls++
+	if mockedStatPathCalls <= mockedStatPathMisses {
+		return types.ContainerPathStat{}, errdefs.NotFound(errors.New("no such file: " + path))
+	}
+	return types.ContainerPathStat{Name: path}, nil
+}
+
+// ContainerExport is a mocked [dockerClient.Client] type method. Returns mockedContainerExport as the stream.
+func (mdc *mockedDockerClient) ContainerExport(_ context.Context, _ string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(mockedContainerExport)), nil
+}
+
+// ContainerCommit is a mocked [dockerClient.Client] type method. Commit options are recorded in mockedContainerCommits.
+func (mdc *mockedDockerClient) ContainerCommit(_ context.Context, id string, options types.ContainerCommitOptions) (types.IDResponse, error) {
+	mockedContainerCommits = append(mockedContainerCommits, mockedContainerCommit{id, options})
+	if mockedContainerCommitError != nil {
+		return types.IDResponse{}, mockedContainerCommitError
+	}
+	return types.IDResponse{ID: mockedCommittedImageID}, nil
+}
+
+// ContainerExecCreate is a mocked [dockerClient.Client] type method. Exec configuration is recorded and
+// the corresponding result is computed using mockedExecScript.
+func (mdc *mockedDockerClient) ContainerExecCreate(
+	_ context.Context,
+	_ string,
+	config types.ExecConfig,
+) (types.IDResponse, error) {
+	mockedExecConfigs = append(mockedExecConfigs, config)
+	result := ExecResult{}
+	if mockedExecScript != nil {
+		result = mockedExecScript(config)
+	}
+	mockedExecResults = append(mockedExecResults, result)
+	return types.IDResponse{ID: strconv.Itoa(len(mockedExecResults) - 1)}, nil
+}
+
+// ContainerExecAttach is a mocked [dockerClient.Client] type method.
+// Returns a multiplexed stream with mocked stdout and stderr, or a raw stream with mocked stdout if TTY is allocated.
+func (mdc *mockedDockerClient) ContainerExecAttach(
+	_ context.Context,
+	execID string,
+	check types.ExecStartCheck,
+) (types.HijackedResponse, error) {
+	result := mockedExecResults[mustAtoi(execID)]
+	buffer := bytes.Buffer{}
+	if check.Tty {
+		buffer.WriteString(result.Stdout)
+	} else {
+		stdcopy.NewStdWriter(&buffer, stdcopy.Stdout).Write([]byte(result.Stdout)) // nolint: errcheck
+		stdcopy.NewStdWriter(&buffer, stdcopy.Stderr).Write([]byte(result.Stderr)) // nolint: errcheck
+	}
+	return types.HijackedResponse{Conn: mockedConn{}, Reader: bufio.NewReader(&buffer)}, nil
+}
+
+// ContainerExecInspect is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) ContainerExecInspect(_ context.Context, execID string) (types.ContainerExecInspect, error) {
+	return types.ContainerExecInspect{ExecID: execID, ExitCode: mockedExecResults[mustAtoi(execID)].ExitCode}, nil
+}
+
+// mockedConn is a no-op network connection used in mocked hijacked responses.
+type mockedConn struct {
+	net.Conn
+}
+
+// Close is a mocked [net.Conn] method.
+func (mockedConn) Close() error {
+	return nil
+}
+
+// Write is a mocked [net.Conn] method. Records written data in mockedExecStdin.
+func (mockedConn) Write(p []byte) (int, error) {
+	return mockedExecStdin.Write(p)
+}
+
+// CloseWrite is a mocked half-close method of hijacked connections.
+func (mockedConn) CloseWrite() error {
+	mockedExecStdinClosed = true
+	return nil
+}
+
+// mustAtoi converts string to int, panics on error.
+func mustAtoi(s string) int {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
+
+// ContainerUpdate is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) ContainerUpdate(
+	_ context.Context,
+	_ string,
+	updateConfig dockerContainer.UpdateConfig,
+) (dockerContainer.ContainerUpdateOKBody, error) {
+	mockedContainerUpdateConfig = &updateConfig
+	return dockerContainer.ContainerUpdateOKBody{}, nil
+}
+
+// ContainerLogs is a mocked [dockerClient.Client] type method. Returns a multiplexed stream of mockedContainerLogs
+// lines of the requested streams, limited to the requested number of the last lines.
+func (mdc *mockedDockerClient) ContainerLogs(
 	_ context.Context,
 	_ string,
-	_ types.ContainerRemoveOptions,
+	options types.ContainerLogsOptions,
+) (io.ReadCloser, error) {
+	mockedContainerLogsOptions = &options
+	lines := make([]mockedLogLine, 0, len(mockedContainerLogs))
+	for _, line := range mockedContainerLogs {
+		if (line.stream == stdcopy.Stdout && options.ShowStdout) || (line.stream == stdcopy.Stderr && options.ShowStderr) {
+			lines = append(lines, line)
+		}
+	}
+	if tail, err := strconv.Atoi(options.Tail); err == nil && tail < len(lines) {
+		lines = lines[len(lines)-tail:]
+	}
+	buffer := bytes.Buffer{}
+	for _, line := range lines {
+		stdcopy.NewStdWriter(&buffer, line.stream).Write([]byte(line.text)) // nolint: errcheck
+	}
+	return io.NopCloser(&buffer), nil
+}
+
+// Info is a mocked [dockerClient.Client] type method. Returns mockedDaemonInfo.
+func (mdc *mockedDockerClient) Info(_ context.Context) (types.Info, error) {
+	return mockedDaemonInfo, nil
+}
+
+// NetworkInspect is a mocked [dockerClient.Client] type method. Returns mockedBridgeNetwork.
+func (mdc *mockedDockerClient) NetworkInspect(
+	_ context.Context,
+	_ string,
+	_ types.NetworkInspectOptions,
+) (types.NetworkResource, error) {
+	return mockedBridgeNetwork, nil
+}
+
+// VolumeInspect is a mocked [dockerClient.Client] type method. Returns not found error for volumes which are
+// not in mockedVolumes.
+func (mdc *mockedDockerClient) VolumeInspect(_ context.Context, name string) (volume.Volume, error) {
+	if !mockedVolumes[name] {
+		return volume.Volume{}, errdefs.NotFound(errors.New("no such volume"))
+	}
+	return volume.Volume{Name: name}, nil
+}
+
+// VolumeCreate is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) VolumeCreate(_ context.Context, options volume.CreateOptions) (volume.Volume, error) {
+	mockedVolumeCreateOptions = append(mockedVolumeCreateOptions, options)
+	return volume.Volume{Name: options.Name, Labels: options.Labels}, nil
+}
+
+// VolumeRemove is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) VolumeRemove(_ context.Context, name string, _ bool) error {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedRemovedVolumes = append(mockedRemovedVolumes, name)
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "volume remove "+name)
+	}
+	return mockedVolumeRemoveErrors[name]
+}
+
+// VolumeList is a mocked [dockerClient.Client] type method. Returns mockedVolumeList.
+func (mdc *mockedDockerClient) VolumeList(_ context.Context, listFilters filters.Args) (volume.ListResponse, error) {
+	mockedVolumeListFilters = &listFilters
+	return volume.ListResponse{Volumes: mockedVolumeList}, nil
+}
+
+// NetworkList is a mocked [dockerClient.Client] type method. Returns mockedNetworkList.
+func (mdc *mockedDockerClient) NetworkList(_ context.Context, options types.NetworkListOptions) ([]types.NetworkResource, error) {
+	mockedNetworkListFilters = &options.Filters
+	return mockedNetworkList, nil
+}
+
+// VolumesPrune is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) VolumesPrune(_ context.Context, pruneFilters filters.Args) (types.VolumesPruneReport, error) {
+	mockedVolumesPruneFilters = &pruneFilters
+	return types.VolumesPruneReport{}, mockedVolumesPruneError
+}
+
+// ServerVersion is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) ServerVersion(_ context.Context) (types.Version, error) {
+	return types.Version{APIVersion: mockedServerAPIVersion}, nil
+}
+
+// DaemonHost is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) DaemonHost() string {
+	return mockedDaemonHost
+}
+
+// NetworkCreate is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) NetworkCreate(
+	_ context.Context,
+	name string,
+	options types.NetworkCreate,
+) (types.NetworkCreateResponse, error) {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedNetworkCreateName = name
+	mockedNetworkCreateOptions = &options
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "network create "+name)
+	}
+	return types.NetworkCreateResponse{ID: mockedNetworkID}, nil
+}
+
+// NetworkRemove is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) NetworkRemove(_ context.Context, id string) error {
+	mockedDaemonMu.Lock()
+	defer mockedDaemonMu.Unlock()
+	mockedRemovedNetworks = append(mockedRemovedNetworks, id)
+	if mockedDaemonContainers != nil {
+		mockedDaemonCalls = append(mockedDaemonCalls, "network remove "+id)
+	}
+	return mockedNetworkRemoveErrors[id]
+}
+
+// NetworkConnect is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) NetworkConnect(
+	_ context.Context,
+	networkID, containerID string,
+	settings *network.EndpointSettings,
 ) error {
+	mockedNetworkConnects = append(mockedNetworkConnects, networkID+" "+containerID)
+	mockedNetworkConnectSettings = append(mockedNetworkConnectSettings, settings)
 	return nil
 }
 
+// ImageTag is a mocked [dockerClient.Client] type method.
+func (mdc *mockedDockerClient) ImageTag(_ context.Context, source, target string) error {
+	mockedImageTags = append(mockedImageTags, [2]string{source, target})
+	return nil
+}
+
+// ImagePush is a mocked [dockerClient.Client] type method. Returns mockedImagePushStream as push progress.
+func (mdc *mockedDockerClient) ImagePush(_ context.Context, ref string, options types.ImagePushOptions) (io.ReadCloser, error) {
+	mockedImagePushRef = ref
+	mockedImagePushOptions = &options
+	return io.NopCloser(strings.NewReader(mockedImagePushStream)), nil
+}
+
 // Close is a mocked [dockerClient.Client] type method.
 func (mdc *mockedDockerClient) Close() error {
 	return nil
@@ -95,10 +458,11 @@ type containerListMockValues struct {
 	index  int
 }
 
-// next iteratively returns containerListMockValues values to caller.
+// next iteratively returns containerListMockValues values to caller. The last value is returned repeatedly
+// once the list is exhausted.
 func (cl *containerListMockValues) next() ([]types.Container, error) {
-	if cl.size == 1 {
-		return cl.values[0].mockContainers, cl.values[0].mockError
+	if cl.index >= cl.size {
+		return cl.values[cl.size-1].mockContainers, cl.values[cl.size-1].mockError
 	}
 	value := cl.values[cl.index]
 	cl.index++
@@ -114,11 +478,184 @@ func newContainerListMockValues(values ...containerListMockValue) containerListM
 func resetMocks() {
 	mockedImagePullError = nil
 	mockedContainerCreateError = nil
+	mockedContainerStartError = nil
+	mockedContainerStopOptions = nil
+	mockedImagePullDelay = 0
+	mockedImageTags = nil
+	mockedContainerCommits = nil
+	mockedContainerCommitError = nil
+	mockedImagePushRef = ""
+	mockedImagePushOptions = nil
+	mockedImagePushStream = ""
+	mockedImagePullStream = ""
+	mockedImagePulls = 0
+	pulledImages = map[pulledImage]bool{}
+	mockedImagePullRefs = nil
+	mockedImageInspect = types.ImageInspect{}
+	mockedImageInspectCalls = 0
+	mockedImageInspectNotFound = 0
+	mockedDiskUsage = types.DiskUsage{}
+	mockedDiskUsageError = nil
+	mockedImagePullsInFlight = 0
+	mockedImagePullsMaxInFlight = 0
+	mockedContainerCreateConfig = nil
+	mockedContainerCreateHostConfig = nil
+	mockedServerAPIVersion = "1.42"
+	mockedDaemonHost = ""
+	mockedDaemonInfo = types.Info{}
+	mockedVolumes = nil
+	mockedVolumeCreateOptions = nil
+	mockedVolumesPruneFilters = nil
+	mockedVolumesPruneError = nil
+	mockedVolumeRemoveErrors = nil
+	mockedRemovedVolumes = nil
+	mockedVolumeList = nil
+	mockedVolumeListFilters = nil
+	mockedNetworkList = nil
+	mockedNetworkListFilters = nil
+	mockedNetworkRemoveErrors = nil
+	mockedBridgeNetwork = types.NetworkResource{}
+	mockedContainerInspect = types.ContainerJSON{}
+	mockedContainerInspectSequences = nil
+	mockedExecScript = nil
+	mockedExecConfigs = nil
+	mockedExecResults = nil
+	mockedExecStdin = bytes.Buffer{}
+	mockedExecStdinClosed = false
+	mockedContainerUpdateConfig = nil
+	mockedContainerTop = dockerContainer.ContainerTopOKBody{}
+	mockedContainerTopArguments = nil
+	mockedStatPathMisses = 0
+	mockedStatPathCalls = 0
+	mockedContainerExport = nil
+	mockedContainerLogs = nil
+	mockedContainerLogsOptions = nil
+	mockedContainerListOptions = nil
+	mockedRemovedContainers = nil
+	mockedContainerRemoveOptions = nil
+	mockedContainerRemoveErrors = nil
+	mockedNetworkCreateName = ""
+	mockedNetworkCreateOptions = nil
+	mockedRemovedNetworks = nil
+	mockedNetworkConnects = nil
+	mockedNetworkConnectSettings = nil
+	mockedContainerCreateNetworkingConfig = nil
+	mockedDaemonContainers = nil
+	mockedDaemonStatuses = nil
+	mockedDaemonCalls = nil
+	mockedDaemonStartErrors = nil
+	mockedDaemonCreateErrors = nil
 	mockedContainerListValues = newContainerListMockValues(
 		containerListMockValue{mockedRunningInContainerList, nil},
 	)
 }
 
+var (
+	// mockedExecScript computes mocked exec result for the given exec configuration.
+	mockedExecScript  func(config types.ExecConfig) ExecResult
+	mockedExecConfigs []types.ExecConfig
+	mockedExecResults []ExecResult
+	// mockedExecStdin holds data written to exec standard input and mockedExecStdinClosed reports whether
+	// the input has been closed.
+	mockedExecStdin       bytes.Buffer
+	mockedExecStdinClosed bool
+
+	mockedContainerUpdateConfig *dockerContainer.UpdateConfig
+
+	// mockedContainerInspectSequences holds per container id inspect results returned one by one.
+	// The last result is returned repeatedly, containers without results get mockedContainerInspect.
+	mockedContainerInspectSequences map[string][]types.ContainerJSON
+
+	mockedContainerExport []byte
+
+	mockedContainerTop          dockerContainer.ContainerTopOKBody
+	mockedContainerTopArguments []string
+	mockedStatPathMisses        int
+	mockedStatPathCalls         int
+
+	mockedContainerLogs        []mockedLogLine
+	mockedContainerLogsOptions *types.ContainerLogsOptions
+
+	mockedContainerStartError  error
+	mockedContainerStopOptions *dockerContainer.StopOptions
+
+	// mockedImagePullDelay makes mocked image pulls last for the given time, tracking the number of pulls in flight.
+	mockedImagePullDelay        time.Duration
+	mockedImagePullsInFlight    int32
+	mockedImagePullsMaxInFlight int32
+
+	mockedImageTags            [][2]string
+	mockedContainerCommits     []mockedContainerCommit
+	mockedContainerCommitError error
+	mockedImagePushRef         string
+	mockedImagePushOptions     *types.ImagePushOptions
+	mockedImagePushStream      string
+	mockedImagePullStream      string
+	mockedImagePulls           int
+	mockedImagePullRefs        []string
+
+	mockedImageInspect         types.ImageInspect
+	mockedImageInspectCalls    int
+	mockedImageInspectNotFound int
+
+	mockedDiskUsage      types.DiskUsage
+	mockedDiskUsageError error
+
+	mockedContainerListOptions   *types.ContainerListOptions
+	mockedRemovedContainers      []string
+	mockedContainerRemoveOptions *types.ContainerRemoveOptions
+	mockedContainerRemoveErrors  map[string]error
+
+	// mockedDaemonContainers enables a stateful mocked daemon: containers are identified by names, their states are
+	// kept in the map, and container and network operations are recorded in mockedDaemonCalls in call order.
+	mockedDaemonContainers  map[string]string
+	mockedDaemonCalls       []string
+	mockedDaemonStartErrors map[string]error
+	// mockedDaemonCreateErrors holds errors returned on creation of containers with the given names.
+	mockedDaemonCreateErrors map[string]error
+	// mockedDaemonStatuses holds listed container statuses by names, e.g. "Up 5 seconds (health: starting)".
+	mockedDaemonStatuses map[string]string
+	// mockedDaemonMu serializes mocked daemon calls made concurrently, e.g. by container groups.
+	mockedDaemonMu sync.Mutex
+
+	mockedNetworkID            = "mockedNetworkID"
+	mockedNetworkCreateName    string
+	mockedNetworkCreateOptions *types.NetworkCreate
+	mockedRemovedNetworks      []string
+	mockedNetworkConnects      []string
+	// mockedDaemonInfo holds daemon system information and mockedBridgeNetwork the default bridge network.
+	mockedDaemonInfo types.Info
+	// mockedVolumes holds names of existing volumes.
+	mockedVolumes             map[string]bool
+	mockedVolumeCreateOptions []volume.CreateOptions
+	mockedVolumesPruneFilters *filters.Args
+	mockedVolumesPruneError   error
+	mockedVolumeRemoveErrors  map[string]error
+	mockedNetworkRemoveErrors map[string]error
+	mockedBridgeNetwork       types.NetworkResource
+	mockedRemovedVolumes      []string
+	mockedVolumeList          []*volume.Volume
+	mockedVolumeListFilters   *filters.Args
+	mockedNetworkList         []types.NetworkResource
+	mockedNetworkListFilters  *filters.Args
+	// mockedNetworkConnectSettings holds endpoint settings passed to NetworkConnect calls.
+	mockedNetworkConnectSettings []*network.EndpointSettings
+	// mockedContainerCreateNetworkingConfig holds networking configuration passed to the last ContainerCreate call.
+	mockedContainerCreateNetworkingConfig *network.NetworkingConfig
+)
+
+// mockedLogLine holds a mocked container log line and the stream it is written to.
+type mockedLogLine struct {
+	stream stdcopy.StdType
+	text   string
+}
+
+// mockedContainerCommit holds a recorded mocked container commit.
+type mockedContainerCommit struct {
+	id      string
+	options types.ContainerCommitOptions
+}
+
 // mockedContainer holds mocked container data. It is used to store data in one object and
 // convert it to external 'types.Container' and internal 'container' types in tests.
 type mockedContainer struct {
@@ -152,8 +689,14 @@ var (
 	mockedContainerID                                = "mockedContainerID"
 	mockedContainerName                              = "mockedContainerName"
 	mockedImageName                                  = "mockedImageName"
+	mockedCommittedImageID                           = "sha256:mockedCommittedImageID"
 	mockedImagePullError, mockedContainerCreateError error
 	mockedContainerListValues                        containerListMockValues
+	mockedContainerCreateConfig                      *dockerContainer.Config
+	mockedContainerCreateHostConfig                  *dockerContainer.HostConfig
+	mockedServerAPIVersion                           string
+	mockedDaemonHost                                 string
+	mockedContainerInspect                           types.ContainerJSON
 	mockedCreatedContainer                           = mockedContainer{
 		id:    mockedContainerID,
 		name:  mockedContainerName,
@@ -196,6 +739,11 @@ var (
 		containerListMockValue{mockedCreatedInContainerList, nil},
 		containerListMockValue{mockedRunningInContainerList, nil},
 	)
+	mockedContainerListValuesEmptyCreatedRunning = newContainerListMockValues(
+		containerListMockValue{mockedEmptyContainerList, nil},
+		containerListMockValue{mockedCreatedInContainerList, nil},
+		containerListMockValue{mockedRunningInContainerList, nil},
+	)
 	mockedContainerListValuesEmpty = newContainerListMockValues(
 		containerListMockValue{mockedEmptyContainerList, nil},
 	)
@@ -258,6 +806,21 @@ func Test_container(t *testing.T) {
 			mockedContainerListValues = mockedContainerListValuesEmptyTechnical
 		}, mockedRunningContainer, Container.Remove, errContainerListTechnicalMock},
 
+		{"ensureStarted_already_running", nil, mockedRunningContainer, Container.EnsureStarted, nil},
+		{"ensureStarted_not_created", func() {
+			mockedContainerListValues = mockedContainerListValuesEmptyCreatedRunning
+		}, mockedRunningContainer, Container.EnsureStarted, nil},
+		{"ensureStarted_stopped", func() {
+			mockedContainerListValues = newContainerListMockValues(
+				containerListMockValue{mockedCreatedInContainerList, nil},
+				containerListMockValue{mockedCreatedInContainerList, nil},
+				containerListMockValue{mockedRunningInContainerList, nil},
+			)
+		}, mockedRunningContainer, Container.EnsureStarted, nil},
+		{"ensureStarted_data_fetch_error", func() {
+			mockedContainerListValues = mockedContainerListValuesEmptyTechnical
+		}, mockedRunningContainer, Container.EnsureStarted, errContainerListTechnicalMock},
+
 		{"stopRemove", nil, mockedRunningContainer, Container.StopRemove, nil},
 		{"stopRemove_empty_container_name_and_id", nil, mockedEmptyNameContainer, Container.StopRemove, errEmptyContainerNameAndID},
 		{"stopRemove_container_notfound", func() {
@@ -285,3 +848,712 @@ func Test_container(t *testing.T) {
 		})
 	}
 }
+
+// mockedMultiPortInspect is a mocked inspect result of a container with several published ports.
+var mockedMultiPortInspect = types.ContainerJSON{
+	ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, Name: "/" + mockedContainerName},
+	NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+		"80/tcp":   []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "8080"}},
+		"443/tcp":  []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: "8443"}},
+		"53/udp":   []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "5353"}},
+		"9000/tcp": []nat.PortBinding{},
+	}}},
+}
+
+func Test_endpoints(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name          string
+		containerPort string
+		// function points to Container endpoint methods.
+		function         func(_ Container, ctx context.Context, containerPort string) (string, error)
+		expectedEndpoint string
+		expectedError    error
+	}{
+		{"http", "80", Container.HTTPEndpoint, "http://localhost:8080", nil},
+		{"https", "443", Container.HTTPSEndpoint, "https://127.0.0.1:8443", nil},
+		{"http_explicit_protocol", "80/tcp", Container.HTTPEndpoint, "http://localhost:8080", nil},
+		{"mapped_port_udp", "53/udp", Container.MappedPort, "5353", nil},
+		{"not_published", "9000", Container.HTTPEndpoint, "", errPortNotMapped},
+		{"not_exposed", "5432", Container.MappedPort, "", errPortNotMapped},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = mockedMultiPortInspect
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			endpoint, err := test.function(c, context.Background(), test.containerPort)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedEndpoint, endpoint)
+		})
+	}
+}
+
+func Test_UpdateResources(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name              string
+		setupMocks        func()
+		memBytes          int64
+		nanoCPUs          int64
+		expectedResources *dockerContainer.Resources
+		expectedError     error
+	}{
+		{"memory_and_cpu", nil, 256 << 20, 500000000, &dockerContainer.Resources{
+			Memory: 256 << 20, MemorySwap: 256 << 20, NanoCPUs: 500000000,
+		}, nil},
+		{"cpu_only", nil, 0, 2000000000, &dockerContainer.Resources{NanoCPUs: 2000000000}, nil},
+		{"container_notfound", func() {
+			mockedContainerListValues = mockedContainerListValuesEmpty
+		}, 1 << 20, 0, nil, errContainerNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			if test.setupMocks != nil {
+				test.setupMocks()
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			require.ErrorIs(t, c.UpdateResources(context.Background(), test.memBytes, test.nanoCPUs), test.expectedError)
+			if test.expectedResources != nil {
+				require.Equal(t, *test.expectedResources, mockedContainerUpdateConfig.Resources)
+			} else {
+				require.Nil(t, mockedContainerUpdateConfig)
+			}
+		})
+	}
+}
+
+func Test_Logs(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerLogs = []mockedLogLine{
+		{stdcopy.Stdout, "starting\n"},
+		{stdcopy.Stderr, "warning: low memory\n"},
+		{stdcopy.Stdout, "ready\n"},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	logs, err := c.Logs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "starting\nwarning: low memory\nready\n", logs)
+	require.Equal(t, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}, *mockedContainerLogsOptions)
+}
+
+func Test_EnsureStartedReusesRunningContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.NoError(t, c.EnsureStarted(context.Background()))
+	require.Nil(t, mockedContainerCreateConfig)
+}
+
+func Test_EnsureStartedRecreatesUnhealthyContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	unhealthy := mockedRunningContainer
+	unhealthy.status = "Up 2 minutes (unhealthy)"
+	mockedContainerListValues = newContainerListMockValues(
+		containerListMockValue{[]types.Container{unhealthy.asTypesContainer()}, nil},
+		containerListMockValue{[]types.Container{unhealthy.asTypesContainer()}, nil},
+		containerListMockValue{mockedCreatedInContainerList, nil},
+		containerListMockValue{mockedRunningInContainerList, nil},
+	)
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.NoError(t, c.EnsureStarted(context.Background()))
+	require.NotNil(t, mockedContainerCreateConfig)
+}
+
+func Test_Exists(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name           string
+		listValues     containerListMockValues
+		expectedExists bool
+		expectedError  error
+	}{
+		{"exists", newContainerListMockValues(containerListMockValue{mockedRunningInContainerList, nil}), true, nil},
+		{"not_exists", mockedContainerListValuesEmpty, false, nil},
+		{"data_fetch_error", mockedContainerListValuesEmptyTechnical, false, errContainerListTechnicalMock},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerListValues = test.listValues
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			exists, err := c.Exists(context.Background())
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedExists, exists)
+		})
+	}
+}
+
+func Test_CreateCapturesGeneratedName(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, Name: "/brave_turing"},
+	}
+	c := NewContainer(mockedImageName)
+	require.Empty(t, c.Name())
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, "brave_turing", c.Name())
+
+	// Configured names are visible before creation and kept as is.
+	c = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.Equal(t, mockedContainerName, c.Name())
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, mockedContainerName, c.Name())
+}
+
+func Test_StartTimeoutDiagnosis(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+
+	withStatus := func(status string) []types.Container {
+		mc := mockedRunningContainer
+		mc.status = status
+		return []types.Container{mc.asTypesContainer()}
+	}
+	tests := []struct {
+		name           string
+		lastStatus     string
+		inspect        types.ContainerJSON
+		expectedError  error
+		expectedDetail string
+	}{
+		{"still_starting", "Up 60 seconds (health: starting)", types.ContainerJSON{}, errContainerStartTimeout, ""},
+		{"unhealthy", "Up 60 seconds (unhealthy)", types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+			State: &types.ContainerState{Health: &types.Health{
+				Status:        types.Unhealthy,
+				FailingStreak: 25,
+				Log: []*types.HealthcheckResult{
+					{ExitCode: 1, Output: "old output"},
+					{ExitCode: 2, Output: "connection refused\n"},
+				},
+			}},
+		}}, ErrContainerUnhealthy, `failing streak: 25, last probe output: "connection refused"`},
+		{"unhealthy_without_health_details", "Up 60 seconds (unhealthy)", types.ContainerJSON{}, ErrContainerUnhealthy, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = test.inspect
+			mockedContainerListValues = newContainerListMockValues(
+				containerListMockValue{mockedCreatedInContainerList, nil},
+				containerListMockValue{withStatus("Up 2 seconds (health: starting)"), nil},
+				containerListMockValue{withStatus(test.lastStatus), nil},
+			)
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, StartTimeout: 3})
+			err := c.Start(context.Background())
+			require.ErrorIs(t, err, test.expectedError)
+			require.Contains(t, err.Error(), test.expectedDetail)
+		})
+	}
+}
+
+func Test_Export(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	archive := bytes.Buffer{}
+	writer := tar.NewWriter(&archive)
+	content := []byte("listen_addresses = '*'\n")
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "etc/postgresql.conf", Mode: 0o644, Size: int64(len(content))}))
+	_, err := writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	mockedContainerExport = archive.Bytes()
+
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	exported := bytes.Buffer{}
+	require.NoError(t, c.Export(context.Background(), &exported))
+	require.Equal(t, mockedContainerID, c.(*container).id)
+
+	reader := tar.NewReader(&exported)
+	header, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, "etc/postgresql.conf", header.Name)
+	read, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, content, read)
+
+	mockedContainerListValues = mockedContainerListValuesEmpty
+	c = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.ErrorIs(t, c.Export(context.Background(), &exported), errContainerNotFound)
+}
+
+func Test_Snapshot(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name          string
+		imageRef      string
+		commitError   error
+		expectedError string
+		expectedTags  [][2]string
+	}{
+		{"snapshot", "myapp-seeded:test", nil, "", [][2]string{{mockedCommittedImageID, "myapp-seeded:test"}}},
+		{"commit_error", "myapp-seeded:test", errors.New("commit failed"), "commit failed", nil},
+		{"invalid_reference", "MyApp:test", nil, errInvalidImageReference.Error(), nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerCommitError = test.commitError
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			imageID, err := c.Snapshot(context.Background(), test.imageRef)
+			if len(test.expectedError) > 0 {
+				require.ErrorContains(t, err, test.expectedError)
+				require.Empty(t, imageID)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, mockedCommittedImageID, imageID)
+				require.Equal(t, []mockedContainerCommit{{mockedContainerID, types.ContainerCommitOptions{Pause: true}}}, mockedContainerCommits)
+			}
+			require.Equal(t, test.expectedTags, mockedImageTags)
+		})
+	}
+}
+
+func Test_StartPostReadyDelay(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	tests := []struct {
+		name          string
+		delay         time.Duration
+		expectedDelay time.Duration
+	}{
+		{"no_delay", 0, 0},
+		{"delay", 3 * time.Second, 3 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerListValues = newContainerListMockValues(
+				containerListMockValue{mockedCreatedInContainerList, nil},
+				containerListMockValue{mockedRunningInContainerList, nil},
+			)
+			times := map[LifecyclePhase]time.Time{}
+			c := NewContainerWithOptions(mockedImageName, Options{
+				Name:           mockedContainerName,
+				PostReadyDelay: test.delay,
+				OnEvent:        func(e LifecycleEvent) { times[e.Phase] = nowFn() },
+			})
+			require.NoError(t, c.Start(context.Background()))
+			require.Equal(t, test.expectedDelay, times[PhaseHealthy].Sub(times[PhaseStarted]))
+		})
+	}
+}
+
+func Test_RestartCount(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, RestartCount: 3},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	count, err := c.RestartCount(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	mockedContainerListValues = mockedContainerListValuesEmpty
+	c = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	_, err = c.RestartCount(context.Background())
+	require.ErrorIs(t, err, errContainerNotFound)
+}
+
+// mockedStateInspect returns mocked container low-level information with the given state.
+func mockedStateInspect(state *types.ContainerState) types.ContainerJSON {
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, State: state}}
+}
+
+func Test_WasOOMKilled(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name     string
+		inspect  types.ContainerJSON
+		expected bool
+	}{
+		{"oom_killed", mockedStateInspect(&types.ContainerState{Status: "exited", ExitCode: 137, OOMKilled: true}), true},
+		{"running", mockedStateInspect(&types.ContainerState{Status: "running", Running: true}), false},
+		{"no_state", mockedStateInspect(nil), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = test.inspect
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			killed, err := c.WasOOMKilled(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, test.expected, killed)
+		})
+	}
+}
+
+func Test_WaitForOOM(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	running := mockedStateInspect(&types.ContainerState{Status: "running", Running: true})
+	tests := []struct {
+		name          string
+		sequence      []types.ContainerJSON
+		expectedError error
+	}{
+		{"killed", []types.ContainerJSON{
+			running, running, mockedStateInspect(&types.ContainerState{Status: "exited", ExitCode: 137, OOMKilled: true}),
+		}, nil},
+		{"exited", []types.ContainerJSON{
+			running, mockedStateInspect(&types.ContainerState{Status: "exited", ExitCode: 1}),
+		}, errExitedWithoutOOM},
+		{"timeout", []types.ContainerJSON{running}, errWaitForOOMTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspectSequences = map[string][]types.ContainerJSON{mockedContainerID: test.sequence}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			require.ErrorIs(t, c.WaitForOOM(context.Background(), time.Second), test.expectedError)
+		})
+	}
+}
+
+func Test_ExecRetry(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	tests := []struct {
+		name             string
+		attempts         int
+		expectedCalls    int
+		expectedExitCode int
+		expectedError    error
+	}{
+		{"succeeds_third", 5, 3, 0, nil},
+		{"exhausted", 2, 2, 1, errExecRetriesExhausted},
+		{"single_attempt", 0, 1, 1, errExecRetriesExhausted},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(config types.ExecConfig) ExecResult {
+				if len(mockedExecConfigs) < 3 {
+					return ExecResult{ExitCode: 1, Stderr: "connection refused\n"}
+				}
+				return ExecResult{Stdout: "created"}
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			start := nowFn()
+			result, err := c.ExecRetry(context.Background(), "psql -c 'CREATE TABLE t (id int)'", test.attempts, time.Second)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedExitCode, result.ExitCode)
+			require.Len(t, mockedExecConfigs, test.expectedCalls)
+			require.Equal(t, []string{"/bin/sh", "-c", "psql -c 'CREATE TABLE t (id int)'"}, mockedExecConfigs[0].Cmd)
+			require.Equal(t, time.Duration(test.expectedCalls-1)*time.Second, nowFn().Sub(start))
+		})
+	}
+}
+
+func Test_Top(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	processes := [][]string{
+		{"root", "1", "0", "0", "10:00", "?", "00:00:01", "postgres"},
+		{"root", "25", "1", "0", "10:00", "?", "00:00:00", "postgres: checkpointer"},
+	}
+	tests := []struct {
+		name              string
+		psArgs            string
+		expectedArguments []string
+	}{
+		{"default", "", []string{}},
+		{"aux", "aux", []string{"aux"}},
+		{"several_arguments", "-o pid,comm", []string{"-o", "pid,comm"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerTop = dockerContainer.ContainerTopOKBody{
+				Titles:    []string{"UID", "PID", "PPID", "C", "STIME", "TTY", "TIME", "CMD"},
+				Processes: processes,
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			rows, err := c.Top(context.Background(), test.psArgs)
+			require.NoError(t, err)
+			require.Equal(t, processes, rows)
+			require.Equal(t, test.expectedArguments, mockedContainerTopArguments)
+		})
+	}
+}
+
+func Test_Env(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name        string
+		config      *dockerContainer.Config
+		expectedEnv map[string]string
+	}{
+		{"no_config", nil, map[string]string{}},
+		{
+			"image_and_container_env",
+			&dockerContainer.Config{Env: []string{"PATH=/usr/bin", "PGDATA=/var/lib/postgresql/data"}},
+			map[string]string{"PATH": "/usr/bin", "PGDATA": "/var/lib/postgresql/data"},
+		},
+		{
+			"duplicate_keys",
+			&dockerContainer.Config{Env: []string{"MODE=image", "MODE=container"}},
+			map[string]string{"MODE": "container"},
+		},
+		{
+			"value_with_equal_sign",
+			&dockerContainer.Config{Env: []string{"DSN=host=localhost user=postgres", "EMPTY="}},
+			map[string]string{"DSN": "host=localhost user=postgres", "EMPTY": ""},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+				Config:            test.config,
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			env, err := c.Env(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, test.expectedEnv, env)
+		})
+	}
+}
+
+func Test_Healthcheck(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	healthcheck := &dockerContainer.HealthConfig{
+		Test:     []string{"CMD-SHELL", "pg_isready -U postgres"},
+		Interval: time.Second,
+		Timeout:  5 * time.Second,
+		Retries:  10,
+	}
+	tests := []struct {
+		name                string
+		config              *dockerContainer.Config
+		expectedHealthcheck *dockerContainer.HealthConfig
+	}{
+		{"no_config", nil, nil},
+		{"no_healthcheck", &dockerContainer.Config{}, nil},
+		{"healthcheck", &dockerContainer.Config{Healthcheck: healthcheck}, healthcheck},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+				Config:            test.config,
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			actual, err := c.Healthcheck(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, test.expectedHealthcheck, actual)
+		})
+	}
+}
+
+func Test_StartNew(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	errStartMock := errors.New("mockedContainerStartError")
+	tests := []struct {
+		name            string
+		options         *Options
+		setupMocks      func()
+		expectedError   error
+		expectedRemoved []string
+	}{
+		{"success", &Options{Name: mockedContainerName}, func() {}, nil, nil},
+		{"nil_options", nil, func() {}, nil, nil},
+		{"pull_failure", &Options{Name: mockedContainerName}, func() {
+			mockedImagePullError = errInvalidImagePullMock
+		}, errInvalidImagePullMock, nil},
+		{"create_failure", &Options{Name: mockedContainerName}, func() {
+			mockedContainerCreateError = errDuplicateContainerNameMock
+		}, errDuplicateContainerNameMock, nil},
+		{"start_failure", &Options{Name: mockedContainerName}, func() {
+			mockedContainerListValues = mockedCreatedContainerListValues()
+			mockedContainerStartError = errStartMock
+		}, errStartMock, []string{mockedContainerID}},
+		{"start_timeout", &Options{Name: mockedContainerName, StartTimeout: 2}, func() {
+			mockedContainerListValues = mockedCreatedContainerListValues()
+		}, errContainerStartTimeout, []string{mockedContainerID}},
+		{"start_timeout_keep_on_failure", &Options{Name: mockedContainerName, StartTimeout: 2, KeepOnFailure: true}, func() {
+			mockedContainerListValues = mockedCreatedContainerListValues()
+		}, errContainerStartTimeout, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			test.setupMocks()
+			c, err := StartNew(context.Background(), mockedImageName, test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedRemoved, mockedRemovedContainers)
+			if test.expectedError != nil {
+				require.Nil(t, c)
+				return
+			}
+			require.Equal(t, mockedContainerID, c.(*container).id)
+			require.Equal(t, containerStateRunning, c.(*container).state)
+		})
+	}
+}
+
+// mockedCreatedContainerListValues returns mocked container list values which always report a created container.
+func mockedCreatedContainerListValues() containerListMockValues {
+	return newContainerListMockValues(containerListMockValue{mockedCreatedInContainerList, nil})
+}
+
+func Test_WaitRemoved(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	tests := []struct {
+		name          string
+		listValues    containerListMockValues
+		expectedError error
+	}{
+		{"removed", newContainerListMockValues(
+			containerListMockValue{mockedRunningInContainerList, nil},
+			containerListMockValue{mockedRunningInContainerList, nil},
+			containerListMockValue{mockedEmptyContainerList, nil},
+		), nil},
+		{"already_removed", mockedContainerListValuesEmpty, nil},
+		{"timeout", newContainerListMockValues(
+			containerListMockValue{mockedRunningInContainerList, nil},
+		), errWaitRemovedTimeout},
+		{"list_error", mockedContainerListValuesEmptyTechnical, errContainerListTechnicalMock},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerListValues = test.listValues
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			require.ErrorIs(t, c.WaitRemoved(context.Background(), time.Second*5), test.expectedError)
+		})
+	}
+}
+
+func Test_WaitForFile(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	tests := []struct {
+		name          string
+		misses        int
+		expectedCalls int
+		expectedError error
+	}{
+		{"exists", 0, 1, nil},
+		{"appears", 3, 4, nil},
+		{"timeout", 1000, 26, errWaitForFileTimeout},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedStatPathMisses = test.misses
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			err := c.WaitForFile(context.Background(), "/tmp/ready", time.Second*5)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedCalls, mockedStatPathCalls)
+		})
+	}
+}
+
+func Test_StopTimeout(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	timeout := 30
+	tests := []struct {
+		name            string
+		stopTimeout     int
+		function        func(_ Container, ctx context.Context) error
+		expectedTimeout *int
+	}{
+		{"stop_remove", 30, Container.StopRemove, &timeout},
+		{"stop", 30, Container.Stop, &timeout},
+		{"stop_remove_default", 0, Container.StopRemove, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, StopTimeout: test.stopTimeout})
+			require.NoError(t, test.function(c, context.Background()))
+			require.Equal(t, dockerContainer.StopOptions{Timeout: test.expectedTimeout}, *mockedContainerStopOptions)
+		})
+	}
+}
+
+func Test_HealthcheckTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  Options
+		expected dockerContainer.HealthConfig
+	}{
+		{"defaults", Options{Healthcheck: "pg_isready"}, dockerContainer.HealthConfig{
+			Test:        []string{"CMD-SHELL", "pg_isready"},
+			Interval:    2 * time.Second,
+			Timeout:     10 * time.Second,
+			StartPeriod: 2 * time.Second,
+			Retries:     29,
+		}},
+		{"custom", Options{
+			Healthcheck:            "pg_isready",
+			HealthcheckInterval:    5 * time.Second,
+			HealthcheckTimeout:     time.Second,
+			HealthcheckStartPeriod: time.Minute,
+			HealthcheckRetries:     3,
+		}, dockerContainer.HealthConfig{
+			Test:        []string{"CMD-SHELL", "pg_isready"},
+			Interval:    5 * time.Second,
+			Timeout:     time.Second,
+			StartPeriod: time.Minute,
+			Retries:     3,
+		}},
+		{"no_command", Options{HealthcheckRetries: 3}, dockerContainer.HealthConfig{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, &test.expected, containerHealthcheck(&test.options))
+		})
+	}
+}
+
+func Test_Shell(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name                string
+		shell               []string
+		expectedHealthcheck []string
+		expectedExec        []string
+	}{
+		{"default", nil, []string{"CMD-SHELL", "pg_isready -q"}, []string{"/bin/sh", "-c", "echo ok"}},
+		{"custom", []string{"/bin/ash", "-c"}, []string{"CMD", "/bin/ash", "-c", "pg_isready -q"}, []string{"/bin/ash", "-c", "echo ok"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, Healthcheck: "pg_isready -q", Shell: test.shell})
+			require.NoError(t, c.Create(context.Background()))
+			require.Equal(t, test.expectedHealthcheck, mockedContainerCreateConfig.Healthcheck.Test)
+			_, err := c.ExecScript(context.Background(), "echo ok")
+			require.NoError(t, err)
+			require.Equal(t, test.expectedExec, mockedExecConfigs[0].Cmd)
+		})
+	}
+}
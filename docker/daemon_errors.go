@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrDaemonOutOfDiskSpace is returned if Docker daemon fails to pull an image or to create or start a container
+	// because there is no space left on its storage.
+	ErrDaemonOutOfDiskSpace = errors.New("docker daemon is out of disk space")
+	// ErrDaemonOutOfMemory is returned if Docker daemon fails to create or start a container because it cannot
+	// allocate memory.
+	ErrDaemonOutOfMemory = errors.New("docker daemon is out of memory")
+)
+
+// daemonErrorSignature maps well-known Docker daemon error message parts to a typed error with a hint on how to fix it.
+type daemonErrorSignature struct {
+	substrings []string
+	kind       error
+	hint       string
+}
+
+// daemonErrorSignatures holds the list of recognized Docker daemon resource errors. Substrings are lower case.
+var daemonErrorSignatures = []daemonErrorSignature{
+	{
+		substrings: []string{"no space left on device", "not enough space on the disk", "disk quota exceeded"},
+		kind:       ErrDaemonOutOfDiskSpace,
+		hint:       "free up Docker storage, e.g. with `docker system prune`",
+	},
+	{
+		substrings: []string{"cannot allocate memory", "out of memory", "not enough memory resources"},
+		kind:       ErrDaemonOutOfMemory,
+		hint:       "increase memory available to Docker daemon or lower containers memory limits",
+	},
+}
+
+// daemonResourceError holds a Docker daemon resource error. It matches its kind, e.g. [ErrDaemonOutOfDiskSpace],
+// with errors.Is and unwraps to the original daemon error.
+type daemonResourceError struct {
+	kind  error
+	cause error
+	hint  string
+	// usage holds Docker disk usage summary. It is empty if unknown.
+	usage string
+}
+
+func (e *daemonResourceError) Error() string {
+	message := fmt.Sprintf("%s: %s; %s", e.kind, e.cause, e.hint)
+	if len(e.usage) > 0 {
+		message += "; current usage: " + e.usage
+	}
+	return message
+}
+
+func (e *daemonResourceError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *daemonResourceError) Unwrap() error {
+	return e.cause
+}
+
+// classifyDaemonError returns the signature of a recognized Docker daemon resource error or nil.
+func classifyDaemonError(err error) *daemonErrorSignature {
+	if err == nil {
+		return nil
+	}
+	message := strings.ToLower(err.Error())
+	for i := range daemonErrorSignatures {
+		for _, substring := range daemonErrorSignatures[i].substrings {
+			if strings.Contains(message, substring) {
+				return &daemonErrorSignatures[i]
+			}
+		}
+	}
+	return nil
+}
+
+// wrapDaemonError wraps a recognized Docker daemon resource error into a typed error with a hint message.
+// Disk space errors include current Docker disk usage, if it can be retrieved. Registry rate limit errors are
+// wrapped into [RegistryRateLimitedError]. Other errors are returned as is.
+func (c *defaultClient) wrapDaemonError(ctx context.Context, err error) error {
+	if rateLimitedErr := registryRateLimitedError(err); rateLimitedErr != nil {
+		return rateLimitedErr
+	}
+	signature := classifyDaemonError(err)
+	if signature == nil {
+		return err
+	}
+	resourceErr := &daemonResourceError{kind: signature.kind, cause: err, hint: signature.hint}
+	if signature.kind == ErrDaemonOutOfDiskSpace {
+		resourceErr.usage = c.diskUsageSummary(ctx)
+	}
+	return resourceErr
+}
+
+// diskUsageSummary returns a short summary of Docker disk usage or an empty string if it cannot be retrieved.
+func (c *defaultClient) diskUsageSummary(ctx context.Context) string {
+	usage, err := c.handler.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return ""
+	}
+	var containersSize, volumesSize, buildCacheSize int64
+	for _, container := range usage.Containers {
+		if container != nil {
+			containersSize += container.SizeRw
+		}
+	}
+	for _, volume := range usage.Volumes {
+		if volume != nil && volume.UsageData != nil && volume.UsageData.Size > 0 {
+			volumesSize += volume.UsageData.Size
+		}
+	}
+	for _, cache := range usage.BuildCache {
+		if cache != nil {
+			buildCacheSize += cache.Size
+		}
+	}
+	return fmt.Sprintf(
+		"images: %d (%s), containers: %d (%s), volumes: %d (%s), build cache: %s",
+		len(usage.Images), units.HumanSize(float64(usage.LayersSize)),
+		len(usage.Containers), units.HumanSize(float64(containersSize)),
+		len(usage.Volumes), units.HumanSize(float64(volumesSize)),
+		units.HumanSize(float64(buildCacheSize)),
+	)
+}
+
+// pullStreamError reads image pull progress stream to the end and returns the first recognized Docker daemon
+// resource or registry rate limit error reported in it. Other stream errors and malformed messages are ignored.
+func pullStreamError(reader io.Reader) error {
+	var streamErr error
+	decoder := json.NewDecoder(reader)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err != nil {
+			io.Copy(io.Discard, reader) // nolint: errcheck
+			return streamErr
+		}
+		if streamErr == nil && message.Error != nil &&
+			(classifyDaemonError(message.Error) != nil || isRateLimited(message.Error)) {
+			streamErr = message.Error
+		}
+	}
+}
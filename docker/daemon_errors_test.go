@@ -0,0 +1,194 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_classifyDaemonError(t *testing.T) {
+	tests := []struct {
+		name         string
+		message      string
+		expectedKind error
+	}{
+		{
+			"overlay_mkdir",
+			"Error response from daemon: mkdir /var/lib/docker/overlay2/4f1c2d7e-init: no space left on device",
+			ErrDaemonOutOfDiskSpace,
+		},
+		{
+			"layer_register",
+			"failed to register layer: Error processing tar file(exit status 1): write /usr/lib/x86_64-linux-gnu/libLLVM-15.so.1: " +
+				"no space left on device",
+			ErrDaemonOutOfDiskSpace,
+		},
+		{
+			"runc_create",
+			"Error response from daemon: failed to create shim task: OCI runtime create failed: runc create failed: " +
+				"unable to start container process: error during container init: no space left on device: unknown",
+			ErrDaemonOutOfDiskSpace,
+		},
+		{
+			"windows_import_layer",
+			"hcsshim::ImportLayer - failed failed in Win32: There is not enough space on the disk. (0x70)",
+			ErrDaemonOutOfDiskSpace,
+		},
+		{
+			"blob_quota",
+			"write /var/lib/docker/tmp/GetImageBlob262145813: disk quota exceeded",
+			ErrDaemonOutOfDiskSpace,
+		},
+		{
+			"shim_fork",
+			"Error response from daemon: failed to create shim task: fork/exec /usr/bin/containerd-shim-runc-v2: " +
+				"cannot allocate memory: unknown",
+			ErrDaemonOutOfMemory,
+		},
+		{
+			"runtime_out_of_memory",
+			"Error response from daemon: failed to create shim task: OCI runtime create failed: " +
+				"fatal error: runtime: out of memory",
+			ErrDaemonOutOfMemory,
+		},
+		{
+			"windows_compute_system",
+			"hcsshim::CreateComputeSystem 5d2c: Not enough memory resources are available to process this command.",
+			ErrDaemonOutOfMemory,
+		},
+		{
+			"name_conflict",
+			`Error response from daemon: Conflict. The container name "/db" is already in use by container "5d2c".`,
+			nil,
+		},
+		{
+			"pull_access_denied",
+			"Error response from daemon: pull access denied for foo, repository does not exist or may require 'docker login'",
+			nil,
+		},
+		{
+			"port_allocated",
+			"Error response from daemon: driver failed programming external connectivity on endpoint db: " +
+				"Bind for 0.0.0.0:5432 failed: port is already allocated",
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signature := classifyDaemonError(errors.New(test.message))
+			if test.expectedKind == nil {
+				require.Nil(t, signature)
+				return
+			}
+			require.NotNil(t, signature)
+			require.Equal(t, test.expectedKind, signature.kind)
+		})
+	}
+	require.Nil(t, classifyDaemonError(nil))
+}
+
+func Test_wrapDaemonError(t *testing.T) {
+	diskUsage := types.DiskUsage{
+		LayersSize: 3 * 1000 * 1000 * 1000,
+		Images:     []*types.ImageSummary{{}, {}},
+		Containers: []*types.Container{{SizeRw: 1000 * 1000}},
+		Volumes:    []*volume.Volume{{UsageData: &volume.UsageData{Size: 2 * 1000 * 1000}}, {}},
+		BuildCache: []*types.BuildCache{{Size: 5 * 1000 * 1000}},
+	}
+	tests := []struct {
+		name             string
+		createError      error
+		diskUsageError   error
+		expectedKind     error
+		expectedContains []string
+	}{
+		{
+			"out_of_disk_space",
+			errors.New("Error response from daemon: mkdir /var/lib/docker/overlay2/4f1c-init: no space left on device"),
+			nil,
+			ErrDaemonOutOfDiskSpace,
+			[]string{
+				"no space left on device", "docker system prune",
+				"images: 2 (3GB), containers: 1 (1MB), volumes: 2 (2MB), build cache: 5MB",
+			},
+		},
+		{
+			"out_of_disk_space_usage_unknown",
+			errors.New("no space left on device"),
+			errors.New("daemon unavailable"),
+			ErrDaemonOutOfDiskSpace,
+			[]string{"docker system prune"},
+		},
+		{
+			"out_of_memory",
+			errors.New("fork/exec /usr/bin/containerd-shim-runc-v2: cannot allocate memory"),
+			nil,
+			ErrDaemonOutOfMemory,
+			[]string{"cannot allocate memory", "memory limits"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerCreateError = test.createError
+			mockedDiskUsage = diskUsage
+			mockedDiskUsageError = test.diskUsageError
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{Name: mockedContainerName})
+			require.ErrorIs(t, err, test.expectedKind)
+			require.ErrorIs(t, err, test.createError)
+			for _, part := range test.expectedContains {
+				require.Contains(t, err.Error(), part)
+			}
+			if test.diskUsageError != nil {
+				require.NotContains(t, err.Error(), "current usage")
+			}
+		})
+	}
+
+	resetMocks()
+	otherErr := errors.New("Error response from daemon: Conflict. The container name is already in use")
+	mockedContainerCreateError = otherErr
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	_, err := c.createContainer(context.Background(), mockedImageName, &Options{Name: mockedContainerName})
+	require.Equal(t, otherErr, err)
+}
+
+func Test_pullImageStreamErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		stream        string
+		expectedError error
+	}{
+		{"no_errors", `{"status":"Pulling from library/alpine"}` + "\n" + `{"status":"Download complete"}`, nil},
+		{
+			"out_of_disk_space",
+			`{"status":"Extracting"}` + "\n" +
+				`{"errorDetail":{"message":"write /usr/lib/libLLVM.so: no space left on device"},` +
+				`"error":"write /usr/lib/libLLVM.so: no space left on device"}`,
+			ErrDaemonOutOfDiskSpace,
+		},
+		{"other_stream_error", `{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}`, nil},
+		{"malformed_stream", "not a json stream", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImagePullStream = test.stream
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			err := c.pullImage(context.Background(), mockedImageName)
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/pkg/errors"
+)
+
+// daemonFeature describes a container configuration feature which is supported by Docker daemon starting from
+// a specific API version.
+type daemonFeature struct {
+	name          string
+	minAPIVersion string
+	// isUsed reports whether the feature is used in the given host configuration.
+	isUsed func(hostConfig *dockerContainer.HostConfig) bool
+	// drop removes the feature from the given host configuration.
+	drop func(hostConfig *dockerContainer.HostConfig)
+}
+
+// daemonFeatures holds the list of features which are checked against Docker daemon API version on container creation.
+var daemonFeatures = []daemonFeature{
+	{
+		name:          "cgroup namespace mode",
+		minAPIVersion: "1.41",
+		isUsed:        func(h *dockerContainer.HostConfig) bool { return !h.CgroupnsMode.IsEmpty() },
+		drop:          func(h *dockerContainer.HostConfig) { h.CgroupnsMode = dockerContainer.CgroupnsModeEmpty },
+	},
+	{
+		name:          "host gateway",
+		minAPIVersion: "1.41",
+		isUsed: func(h *dockerContainer.HostConfig) bool {
+			for _, host := range h.ExtraHosts {
+				if strings.HasSuffix(host, ":host-gateway") {
+					return true
+				}
+			}
+			return false
+		},
+		drop: func(h *dockerContainer.HostConfig) {
+			hosts := make([]string, 0, len(h.ExtraHosts))
+			for _, host := range h.ExtraHosts {
+				if !strings.HasSuffix(host, ":host-gateway") {
+					hosts = append(hosts, host)
+				}
+			}
+			h.ExtraHosts = hosts
+		},
+	},
+}
+
+var errUnsupportedDaemonFeature = errors.New("unsupported daemon feature")
+
+// serverAPIVersion returns Docker daemon API version. The version is fetched once and cached in the client.
+func (c *defaultClient) serverAPIVersion(ctx context.Context) (string, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if len(c.apiVersion) > 0 {
+		return c.apiVersion, nil
+	}
+	v, err := c.handler.ServerVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.apiVersion = v.APIVersion
+	return c.apiVersion, nil
+}
+
+// checkDaemonFeatures checks that all features used in the given host configuration are supported by Docker daemon.
+// Unsupported features are dropped from the configuration with a logged warning or, if strict is true,
+// an error is returned.
+func (c *defaultClient) checkDaemonFeatures(ctx context.Context, hostConfig *dockerContainer.HostConfig, strict bool) error {
+	var (
+		apiVersion string
+		err        error
+	)
+	for _, feature := range daemonFeatures {
+		if !feature.isUsed(hostConfig) {
+			continue
+		}
+		if len(apiVersion) == 0 {
+			if apiVersion, err = c.serverAPIVersion(ctx); err != nil {
+				return err
+			}
+		}
+		if !versions.LessThan(apiVersion, feature.minAPIVersion) {
+			continue
+		}
+		if strict {
+			return errors.Wrapf(errUnsupportedDaemonFeature, "daemon %s does not support %s", apiVersion, feature.name)
+		}
+		warnf("daemon %s does not support %s, the option is ignored", apiVersion, feature.name)
+		feature.drop(hostConfig)
+	}
+	return nil
+}
+
+// daemonOS returns Docker daemon operating system, e.g. `linux` or `windows`, which may differ from the client one,
+// e.g. for a remote daemon. The operating system is fetched once and cached in the client.
+func (c *defaultClient) daemonOS(ctx context.Context) (string, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if len(c.osType) > 0 {
+		return c.osType, nil
+	}
+	info, err := c.handler.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.osType = info.OSType
+	if len(c.osType) == 0 {
+		c.osType = "linux"
+	}
+	return c.osType, nil
+}
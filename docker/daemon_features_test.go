@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_checkDaemonFeatures(t *testing.T) {
+	tests := []struct {
+		name                 string
+		apiVersion           string
+		options              Options
+		expectedError        error
+		expectedCgroupnsMode dockerContainer.CgroupnsMode
+		expectedWarnings     int
+		expectedExtraHosts   []string
+	}{
+		{"new_daemon", "1.42", Options{CgroupnsMode: "private"}, nil, dockerContainer.CgroupnsModePrivate, 0, nil},
+		{"new_daemon_strict", "1.41", Options{CgroupnsMode: "host", StrictDaemonFeatures: true}, nil, dockerContainer.CgroupnsModeHost, 0, nil},
+		{"old_daemon_degraded", "1.40", Options{CgroupnsMode: "private"}, nil, dockerContainer.CgroupnsModeEmpty, 1, nil},
+		{"old_daemon_strict", "1.40", Options{CgroupnsMode: "private", StrictDaemonFeatures: true}, errUnsupportedDaemonFeature, "", 0, nil},
+		{"old_daemon_feature_unused", "1.24", Options{StrictDaemonFeatures: true}, nil, dockerContainer.CgroupnsModeEmpty, 0, nil},
+		{"host_gateway_new_daemon", "1.41", Options{EnableHostGateway: true, ExtraHosts: []string{"db:10.0.0.2"}}, nil, "", 0,
+			[]string{"db:10.0.0.2", hostGatewayHost}},
+		{"host_gateway_new_daemon_strict", "1.41", Options{EnableHostGateway: true, StrictDaemonFeatures: true}, nil, "", 0,
+			[]string{hostGatewayHost}},
+		{"host_gateway_old_daemon_degraded", "1.40", Options{EnableHostGateway: true, ExtraHosts: []string{"db:10.0.0.2"}}, nil, "", 1,
+			[]string{"db:10.0.0.2"}},
+		{"host_gateway_old_daemon_strict", "1.40", Options{EnableHostGateway: true, StrictDaemonFeatures: true},
+			errUnsupportedDaemonFeature, "", 0, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedServerAPIVersion = test.apiVersion
+			l, restore := useMockedLogger()
+			defer restore()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Len(t, l.messages, test.expectedWarnings)
+			if test.expectedError == nil {
+				require.Equal(t, test.expectedCgroupnsMode, mockedContainerCreateHostConfig.CgroupnsMode)
+				require.Equal(t, test.expectedExtraHosts, mockedContainerCreateHostConfig.ExtraHosts)
+			}
+		})
+	}
+}
+
+func Test_serverAPIVersionCached(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	v, err := c.serverAPIVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1.42", v)
+
+	mockedServerAPIVersion = "1.30"
+	v, err = c.serverAPIVersion(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1.42", v)
+}
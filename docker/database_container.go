@@ -3,30 +3,320 @@ package docker
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
 )
 
 // DatabaseContainer extends [Container] interface with database interaction methods.
 type DatabaseContainer interface {
 	Container
 	ResetDatabase(ctx context.Context) error
+	RunNamedCommand(ctx context.Context, name string, args ...string) (string, error)
+	ExecSQL(ctx context.Context, query string) (string, error)
+	WaitForQuery(ctx context.Context, query string, expect string, timeout time.Duration) error
+	WaitReady(ctx context.Context, timeout time.Duration) error
+	WaitConnectable(ctx context.Context, timeout time.Duration) error
+	WaitReadyTCP(ctx context.Context, timeout time.Duration) error
+	CreateDatabase(ctx context.Context, name string) error
+	DropDatabase(ctx context.Context, name string) error
+	ConnectionString(ctx context.Context) (string, error)
+	ConnectionStringFor(ctx context.Context, name string) (string, error)
+	OpenDB(ctx context.Context) (*sql.DB, error)
 }
 
 // Database holds database metadata.
 type Database struct {
-	Name         string
+	Name string
+	// Names holds names of several databases in one engine container. If it is set, ResetDatabase resets each
+	// of them instead of Name.
+	Names []string
+	// ResetCommand drops and recreates the database. It may contain `{{ .Database }}` placeholder replaced with
+	// the database name.
 	ResetCommand string
+	// QueryCommand is a database client command executing SQL query passed to it as the last argument,
+	// e.g. `psql --tuples-only --no-align --command`.
+	QueryCommand string
+	// Commands holds named auxiliary database commands, e.g. "vacuum", which can be run using RunNamedCommand.
+	Commands map[string]string
+	// ReadyCommand is a command which exits with zero code when the database accepts connections, e.g. `pg_isready`.
+	// If it is empty, WaitReady probes Port with TCP connections instead, e.g. in slim images without database client.
+	ReadyCommand string
+	// Port is the database container port, e.g. "5432", probed by WaitReady if ReadyCommand is empty.
+	Port string
+	// ReadyProbe selects the database wire protocol used by WaitReadyTCP to probe Port: [ReadyProbePostgres],
+	// [ReadyProbeRedis], or [ReadyProbeMySQL].
+	ReadyProbe string
+	// CreateCommand and DropCommand are commands creating and dropping a logical database with the name passed
+	// to them as the last argument, e.g. `createdb` and `dropdb`.
+	CreateCommand, DropCommand string
+	// ConnectionString is a template of the database connection string which may contain `{{ .Database }}`
+	// placeholder, e.g. `postgres://postgres@{{ .Endpoint 5432 }}/{{ .Database }}`.
+	ConnectionString string
+	// Driver is the name of the [database/sql] driver used by OpenDB with the connection string, e.g. `pgx`.
+	// The driver package has to be imported by the test.
+	Driver string
 }
 
+const (
+	// queryPollInterval is the interval between query executions in WaitForQuery and readiness probes in WaitReady.
+	queryPollInterval = time.Millisecond * 500
+	// readyDialTimeout is the TCP readiness probe connection timeout.
+	readyDialTimeout = time.Second
+	// openDBTimeout is the maximum time OpenDB waits for the database to accept connections.
+	openDBTimeout = 30 * time.Second
+)
+
+var (
+	errUnknownCommand         = errors.New("unknown database command")
+	errCommandFailed          = errors.New("database command failed")
+	errQueryCommandNotSet     = errors.New("database query command is not set")
+	errWaitForQueryTimeout    = errors.New("query result wait timeout")
+	errReadyProbeNotSet       = errors.New("database ready command and port are not set")
+	errWaitReadyTimeout       = errors.New("database readiness wait timeout")
+	errPortNotSet             = errors.New("database port is not set")
+	errCreateCommandNotSet    = errors.New("database create command is not set")
+	errDropCommandNotSet      = errors.New("database drop command is not set")
+	errConnectionStringNotSet = errors.New("database connection string is not set")
+	errDriverNotSet           = errors.New("database driver is not set")
+	errOpenDBTimeout          = errors.New("database connection wait timeout")
+)
+
 // databaseContainer holds container and inner database metadata. Implements [DatabaseContainer] interface.
 type databaseContainer struct {
 	container
 	database Database
 }
 
-// ResetDatabase executes database reset command in container.
+// databaseTemplateData is passed to database command and connection string templates. It extends container
+// template data with the database name.
+type databaseTemplateData struct {
+	templateData
+	Database string
+}
+
+// ResetDatabase executes database reset command in container for each database in Names or, if it is not set,
+// for Name. Reset command may contain template placeholders, which are rendered using the started container data.
 func (dc *databaseContainer) ResetDatabase(ctx context.Context) error {
-	buffer := bytes.Buffer{}
-	return dc.Exec(ctx, dc.database.ResetCommand, &buffer)
+	for _, name := range dc.databaseNames() {
+		command, err := dc.renderDatabase(ctx, dc.database.ResetCommand, name)
+		if err != nil {
+			return err
+		}
+		buffer := bytes.Buffer{}
+		if err = dc.Exec(ctx, command, &buffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnectionString returns the connection string of the database, the first one in Names if it is set.
+// It is not routed through [ForwardPort] if [Options.ForwardPorts] is set.
+func (dc *databaseContainer) ConnectionString(ctx context.Context) (string, error) {
+	return dc.ConnectionStringFor(ctx, dc.databaseNames()[0])
+}
+
+// ConnectionStringFor returns the connection string of the database with the given name, e.g. one of Names
+// or a database created with CreateDatabase. It is not routed through [ForwardPort] if [Options.ForwardPorts] is set.
+func (dc *databaseContainer) ConnectionStringFor(ctx context.Context, name string) (string, error) {
+	if len(dc.database.ConnectionString) == 0 {
+		return "", errConnectionStringNotSet
+	}
+	return dc.renderDatabase(ctx, dc.database.ConnectionString, name)
+}
+
+// OpenDB opens a [database/sql] connection pool to the database, the first one in Names if it is set, using Driver
+// and the connection string, and pings it until the database accepts connections, for up to 30 seconds. The driver
+// package, e.g. `github.com/jackc/pgx/v5/stdlib`, has to be imported by the test, so that the package does not
+// depend on database drivers. The returned pool is closed by the caller.
+func (dc *databaseContainer) OpenDB(ctx context.Context) (*sql.DB, error) {
+	if len(dc.database.Driver) == 0 {
+		return nil, errDriverNotSet
+	}
+	dsn, err := dc.ConnectionString(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(dc.database.Driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err = poll(ctx, openDBTimeout, queryPollInterval, errOpenDBTimeout, db.PingContext); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// databaseNames returns Names, if they are set, or Name.
+func (dc *databaseContainer) databaseNames() []string {
+	if len(dc.database.Names) > 0 {
+		return dc.database.Names
+	}
+	return []string{dc.database.Name}
+}
+
+// renderDatabase renders template placeholders in the given text using data of the started container
+// and the given database name.
+func (dc *databaseContainer) renderDatabase(ctx context.Context, text, name string) (string, error) {
+	return dc.renderWith(ctx, text, func(data templateData) any {
+		return databaseTemplateData{templateData: data, Database: name}
+	})
+}
+
+// RunNamedCommand executes the named database command in container and returns its output. The given args are
+// shell-quoted and appended to the command. Command may contain template placeholders, which are rendered using
+// the started container data.
+func (dc *databaseContainer) RunNamedCommand(ctx context.Context, name string, args ...string) (string, error) {
+	command, ok := dc.database.Commands[name]
+	if !ok {
+		names := make([]string, 0, len(dc.database.Commands))
+		for n := range dc.database.Commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", errors.Wrapf(errUnknownCommand, "%q, available commands: %s", name, strings.Join(names, ", "))
+	}
+	return dc.execShell(ctx, name, command, args...)
+}
+
+// ExecSQL executes SQL query using the database client in container and returns query output.
+func (dc *databaseContainer) ExecSQL(ctx context.Context, query string) (string, error) {
+	if len(dc.database.QueryCommand) == 0 {
+		return "", errQueryCommandNotSet
+	}
+	return dc.execShell(ctx, "query", dc.database.QueryCommand, query)
+}
+
+// WaitForQuery repeatedly executes SQL query until its trimmed output matches the expected string or the timeout
+// expires. Timeout error includes the last query output.
+func (dc *databaseContainer) WaitForQuery(ctx context.Context, query string, expect string, timeout time.Duration) error {
+	var (
+		output string
+		err    error
+	)
+	deadline := nowFn().Add(timeout)
+	for {
+		output, err = dc.ExecSQL(ctx, query)
+		output = strings.TrimSpace(output)
+		switch {
+		case errors.Is(err, errQueryCommandNotSet):
+			return err
+		case err == nil && output == expect:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !nowFn().Before(deadline):
+			if err != nil {
+				return errors.Wrapf(errWaitForQueryTimeout, "last output: %q, last error: %v", output, err)
+			}
+			return errors.Wrapf(errWaitForQueryTimeout, "last output: %q", output)
+		}
+		sleepFn(queryPollInterval)
+	}
+}
+
+// WaitReady repeatedly probes the database until it accepts connections or the timeout expires. The database is
+// probed with ReadyCommand executed in container or, if it is empty, with TCP connections to the host port bound
+// to Port. Timeout error includes the last probe error.
+func (dc *databaseContainer) WaitReady(ctx context.Context, timeout time.Duration) error {
+	if len(dc.database.ReadyCommand) == 0 && len(dc.database.Port) == 0 {
+		return errReadyProbeNotSet
+	}
+	endpoint, release := dc.probeEndpoint(dc.database.Port)
+	defer release()
+	return dc.waitProbe(ctx, timeout, func(ctx context.Context) error { return dc.probeReady(ctx, endpoint) })
+}
+
+// WaitConnectable repeatedly dials the host port bound to the database Port until a TCP connection is established
+// or the timeout expires. Unlike WaitReady, it checks the host-side path used by test code to connect to the database.
+func (dc *databaseContainer) WaitConnectable(ctx context.Context, timeout time.Duration) error {
+	if len(dc.database.Port) == 0 {
+		return errPortNotSet
+	}
+	endpoint, release := dc.probeEndpoint(dc.database.Port)
+	defer release()
+	return dc.waitProbe(ctx, timeout, func(ctx context.Context) error { return dialPort(ctx, endpoint) })
+}
+
+// waitProbe repeatedly calls probe until it succeeds or the timeout expires. Timeout error includes the last probe error.
+func (dc *databaseContainer) waitProbe(ctx context.Context, timeout time.Duration, probe func(ctx context.Context) error) error {
+	return poll(ctx, timeout, queryPollInterval, errWaitReadyTimeout, probe)
+}
+
+// probeReady executes database ready command in container or, if it is not set, dials the database port address
+// returned by endpoint.
+func (dc *databaseContainer) probeReady(ctx context.Context, endpoint func(ctx context.Context) (string, error)) error {
+	if len(dc.database.ReadyCommand) > 0 {
+		_, err := dc.execShell(ctx, "ready", dc.database.ReadyCommand)
+		return err
+	}
+	return dialPort(ctx, endpoint)
+}
+
+// dialPort establishes and closes a TCP connection to the database port address returned by endpoint.
+func dialPort(ctx context.Context, endpoint func(ctx context.Context) (string, error)) error {
+	address, err := endpoint(ctx)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", address, readyDialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// CreateDatabase creates a new logical database with the given name using the database CreateCommand.
+func (dc *databaseContainer) CreateDatabase(ctx context.Context, name string) error {
+	if len(dc.database.CreateCommand) == 0 {
+		return errCreateCommandNotSet
+	}
+	_, err := dc.execShell(ctx, "create", dc.database.CreateCommand, name)
+	return err
+}
+
+// DropDatabase drops the logical database with the given name using the database DropCommand.
+func (dc *databaseContainer) DropDatabase(ctx context.Context, name string) error {
+	if len(dc.database.DropCommand) == 0 {
+		return errDropCommandNotSet
+	}
+	_, err := dc.execShell(ctx, "drop", dc.database.DropCommand, name)
+	return err
+}
+
+// execShell executes shell command with the given shell-quoted args appended in container and returns its stdout.
+// Command may contain template placeholders, which are rendered using the started container data.
+func (dc *databaseContainer) execShell(ctx context.Context, name, command string, args ...string) (string, error) {
+	command, err := dc.render(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	for _, arg := range args {
+		command += " " + shellQuote(arg)
+	}
+	if err = dc.resolveID(ctx); err != nil {
+		return "", err
+	}
+	result, err := ExecWithResult(ctx, dc.id, types.ExecConfig{Cmd: []string{"bash", "-c", command}})
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return result.Stdout, errors.Wrapf(errCommandFailed, "%q exit code %d: %s", name, result.ExitCode, result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// shellQuote quotes the given string to be passed to shell as a single argument.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 // NewDatabaseContainer creates a new [DatabaseContainer] object.
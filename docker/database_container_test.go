@@ -0,0 +1,354 @@
+package docker
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RunNamedCommand(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	database := Database{
+		Name: "postgres",
+		Commands: map[string]string{
+			"vacuum": "vacuumdb --all",
+			"psql":   "psql --command",
+		},
+	}
+	tests := []struct {
+		name            string
+		command         string
+		args            []string
+		exitCode        int
+		expectedCommand string
+		expectedOutput  string
+		expectedError   error
+	}{
+		{"no_args", "vacuum", nil, 0, "vacuumdb --all", "output", nil},
+		{"quoted_args", "psql", []string{"SELECT 'a' AS x"}, 0, `psql --command 'SELECT '\''a'\'' AS x'`, "output", nil},
+		{"failure", "vacuum", nil, 1, "vacuumdb --all", "output", errCommandFailed},
+		{"unknown", "flush", nil, 0, "", "", errUnknownCommand},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(config types.ExecConfig) ExecResult {
+				return ExecResult{ExitCode: test.exitCode, Stdout: "output", Stderr: "error"}
+			}
+			dc := NewDatabaseContainerWithOptions(mockedImageName, database, Options{Name: mockedContainerName})
+			output, err := dc.RunNamedCommand(context.Background(), test.command, test.args...)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedOutput, output)
+			if len(test.expectedCommand) > 0 {
+				require.Equal(t, []string{"bash", "-c", test.expectedCommand}, mockedExecConfigs[0].Cmd)
+			} else {
+				require.Empty(t, mockedExecConfigs)
+			}
+		})
+	}
+}
+
+func Test_ResetDatabaseNames(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID}}
+	dc := NewDatabaseContainerWithOptions(mockedImageName, Database{
+		Name:         "postgres",
+		Names:        []string{"orders", "billing"},
+		ResetCommand: "dropdb -f {{ .Database }}; createdb {{ .Database }}",
+	}, Options{Name: mockedContainerName})
+	require.NoError(t, dc.ResetDatabase(context.Background()))
+	require.Len(t, mockedExecConfigs, 2)
+	require.Equal(t, []string{"bash", "-c", "dropdb -f orders; createdb orders"}, mockedExecConfigs[0].Cmd)
+	require.Equal(t, []string{"bash", "-c", "dropdb -f billing; createdb billing"}, mockedExecConfigs[1].Cmd)
+}
+
+func Test_ConnectionString(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32770"}},
+		}}},
+	}
+	database := Database{
+		Name:             "postgres",
+		Names:            []string{"orders", "billing"},
+		ConnectionString: "postgres://postgres@{{ .Endpoint 5432 }}/{{ .Database }}",
+	}
+	dc := NewDatabaseContainerWithOptions(mockedImageName, database, Options{Name: mockedContainerName})
+
+	connectionString, err := dc.ConnectionString(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "postgres://postgres@localhost:32770/orders", connectionString)
+	connectionString, err = dc.ConnectionStringFor(context.Background(), "billing")
+	require.NoError(t, err)
+	require.Equal(t, "postgres://postgres@localhost:32770/billing", connectionString)
+
+	dc = NewDatabaseContainerWithOptions(mockedImageName, Database{Name: "postgres"}, Options{Name: mockedContainerName})
+	_, err = dc.ConnectionString(context.Background())
+	require.ErrorIs(t, err, errConnectionStringNotSet)
+}
+
+// stubDriverName is the name of stubDriver registered in [database/sql].
+const stubDriverName = "testutils-stub"
+
+func init() {
+	sql.Register(stubDriverName, &stubDriver{})
+}
+
+// stubDriver is a [database/sql] driver recording the opened data source names. Pings fail until
+// stubDriverPingFailures pings have been made.
+type stubDriver struct{}
+
+var (
+	stubDriverDSNs         []string
+	stubDriverPings        int
+	stubDriverPingFailures int
+)
+
+func (d *stubDriver) Open(dsn string) (driver.Conn, error) {
+	stubDriverDSNs = append(stubDriverDSNs, dsn)
+	return &stubConn{}, nil
+}
+
+// stubConn is a [database/sql] driver connection supporting pings only.
+type stubConn struct {
+	driver.Conn
+}
+
+func (c *stubConn) Ping(context.Context) error {
+	stubDriverPings++
+	if stubDriverPings <= stubDriverPingFailures {
+		return errors.New("the database system is starting up")
+	}
+	return nil
+}
+
+func (c *stubConn) Close() error {
+	return nil
+}
+
+func Test_OpenDB(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	tests := []struct {
+		name          string
+		driver        string
+		pingFailures  int
+		expectedPings int
+		expectedError error
+	}{
+		{"ready", stubDriverName, 0, 1, nil},
+		{"starting_up", stubDriverName, 3, 4, nil},
+		{"timeout", stubDriverName, 1000, 61, errOpenDBTimeout},
+		{"driver_not_set", "", 0, 0, errDriverNotSet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			stubDriverDSNs, stubDriverPings, stubDriverPingFailures = nil, 0, test.pingFailures
+			mockedContainerInspect = types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+				NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+					"5432/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32770"}},
+				}}},
+			}
+			dc := NewDatabaseContainerWithOptions(mockedImageName, Database{
+				Name:             "orders",
+				ConnectionString: "postgres://postgres@{{ .Endpoint 5432 }}/{{ .Database }}",
+				Driver:           test.driver,
+			}, Options{Name: mockedContainerName})
+
+			db, err := dc.OpenDB(context.Background())
+			require.Equal(t, test.expectedPings, stubDriverPings)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				require.Nil(t, db)
+				return
+			}
+			require.NoError(t, err)
+			defer db.Close()
+			require.Equal(t, []string{"postgres://postgres@localhost:32770/orders"}, stubDriverDSNs)
+		})
+	}
+}
+
+func Test_OpenDBUnknownDriver(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	dc := NewDatabaseContainerWithOptions(mockedImageName, Database{
+		Name:             "orders",
+		ConnectionString: "postgres://postgres@localhost/{{ .Database }}",
+		Driver:           "unregistered",
+	}, Options{Name: mockedContainerName})
+
+	_, err := dc.OpenDB(context.Background())
+	require.ErrorContains(t, err, `unknown driver "unregistered"`)
+}
+
+func Test_RunNamedCommandUnknownListsAvailable(t *testing.T) {
+	dc := NewDatabaseContainer(mockedImageName, Database{Commands: map[string]string{"vacuum": "", "analyze": ""}})
+	_, err := dc.RunNamedCommand(context.Background(), "flush")
+	require.EqualError(t, err, `"flush", available commands: analyze, vacuum: unknown database command`)
+}
+
+// useFakeClock replaces sleepFn and nowFn with a fake clock advanced by sleepFn calls.
+// Returns a function restoring the real clock.
+func useFakeClock() func() {
+	current := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	nowFn = func() time.Time { return current }
+	sleepFn = func(d time.Duration) { current = current.Add(d) }
+	return func() {
+		nowFn = time.Now
+		sleepFn = time.Sleep
+	}
+}
+
+func Test_WaitForQuery(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	database := Database{Name: "postgres", QueryCommand: "psql --command"}
+	tests := []struct {
+		name          string
+		database      Database
+		outputs       []ExecResult
+		expectedCalls int
+		expectedError error
+		expectedText  string
+	}{
+		{"immediate", database, []ExecResult{{Stdout: "3\n"}}, 1, nil, ""},
+		{"after_retries", database, []ExecResult{
+			{ExitCode: 2, Stderr: "relation does not exist"},
+			{Stdout: "0\n"},
+			{Stdout: " 3 \n"},
+		}, 3, nil, ""},
+		{"timeout", database, []ExecResult{{Stdout: "1\n"}}, 21, errWaitForQueryTimeout, `last output: "1"`},
+		{"timeout_with_error", database, []ExecResult{{ExitCode: 2, Stderr: "relation does not exist"}}, 21,
+			errWaitForQueryTimeout, "relation does not exist"},
+		{"query_command_not_set", Database{Name: "postgres"}, nil, 0, errQueryCommandNotSet, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(config types.ExecConfig) ExecResult {
+				if len(mockedExecConfigs) > len(test.outputs) {
+					return test.outputs[len(test.outputs)-1]
+				}
+				return test.outputs[len(mockedExecConfigs)-1]
+			}
+			dc := NewDatabaseContainerWithOptions(mockedImageName, test.database, Options{Name: mockedContainerName})
+			err := dc.WaitForQuery(context.Background(), "SELECT count(*) FROM currencies", "3", time.Second*10)
+			require.ErrorIs(t, err, test.expectedError)
+			if err != nil {
+				require.Contains(t, err.Error(), test.expectedText)
+			}
+			require.Len(t, mockedExecConfigs, test.expectedCalls)
+			if test.expectedCalls > 0 {
+				require.Equal(t, []string{"bash", "-c", "psql --command 'SELECT count(*) FROM currencies'"}, mockedExecConfigs[0].Cmd)
+			}
+		})
+	}
+}
+
+// mockedPostgresInspect returns a mocked inspect result of a container with port 5432 bound to the given address.
+func mockedPostgresInspect(address string) types.ContainerJSON {
+	_, port, _ := net.SplitHostPort(address)
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: port}},
+		}}},
+	}
+}
+
+func Test_WaitReady(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	closed.Close()
+	tests := []struct {
+		name          string
+		database      Database
+		address       string
+		exitCodes     []int
+		expectedCalls int
+		expectedError error
+	}{
+		{"exec_probe", Database{ReadyCommand: "pg_isready", Port: "5432"}, "", []int{2, 2, 0}, 3, nil},
+		{"exec_probe_timeout", Database{ReadyCommand: "pg_isready"}, "", []int{2}, 21, errWaitReadyTimeout},
+		{"tcp_fallback", Database{Port: "5432"}, listener.Addr().String(), nil, 0, nil},
+		{"tcp_fallback_timeout", Database{Port: "5432"}, closedAddress, nil, 0, errWaitReadyTimeout},
+		{"probe_not_set", Database{}, "", nil, 0, errReadyProbeNotSet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			if len(test.address) > 0 {
+				mockedContainerInspect = mockedPostgresInspect(test.address)
+			}
+			mockedExecScript = func(config types.ExecConfig) ExecResult {
+				if len(mockedExecConfigs) > len(test.exitCodes) {
+					return ExecResult{ExitCode: test.exitCodes[len(test.exitCodes)-1]}
+				}
+				return ExecResult{ExitCode: test.exitCodes[len(mockedExecConfigs)-1]}
+			}
+			dc := NewDatabaseContainerWithOptions(mockedImageName, test.database, Options{Name: mockedContainerName})
+			require.ErrorIs(t, dc.WaitReady(context.Background(), time.Second*10), test.expectedError)
+			require.Len(t, mockedExecConfigs, test.expectedCalls)
+			if test.expectedCalls > 0 {
+				require.Equal(t, []string{"bash", "-c", "pg_isready"}, mockedExecConfigs[0].Cmd)
+			}
+		})
+	}
+}
+
+func Test_WaitConnectable(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	closed.Close()
+	tests := []struct {
+		name          string
+		database      Database
+		address       string
+		expectedError error
+	}{
+		{"connectable", Database{Port: "5432", ReadyCommand: "pg_isready"}, listener.Addr().String(), nil},
+		{"timeout", Database{Port: "5432"}, closedAddress, errWaitReadyTimeout},
+		{"port_not_set", Database{ReadyCommand: "pg_isready"}, listener.Addr().String(), errPortNotSet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = mockedPostgresInspect(test.address)
+			dc := NewDatabaseContainerWithOptions(mockedImageName, test.database, Options{Name: mockedContainerName})
+			require.ErrorIs(t, dc.WaitConnectable(context.Background(), time.Second*10), test.expectedError)
+			require.Empty(t, mockedExecConfigs)
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DatabasePool hands out isolated logical databases created in one shared [DatabaseContainer].
+type DatabasePool interface {
+	// Acquire creates a new logical database and leases it. If the pool is exhausted, it blocks until another lease
+	// is released, the pool is closed, or the context is done.
+	Acquire(ctx context.Context) (LeasedDatabase, error)
+	// TryAcquire is the same as Acquire, but returns an error immediately if the pool is exhausted.
+	TryAcquire(ctx context.Context) (LeasedDatabase, error)
+	// Close drops databases of the leases which have not been released and makes the pool unusable.
+	Close(ctx context.Context) error
+}
+
+// LeasedDatabase is a logical database leased from [DatabasePool].
+type LeasedDatabase interface {
+	// Name returns the logical database name.
+	Name() string
+	// Release drops the logical database and returns the lease to the pool.
+	Release(ctx context.Context) error
+}
+
+// poolDatabaseNameFormat is the format of leased database names built from process id, pool id and lease number.
+const poolDatabaseNameFormat = "testutils_%d_%d_%d"
+
+var (
+	errPoolExhausted   = errors.New("database pool is exhausted")
+	errPoolClosed      = errors.New("database pool is closed")
+	errLeaseReleased   = errors.New("database lease has already been released")
+	errPoolClose       = errors.New("cannot drop some of the leased databases")
+	errInvalidPoolSize = errors.New("database pool size must be positive")
+	errNilPoolDatabase = errors.New("database pool container is nil")
+
+	// poolCounter holds the number of created pools, guarded by poolCounterMu. It is used to build pool ids.
+	poolCounter   int
+	poolCounterMu sync.Mutex
+)
+
+// databasePool holds pool state. Implements [DatabasePool] interface.
+type databasePool struct {
+	dc DatabaseContainer
+	// slots holds one element per active lease.
+	slots chan struct{}
+	// done is closed on pool Close, waking up blocked Acquire calls.
+	done chan struct{}
+	id   int
+
+	// creating tracks leases whose databases are being created, so that Close waits for them.
+	creating sync.WaitGroup
+
+	// mu guards the fields below.
+	mu     sync.Mutex
+	seq    int
+	closed bool
+	leases map[string]struct{}
+}
+
+// leasedDatabase holds leased database data. Implements [LeasedDatabase] interface.
+type leasedDatabase struct {
+	name string
+	pool *databasePool
+}
+
+// NewDatabasePool creates a new [DatabasePool] leasing at most size logical databases of the given database container
+// at a time. The container must be started and its [Database] must have CreateCommand and DropCommand set.
+func NewDatabasePool(dc DatabaseContainer, size int) (DatabasePool, error) {
+	if dc == nil {
+		return nil, errNilPoolDatabase
+	}
+	if size <= 0 {
+		return nil, errInvalidPoolSize
+	}
+	poolCounterMu.Lock()
+	poolCounter++
+	id := poolCounter
+	poolCounterMu.Unlock()
+	return &databasePool{
+		dc:     dc,
+		slots:  make(chan struct{}, size),
+		done:   make(chan struct{}),
+		id:     id,
+		leases: map[string]struct{}{},
+	}, nil
+}
+
+// Acquire creates a new logical database and leases it, blocking while the pool is exhausted.
+func (p *databasePool) Acquire(ctx context.Context) (LeasedDatabase, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-p.done:
+		return nil, errPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return p.lease(ctx)
+}
+
+// TryAcquire creates a new logical database and leases it. Returns an error if the pool is exhausted.
+func (p *databasePool) TryAcquire(ctx context.Context) (LeasedDatabase, error) {
+	select {
+	case <-p.done:
+		return nil, errPoolClosed
+	default:
+	}
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		return nil, errPoolExhausted
+	}
+	return p.lease(ctx)
+}
+
+// lease creates a new logical database in an acquired pool slot. The slot is freed on failure.
+func (p *databasePool) lease(ctx context.Context) (LeasedDatabase, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.slots
+		return nil, errPoolClosed
+	}
+	p.seq++
+	// Process id makes names unique across test binaries sharing one database container.
+	name := fmt.Sprintf(poolDatabaseNameFormat, os.Getpid(), p.id, p.seq)
+	p.leases[name] = struct{}{}
+	p.creating.Add(1)
+	p.mu.Unlock()
+	defer p.creating.Done()
+
+	err := p.dc.CreateDatabase(ctx, name)
+	p.mu.Lock()
+	closed := p.closed
+	if err != nil || closed {
+		delete(p.leases, name)
+	}
+	p.mu.Unlock()
+	switch {
+	case err != nil:
+		<-p.slots
+		return nil, err
+	case closed:
+		// The pool has been closed while the database was being created, Close does not drop it.
+		<-p.slots
+		if err = p.dc.DropDatabase(ctx, name); err != nil {
+			return nil, errors.Wrapf(errPoolClosed, "dropping database %s: %s", name, err)
+		}
+		return nil, errPoolClosed
+	}
+	return &leasedDatabase{name: name, pool: p}, nil
+}
+
+// release drops the leased logical database and frees its pool slot.
+func (p *databasePool) release(ctx context.Context, name string) error {
+	p.mu.Lock()
+	if _, ok := p.leases[name]; !ok {
+		p.mu.Unlock()
+		return errors.Wrap(errLeaseReleased, name)
+	}
+	delete(p.leases, name)
+	p.mu.Unlock()
+	defer func() { <-p.slots }()
+	return p.dc.DropDatabase(ctx, name)
+}
+
+// Close drops databases of the leases which have not been released, waiting for the leases being created.
+// Blocked Acquire calls return an error.
+func (p *databasePool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	p.mu.Unlock()
+
+	// Leases being created drop their databases themselves.
+	p.creating.Wait()
+	p.mu.Lock()
+	names := make([]string, 0, len(p.leases))
+	for name := range p.leases {
+		names = append(names, name)
+	}
+	p.leases = map[string]struct{}{}
+	p.mu.Unlock()
+
+	var failed []string
+	for _, name := range names {
+		if err := p.dc.DropDatabase(ctx, name); err != nil {
+			failed = append(failed, name+": "+err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return errors.Wrapf(errPoolClose, "%v", failed)
+	}
+	return nil
+}
+
+// Name returns the leased logical database name.
+func (l *leasedDatabase) Name() string {
+	return l.name
+}
+
+// Release drops the leased logical database and returns the lease to the pool.
+func (l *leasedDatabase) Release(ctx context.Context) error {
+	return l.pool.release(ctx, l.name)
+}
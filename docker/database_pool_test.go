@@ -0,0 +1,233 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPoolDatabase implements [DatabaseContainer] interface methods used by database pool.
+type stubPoolDatabase struct {
+	DatabaseContainer
+	mu               sync.Mutex
+	databases        map[string]bool
+	created, dropped int
+	dropErr          error
+	// creating, if set, receives database names on CreateDatabase calls, which then wait for resume to be closed.
+	creating chan string
+	resume   chan struct{}
+}
+
+// CreateDatabase is a stubbed [DatabaseContainer] method.
+func (s *stubPoolDatabase) CreateDatabase(_ context.Context, name string) error {
+	if s.creating != nil {
+		s.creating <- name
+		<-s.resume
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.databases[name] {
+		return fmt.Errorf("database %s already exists", name)
+	}
+	s.databases[name] = true
+	s.created++
+	return nil
+}
+
+// DropDatabase is a stubbed [DatabaseContainer] method.
+func (s *stubPoolDatabase) DropDatabase(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.databases, name)
+	s.dropped++
+	return s.dropErr
+}
+
+func newStubPoolDatabase() *stubPoolDatabase {
+	return &stubPoolDatabase{databases: map[string]bool{}}
+}
+
+func Test_DatabasePoolConcurrentLeases(t *testing.T) {
+	db := newStubPoolDatabase()
+	pool, err := NewDatabasePool(db, 3)
+	require.NoError(t, err)
+	var (
+		wg                  sync.WaitGroup
+		inUse, maxInUse     int32
+		namesMu             sync.Mutex
+		names               = map[string]bool{}
+		errorsCount, leases int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := pool.Acquire(context.Background())
+			if err != nil {
+				atomic.AddInt32(&errorsCount, 1)
+				return
+			}
+			current := atomic.AddInt32(&inUse, 1)
+			for {
+				observed := atomic.LoadInt32(&maxInUse)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxInUse, observed, current) {
+					break
+				}
+			}
+			namesMu.Lock()
+			names[lease.Name()] = true
+			namesMu.Unlock()
+			time.Sleep(time.Millisecond * 5)
+			atomic.AddInt32(&inUse, -1)
+			atomic.AddInt32(&leases, 1)
+			if err := lease.Release(context.Background()); err != nil {
+				atomic.AddInt32(&errorsCount, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Zero(t, errorsCount)
+	require.Equal(t, int32(20), leases)
+	require.LessOrEqual(t, maxInUse, int32(3))
+	require.Len(t, names, 20)
+	require.Equal(t, 20, db.created)
+	require.Equal(t, 20, db.dropped)
+	require.Empty(t, db.databases)
+	require.NoError(t, pool.Close(context.Background()))
+}
+
+func Test_DatabasePoolExhaustion(t *testing.T) {
+	pool, err := NewDatabasePool(newStubPoolDatabase(), 1)
+	require.NoError(t, err)
+	lease, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	_, err = pool.TryAcquire(context.Background())
+	require.ErrorIs(t, err, errPoolExhausted)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	acquired := make(chan LeasedDatabase)
+	go func() {
+		next, err := pool.Acquire(context.Background())
+		if err == nil {
+			acquired <- next
+		}
+		close(acquired)
+	}()
+	require.NoError(t, lease.Release(context.Background()))
+	next := <-acquired
+	require.NotNil(t, next)
+	require.NotEqual(t, lease.Name(), next.Name())
+	require.ErrorIs(t, lease.Release(context.Background()), errLeaseReleased)
+}
+
+func Test_DatabasePoolClose(t *testing.T) {
+	db := newStubPoolDatabase()
+	pool, err := NewDatabasePool(db, 2)
+	require.NoError(t, err)
+	leaked, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	released, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, released.Release(context.Background()))
+	_, err = pool.Acquire(context.Background())
+	require.NoError(t, err)
+
+	blocked := make(chan error)
+	go func() {
+		_, err := pool.Acquire(context.Background())
+		blocked <- err
+	}()
+	require.NoError(t, pool.Close(context.Background()))
+	require.ErrorIs(t, <-blocked, errPoolClosed)
+	require.Empty(t, db.databases)
+	require.Equal(t, 3, db.dropped)
+	require.ErrorIs(t, leaked.Release(context.Background()), errLeaseReleased)
+	_, err = pool.TryAcquire(context.Background())
+	require.ErrorIs(t, err, errPoolClosed)
+	require.NoError(t, pool.Close(context.Background()))
+}
+
+func Test_DatabasePoolCloseDuringLease(t *testing.T) {
+	db := newStubPoolDatabase()
+	db.creating, db.resume = make(chan string), make(chan struct{})
+	pool, err := NewDatabasePool(db, 1)
+	require.NoError(t, err)
+	leased := make(chan error)
+	go func() {
+		_, err := pool.Acquire(context.Background())
+		leased <- err
+	}()
+	<-db.creating
+
+	closed := make(chan error)
+	go func() { closed <- pool.Close(context.Background()) }()
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the database being created was dropped")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(db.resume)
+	require.ErrorIs(t, <-leased, errPoolClosed)
+	require.NoError(t, <-closed)
+	require.Empty(t, db.databases)
+	require.Equal(t, 1, db.created)
+	require.Equal(t, 1, db.dropped)
+}
+
+func Test_DatabasePoolCloseErrors(t *testing.T) {
+	db := newStubPoolDatabase()
+	db.dropErr = errors.New("database is being accessed by other users")
+	pool, err := NewDatabasePool(db, 1)
+	require.NoError(t, err)
+	_, err = pool.Acquire(context.Background())
+	require.NoError(t, err)
+	err = pool.Close(context.Background())
+	require.ErrorIs(t, err, errPoolClose)
+	require.Contains(t, err.Error(), "database is being accessed by other users")
+}
+
+func Test_NewDatabasePoolErrors(t *testing.T) {
+	_, err := NewDatabasePool(nil, 1)
+	require.ErrorIs(t, err, errNilPoolDatabase)
+	_, err = NewDatabasePool(newStubPoolDatabase(), 0)
+	require.ErrorIs(t, err, errInvalidPoolSize)
+}
+
+func Test_DatabasePoolCommands(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	dc := NewDatabaseContainerWithOptions(mockedImageName, Database{
+		CreateCommand: "createdb --username=postgres",
+		DropCommand:   "dropdb --force --username=postgres",
+	}, Options{Name: mockedContainerName})
+	pool, err := NewDatabasePool(dc, 1)
+	require.NoError(t, err)
+	lease, err := pool.Acquire(context.Background())
+	require.NoError(t, err)
+	require.Regexp(t, fmt.Sprintf(`^testutils_%d_\d+_1$`, os.Getpid()), lease.Name())
+	require.NoError(t, lease.Release(context.Background()))
+	require.Equal(t, []types.ExecConfig{
+		{AttachStdout: true, AttachStderr: true, Cmd: []string{"bash", "-c", "createdb --username=postgres '" + lease.Name() + "'"}},
+		{AttachStdout: true, AttachStderr: true, Cmd: []string{"bash", "-c", "dropdb --force --username=postgres '" + lease.Name() + "'"}},
+	}, mockedExecConfigs)
+
+	dc = NewDatabaseContainerWithOptions(mockedImageName, Database{}, Options{Name: mockedContainerName})
+	pool, err = NewDatabasePool(dc, 1)
+	require.NoError(t, err)
+	_, err = pool.Acquire(context.Background())
+	require.ErrorIs(t, err, errCreateCommandNotSet)
+	_, err = pool.TryAcquire(context.Background())
+	require.ErrorIs(t, err, errCreateCommandNotSet)
+}
@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+)
+
+const (
+	// debugHoldTimeoutEnv is the name of the environment variable setting the debug hold duration, e.g. "30m".
+	debugHoldTimeoutEnv = "TESTUTILS_DEBUG_HOLD_TIMEOUT"
+	// defaultDebugHoldTimeout is the debug hold duration used if the environment variable is not set.
+	defaultDebugHoldTimeout = 10 * time.Minute
+	// debugHoldDeadlineMargin is the time left before the test deadline for the teardown after a debug hold.
+	debugHoldDeadlineMargin = 30 * time.Second
+)
+
+// debugHold blocks after the container readiness failure until an interrupt signal is received, the context is
+// canceled, or the hold timeout expires, so that a debugger can be attached or commands run in the container.
+// The hold timeout is read from TESTUTILS_DEBUG_HOLD_TIMEOUT environment variable and defaults to 10 minutes.
+// If [Options.TB] is set, the hold ends before the test deadline, if any, and instructions are also written
+// to the test log.
+func (c *container) debugHold(ctx context.Context, cause error) {
+	timeout := debugHoldTimeout()
+	if t, isTest := c.options.TB.(interface{ Deadline() (time.Time, bool) }); isTest {
+		if deadline, hasDeadline := t.Deadline(); hasDeadline {
+			if untilDeadline := time.Until(deadline) - debugHoldDeadlineMargin; untilDeadline < timeout {
+				timeout = untilDeadline
+			}
+		}
+	}
+	if timeout <= 0 {
+		return
+	}
+	format := "debug hold: container %s (%s) is not ready: %v. Holding for %s, interrupt (Ctrl+C) to continue, " +
+		"e.g. run `docker exec -it %s sh` meanwhile"
+	args := []any{c.options.Name, c.id, cause, timeout, c.id}
+	warnf(format, args...)
+	if c.options.TB != nil {
+		c.options.TB.Logf(format, args...)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-signals:
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// debugHoldTimeout returns the debug hold duration set in TESTUTILS_DEBUG_HOLD_TIMEOUT environment variable,
+// or the default one if the variable is not set or cannot be parsed.
+func debugHoldTimeout() time.Duration {
+	value, found := os.LookupEnv(debugHoldTimeoutEnv)
+	if !found {
+		return defaultDebugHoldTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		warnf("invalid %s value %q, using %s: %v", debugHoldTimeoutEnv, value, defaultDebugHoldTimeout, err)
+		return defaultDebugHoldTimeout
+	}
+	return timeout
+}
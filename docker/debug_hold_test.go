@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTB records test log messages.
+type recordingTB struct {
+	testing.TB
+	messages []string
+}
+
+// Logf is a mocked [testing.TB] method.
+func (tb *recordingTB) Logf(format string, args ...any) {
+	tb.messages = append(tb.messages, format)
+}
+
+func Test_StartDebugHold(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	l := &mockedLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	t.Setenv(debugHoldTimeoutEnv, "50ms")
+	tests := []struct {
+		name         string
+		debugHold    bool
+		expectedHold bool
+	}{
+		{"hold", true, true},
+		{"no_hold", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			l.messages = nil
+			mockedContainerListValues = mockedCreatedContainerListValues()
+			tb := &recordingTB{}
+			c := NewContainerWithOptions(mockedImageName, Options{
+				Name:         mockedContainerName,
+				StartTimeout: 1,
+				DebugHold:    test.debugHold,
+				TB:           tb,
+			})
+			start := time.Now()
+			require.ErrorIs(t, c.Start(context.Background()), errContainerStartTimeout)
+			held := time.Since(start) >= 50*time.Millisecond
+			require.Equal(t, test.expectedHold, held)
+			require.Equal(t, test.expectedHold, len(tb.messages) == 1)
+			if test.expectedHold {
+				require.Len(t, l.messages, 1)
+				require.True(t, strings.HasPrefix(l.messages[0], "WARNING: debug hold: container "+mockedContainerName+
+					" ("+mockedContainerID+") is not ready: container start timeout. Holding for 50ms"))
+			}
+		})
+	}
+}
+
+func Test_debugHoldTimeout(t *testing.T) {
+	SetLogger(nil)
+	t.Setenv(debugHoldTimeoutEnv, "30m")
+	require.Equal(t, 30*time.Minute, debugHoldTimeout())
+	t.Setenv(debugHoldTimeoutEnv, "forever")
+	require.Equal(t, defaultDebugHoldTimeout, debugHoldTimeout())
+}
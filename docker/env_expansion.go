@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// containerNameVariable is the built-in variable resolving to the container name in environment variables values.
+const containerNameVariable = "CONTAINER_NAME"
+
+var (
+	errUnknownEnvVariable = errors.New("unknown variable referenced in environment variable value")
+
+	// envReference matches `${NAME}` references in environment variables values. A reference prefixed with
+	// another `$` is escaped, e.g. `$${NAME}` is kept as `${NAME}`.
+	envReference = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// expandEnvironment expands `${NAME}` references in environment variables values set in the given options.
+// References are resolved against the previously set environment variables and built-in variables:
+// `${CONTAINER_NAME}` and `${IMAGE}`. A reference to an unknown variable, e.g. to a variable set later
+// in the list, is an error. Options slice is copied, so that the original one is not modified.
+func expandEnvironment(image string, options *Options) error {
+	if len(options.EnvironmentVariables) == 0 {
+		return nil
+	}
+	resolved := map[string]string{"IMAGE": image}
+	if len(options.Name) > 0 {
+		resolved[containerNameVariable] = options.Name
+	}
+	expanded := make([]string, len(options.EnvironmentVariables))
+	for i, variable := range options.EnvironmentVariables {
+		key, value, _ := strings.Cut(variable, "=")
+		var unknown []string
+		value = envReference.ReplaceAllStringFunc(value, func(reference string) string {
+			if strings.HasPrefix(reference, "$$") {
+				return reference[1:]
+			}
+			name := reference[2 : len(reference)-1]
+			resolvedValue, found := resolved[name]
+			if !found {
+				unknown = append(unknown, name)
+			}
+			return resolvedValue
+		})
+		if len(unknown) > 0 {
+			return errors.Wrapf(errUnknownEnvVariable, "%s: %s", key, strings.Join(unknown, ", "))
+		}
+		if strings.Contains(variable, "=") {
+			expanded[i] = key + "=" + value
+			resolved[key] = value
+		} else {
+			expanded[i] = variable
+		}
+	}
+	options.EnvironmentVariables = expanded
+	return nil
+}
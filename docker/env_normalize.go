@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+)
+
+// NormalizeEnv returns environment variables in "name=value" format de-duplicated and sorted by name. If a variable
+// is set several times, the last value wins, so that later sources, e.g. per-test overrides appended to preset
+// variables, take precedence. Values may contain `=` and be empty. An entry without `=` is kept as is and is
+// overridden by a later entry with the same name.
+func NormalizeEnv(env []string) []string {
+	normalized, _ := normalizeEnv(env)
+	return normalized
+}
+
+// normalizeEnv returns environment variables de-duplicated and sorted by name, and the sorted names of variables
+// set several times.
+func normalizeEnv(env []string) ([]string, []string) {
+	if env == nil {
+		return nil, nil
+	}
+	last := make(map[string]string, len(env))
+	overridden := map[string]bool{}
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		if _, found := last[name]; found {
+			overridden[name] = true
+		}
+		last[name] = variable
+	}
+	names := make([]string, 0, len(last))
+	for name := range last {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, last[name])
+	}
+	overriddenNames := make([]string, 0, len(overridden))
+	for name := range overridden {
+		overriddenNames = append(overriddenNames, name)
+	}
+	sort.Strings(overriddenNames)
+	return normalized, overriddenNames
+}
+
+// applyNormalizedEnv de-duplicates and sorts environment variables set in the given options and warns about
+// overridden ones.
+func applyNormalizedEnv(options *Options) {
+	normalized, overridden := normalizeEnv(options.EnvironmentVariables)
+	if len(overridden) > 0 {
+		warnf("container %s environment variables are set several times, the last values are used: %s",
+			options.Name, strings.Join(overridden, ", "))
+	}
+	options.EnvironmentVariables = normalized
+}
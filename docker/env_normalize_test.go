@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      []string
+		expected []string
+	}{
+		{"nil", nil, nil},
+		{"empty", []string{}, []string{}},
+		{"sorted", []string{"B=2", "A=1", "C=3"}, []string{"A=1", "B=2", "C=3"}},
+		{"last_wins", []string{"A=1", "B=2", "A=3"}, []string{"A=3", "B=2"}},
+		{"value_with_equals", []string{"URL=http://host/?a=b&c=d", "URL=x=y"}, []string{"URL=x=y"}},
+		{"empty_value", []string{"A=1", "A="}, []string{"A="}},
+		{"empty_value_overridden", []string{"A=", "A=1"}, []string{"A=1"}},
+		{"without_equals", []string{"HOME", "A=1"}, []string{"A=1", "HOME"}},
+		{"without_equals_overridden", []string{"HOME", "HOME=/root"}, []string{"HOME=/root"}},
+		{"prefix_names", []string{"AB=2", "A=1", "A_B=3"}, []string{"A=1", "AB=2", "A_B=3"}},
+		{"duplicate_entries", []string{"A=1", "A=1"}, []string{"A=1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, NormalizeEnv(test.env))
+		})
+	}
+}
+
+func Test_createContainerNormalizesEnv(t *testing.T) {
+	resetMocks()
+	l := &mockedLogger{}
+	SetLogger(l)
+	defer SetLogger(nil)
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	options := Options{Name: "db", EnvironmentVariables: []string{"POSTGRES_USER=postgres", "PGDATA=/data", "POSTGRES_USER=test"}}
+	_, err := c.createContainer(context.Background(), mockedImageName, &options)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PGDATA=/data", "POSTGRES_USER=test"}, mockedContainerCreateConfig.Env)
+	require.Equal(t, []string{
+		"WARNING: container db environment variables are set several times, the last values are used: POSTGRES_USER",
+	}, l.messages)
+	require.Len(t, options.EnvironmentVariables, 3)
+}
@@ -0,0 +1,50 @@
+package docker
+
+import "time"
+
+// LifecyclePhase identifies a container lifecycle phase reported in [LifecycleEvent].
+type LifecyclePhase string
+
+// Container lifecycle phases.
+const (
+	PhasePullStarted  LifecyclePhase = "pull_started"
+	PhasePullFinished LifecyclePhase = "pull_finished"
+	PhaseCreated      LifecyclePhase = "created"
+	PhaseStarted      LifecyclePhase = "started"
+	PhaseHealthy      LifecyclePhase = "healthy"
+	PhaseStopped      LifecyclePhase = "stopped"
+	PhaseRemoved      LifecyclePhase = "removed"
+	PhaseError        LifecyclePhase = "error"
+)
+
+// LifecycleEvent holds data of a container lifecycle event passed to [Options.OnEvent] callback.
+type LifecycleEvent struct {
+	Phase LifecyclePhase
+	// Name and ID hold container name and id known at the moment of the event. Both can be empty, e.g.
+	// ID is empty before the container is created.
+	Name, ID string
+	Time     time.Time
+	// Err holds the error which caused [PhaseError] event.
+	Err error
+}
+
+// emit passes a lifecycle event to the container [Options.OnEvent] callback, if it is set.
+// Events of one container are passed to the callback sequentially.
+func (c *container) emit(phase LifecyclePhase, err error) {
+	if c.options.OnEvent == nil {
+		return
+	}
+	c.eventsMu.Lock()
+	defer c.eventsMu.Unlock()
+	c.options.OnEvent(LifecycleEvent{Phase: phase, Name: c.options.Name, ID: c.id, Time: time.Now(), Err: err})
+}
+
+// emitResult emits the given phase event if err is nil, or [PhaseError] event otherwise. Returns err.
+func (c *container) emitResult(phase LifecyclePhase, err error) error {
+	if err != nil {
+		c.emit(PhaseError, err)
+		return err
+	}
+	c.emit(phase, nil)
+	return nil
+}
@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordPhases returns an OnEvent callback recording events phases into the given slice.
+func recordPhases(phases *[]LifecyclePhase) func(e LifecycleEvent) {
+	return func(e LifecycleEvent) {
+		*phases = append(*phases, e.Phase)
+	}
+}
+
+func Test_lifecycleEvents(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name           string
+		setupMocks     func()
+		function       func(_ Container, ctx context.Context) error
+		expectedPhases []LifecyclePhase
+	}{
+		{"createStart", func() {
+			mockedContainerListValues = newContainerListMockValues(
+				containerListMockValue{mockedCreatedInContainerList, nil},
+				containerListMockValue{mockedRunningInContainerList, nil},
+			)
+		}, Container.CreateStart, []LifecyclePhase{
+			PhasePullStarted, PhasePullFinished, PhaseCreated, PhaseStarted, PhaseHealthy,
+		}},
+		{"create_pull_error", func() { mockedImagePullError = errInvalidImagePullMock }, Container.Create, []LifecyclePhase{
+			PhasePullStarted, PhaseError,
+		}},
+		{"create_error", func() { mockedContainerCreateError = errDuplicateContainerNameMock }, Container.Create, []LifecyclePhase{
+			PhasePullStarted, PhasePullFinished, PhaseError,
+		}},
+		{"stop", nil, Container.Stop, []LifecyclePhase{PhaseStopped}},
+		{"remove", nil, Container.Remove, []LifecyclePhase{PhaseRemoved}},
+		{"stopRemove", nil, Container.StopRemove, []LifecyclePhase{PhaseStopped, PhaseRemoved}},
+		{"stopRemove_container_notfound", func() {
+			mockedContainerListValues = mockedContainerListValuesEmpty
+		}, Container.StopRemove, nil},
+		{"stop_data_fetch_error", func() {
+			mockedContainerListValues = mockedContainerListValuesEmptyTechnical
+		}, Container.Stop, []LifecyclePhase{PhaseError}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			if test.setupMocks != nil {
+				test.setupMocks()
+			}
+			var phases []LifecyclePhase
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, OnEvent: recordPhases(&phases)})
+			_ = test.function(c, context.Background())
+			require.Equal(t, test.expectedPhases, phases)
+		})
+	}
+}
+
+func Test_lifecycleEventData(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerCreateError = errDuplicateContainerNameMock
+	var events []LifecycleEvent
+	c := NewContainerWithOptions(mockedImageName, Options{
+		Name:    mockedContainerName,
+		OnEvent: func(e LifecycleEvent) { events = append(events, e) },
+	})
+	require.ErrorIs(t, c.Create(context.Background()), errDuplicateContainerNameMock)
+
+	last := events[len(events)-1]
+	require.Equal(t, PhaseError, last.Phase)
+	require.Equal(t, mockedContainerName, last.Name)
+	require.ErrorIs(t, last.Err, errDuplicateContainerNameMock)
+	require.False(t, last.Time.IsZero())
+}
+
+func Test_lifecycleEventsNilCallback(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.NotPanics(t, func() { require.NoError(t, c.CreateStart(context.Background())) })
+}
+
+func Test_lifecycleEventsNotConcurrent(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	var inCallback, maxInCallback, calls int32
+	c := NewContainerWithOptions(mockedImageName, Options{
+		Name: mockedContainerName,
+		OnEvent: func(e LifecycleEvent) {
+			n := atomic.AddInt32(&inCallback, 1)
+			if n > atomic.LoadInt32(&maxInCallback) {
+				atomic.StoreInt32(&maxInCallback, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&calls, 1)
+			atomic.AddInt32(&inCallback, -1)
+		},
+	})
+	require.NoError(t, c.Create(context.Background()))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = c.Stop(context.Background())
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(13), atomic.LoadInt32(&calls))
+	require.Equal(t, int32(1), atomic.LoadInt32(&maxInCallback))
+}
@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+var errEmptyExecCommand = errors.New("empty exec command")
+
+// ExecOptions holds command execution attributes values.
+type ExecOptions struct {
+	// Cmd holds the command and its arguments, e.g. `[]string{"bash", "-c", "ls /data"}`.
+	Cmd []string
+	// Env holds environment variables in "name=value" format set for the command in addition to the container ones.
+	Env []string
+	// User sets the user the command is run as, e.g. "root" or "1000:1000". By default, the container user is used.
+	User string
+	// WorkingDir sets the command working directory. By default, the container working directory is used.
+	WorkingDir string
+	// Tty allocates a pseudo-TTY for the command. Its output is not split into stdout and stderr then
+	// and is returned as stdout.
+	Tty bool
+	// Stdin, if set, is copied to the command standard input.
+	Stdin io.Reader
+}
+
+// ExecCommandWithOptions executes command in Docker container identified by the given id with the given options
+// and returns its output and exit code.
+func ExecCommandWithOptions(ctx context.Context, id string, options ExecOptions) (ExecResult, error) {
+	if len(options.Cmd) == 0 {
+		return ExecResult{}, errEmptyExecCommand
+	}
+	c, err := getClient()
+	if err != nil {
+		return ExecResult{}, err
+	}
+	defer c.close()
+	return c.execWithResult(ctx, id, types.ExecConfig{
+		Cmd:        options.Cmd,
+		Env:        options.Env,
+		User:       options.User,
+		WorkingDir: options.WorkingDir,
+		Tty:        options.Tty,
+	}, options.Stdin)
+}
@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecCommandWithOptions(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	output := ExecResult{ExitCode: 3, Stdout: "out", Stderr: "err"}
+	tests := []struct {
+		name           string
+		options        ExecOptions
+		expectedConfig types.ExecConfig
+		expectedResult ExecResult
+		expectedStdin  string
+	}{
+		{
+			"command",
+			ExecOptions{Cmd: []string{"ls", "/data"}},
+			types.ExecConfig{Cmd: []string{"ls", "/data"}, AttachStdout: true, AttachStderr: true},
+			output, "",
+		},
+		{
+			"env",
+			ExecOptions{Cmd: []string{"env"}, Env: []string{"A=1", "B="}},
+			types.ExecConfig{Cmd: []string{"env"}, Env: []string{"A=1", "B="}, AttachStdout: true, AttachStderr: true},
+			output, "",
+		},
+		{
+			"user",
+			ExecOptions{Cmd: []string{"id"}, User: "1000:1000"},
+			types.ExecConfig{Cmd: []string{"id"}, User: "1000:1000", AttachStdout: true, AttachStderr: true},
+			output, "",
+		},
+		{
+			"working_dir",
+			ExecOptions{Cmd: []string{"pwd"}, WorkingDir: "/var/lib"},
+			types.ExecConfig{Cmd: []string{"pwd"}, WorkingDir: "/var/lib", AttachStdout: true, AttachStderr: true},
+			output, "",
+		},
+		{
+			"tty",
+			ExecOptions{Cmd: []string{"top", "-b"}, Tty: true},
+			types.ExecConfig{Cmd: []string{"top", "-b"}, Tty: true, AttachStdout: true, AttachStderr: true},
+			ExecResult{ExitCode: 3, Stdout: "out"}, "",
+		},
+		{
+			"stdin",
+			ExecOptions{Cmd: []string{"psql"}, Stdin: strings.NewReader("SELECT 1;\n")},
+			types.ExecConfig{Cmd: []string{"psql"}, AttachStdin: true, AttachStdout: true, AttachStderr: true},
+			output, "SELECT 1;\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(types.ExecConfig) ExecResult { return output }
+			result, err := ExecCommandWithOptions(context.Background(), mockedContainerID, test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedResult, result)
+			require.Equal(t, []types.ExecConfig{test.expectedConfig}, mockedExecConfigs)
+			require.Equal(t, test.expectedStdin, mockedExecStdin.String())
+			require.Equal(t, test.options.Stdin != nil, mockedExecStdinClosed)
+		})
+	}
+}
+
+func TestExecCommandWithOptionsEmptyCommand(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	_, err := ExecCommandWithOptions(context.Background(), mockedContainerID, ExecOptions{})
+	require.ErrorIs(t, err, errEmptyExecCommand)
+	require.Empty(t, mockedExecConfigs)
+}
+
+func Test_Exec(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedExecScript = func(types.ExecConfig) ExecResult { return ExecResult{Stdout: "out\n", Stderr: "err\n"} }
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	buffer := bytes.Buffer{}
+	require.NoError(t, c.Exec(context.Background(), "ls /data", &buffer))
+	require.Equal(t, "out\nerr\n", buffer.String())
+	require.Equal(t, []string{"bash", "-c", "ls /data"}, mockedExecConfigs[0].Cmd)
+}
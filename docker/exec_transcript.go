@@ -0,0 +1,179 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+var (
+	errExecNotRun = errors.New("no command containing the given text has been run")
+	errExecOrder  = errors.New("commands have been run in unexpected order")
+)
+
+// ExecCall holds a command executed in a container.
+type ExecCall struct {
+	Cmd              []string
+	User, WorkingDir string
+}
+
+// String returns the command line, e.g. `psql -c select 1`, followed by the user and working directory, if set.
+func (c ExecCall) String() string {
+	s := strings.Join(c.Cmd, " ")
+	if len(c.User) > 0 {
+		s += " (user " + c.User + ")"
+	}
+	if len(c.WorkingDir) > 0 {
+		s += " (in " + c.WorkingDir + ")"
+	}
+	return s
+}
+
+// ExecTranscript holds commands executed in containers since [StartExecTranscript] call. Commands are recorded
+// in the order they are started, so that commands run sequentially by one goroutine are recorded in the program
+// order also when other goroutines execute commands concurrently.
+type ExecTranscript struct {
+	mu    sync.Mutex
+	calls []transcriptCall
+	// names maps ids of containers created or looked up while recording to their names.
+	names map[string]string
+}
+
+// transcriptCall holds a command executed in the container with the given id or name.
+type transcriptCall struct {
+	container string
+	call      ExecCall
+}
+
+// StartExecTranscript makes the package record commands executed in containers, e.g. to check that migrations
+// have been applied in order. Commands are passed to the current client, so that a transcript can also be recorded
+// without Docker daemon after [StartReplay] call. The returned function stops recording.
+func StartExecTranscript() (*ExecTranscript, func(), error) {
+	previous := cli
+	c, err := getClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	transcript := &ExecTranscript{names: map[string]string{}}
+	cli = &transcriptClient{client: c, transcript: transcript}
+	return transcript, func() { cli = previous }, nil
+}
+
+// ExecCalls returns commands executed in the container with the given name or id in the order they have been
+// started.
+func (t *ExecTranscript) ExecCalls(containerName string) []ExecCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var calls []ExecCall
+	for _, recorded := range t.calls {
+		if recorded.container == containerName || t.names[recorded.container] == containerName {
+			calls = append(calls, recorded.call)
+		}
+	}
+	return calls
+}
+
+// RanBefore checks that the first command containing the before text has been run in the container with the given
+// name or id before the first command containing the after text, e.g. `RanBefore("db", "001_init", "002_users")`.
+// The returned error lists the commands run in the container.
+func (t *ExecTranscript) RanBefore(containerName, before, after string) error {
+	calls := t.ExecCalls(containerName)
+	beforeIndex, afterIndex := firstCallContaining(calls, before), firstCallContaining(calls, after)
+	switch {
+	case beforeIndex < 0:
+		return errors.Wrapf(errExecNotRun, "%q in container %s%s", before, containerName, describeCalls(calls))
+	case afterIndex < 0:
+		return errors.Wrapf(errExecNotRun, "%q in container %s%s", after, containerName, describeCalls(calls))
+	case afterIndex < beforeIndex:
+		return errors.Wrapf(errExecOrder, "%q has been run in container %s as command %d, before %q run as command %d%s",
+			after, containerName, afterIndex+1, before, beforeIndex+1, describeCalls(calls))
+	}
+	return nil
+}
+
+// firstCallContaining returns the index of the first call which command line contains the given text, or -1.
+func firstCallContaining(calls []ExecCall, text string) int {
+	for i, call := range calls {
+		if strings.Contains(strings.Join(call.Cmd, " "), text) {
+			return i
+		}
+	}
+	return -1
+}
+
+// describeCalls returns a numbered list of the given calls.
+func describeCalls(calls []ExecCall) string {
+	if len(calls) == 0 {
+		return ", no commands have been run"
+	}
+	b := strings.Builder{}
+	b.WriteString(", commands run:")
+	for i, call := range calls {
+		fmt.Fprintf(&b, "\n  %d. %s", i+1, call)
+	}
+	return b.String()
+}
+
+// record appends a command executed in the container with the given id or name.
+func (t *ExecTranscript) record(container string, call ExecCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, transcriptCall{container: container, call: call})
+}
+
+// name saves the name of the container with the given id.
+func (t *ExecTranscript) name(id, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.names[id] = strings.TrimPrefix(name, "/")
+}
+
+// transcriptClient passes calls to the wrapped client and records executed commands in the transcript.
+// Implements client interface.
+type transcriptClient struct {
+	client
+	transcript *ExecTranscript
+}
+
+func (c *transcriptClient) createContainer(ctx context.Context, image string, options *Options) (string, error) {
+	id, err := c.client.createContainer(ctx, image, options)
+	if err == nil {
+		c.transcript.name(id, options.Name)
+	}
+	return id, err
+}
+
+func (c *transcriptClient) listContainers(
+	ctx context.Context,
+	filters dockerContainerFilters.Args,
+) ([]types.Container, error) {
+	containers, err := c.client.listContainers(ctx, filters)
+	for _, container := range containers {
+		if len(container.Names) > 0 {
+			c.transcript.name(container.ID, container.Names[0])
+		}
+	}
+	return containers, err
+}
+
+func (c *transcriptClient) execCommand(ctx context.Context, id string, command string, buffer *bytes.Buffer) error {
+	c.transcript.record(id, ExecCall{Cmd: []string{"bash", "-c", command}})
+	return c.client.execCommand(ctx, id, command, buffer)
+}
+
+func (c *transcriptClient) execWithResult(
+	ctx context.Context,
+	id string,
+	config types.ExecConfig,
+	stdin io.Reader,
+) (ExecResult, error) {
+	c.transcript.record(id, ExecCall{Cmd: config.Cmd, User: config.User, WorkingDir: config.WorkingDir})
+	return c.client.execWithResult(ctx, id, config, stdin)
+}
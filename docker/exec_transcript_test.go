@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// execOnlyClient is a client executing commands without Docker daemon.
+type execOnlyClient struct {
+	client
+}
+
+func (c *execOnlyClient) execWithResult(context.Context, string, types.ExecConfig, io.Reader) (ExecResult, error) {
+	return ExecResult{}, nil
+}
+
+func Test_StartExecTranscript(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	transcript, stop, err := StartExecTranscript()
+	require.NoError(t, err)
+
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	require.NoError(t, c.Create(context.Background()))
+	require.NoError(t, c.Exec(context.Background(), "migrate 001_init.sql", &bytes.Buffer{}))
+	_, err = ExecCommandWithOptions(context.Background(), mockedContainerID, ExecOptions{
+		Cmd:        []string{"migrate", "002_users.sql"},
+		User:       "postgres",
+		WorkingDir: "/migrations",
+	})
+	require.NoError(t, err)
+	require.NoError(t, ExecCommand(context.Background(), "other", "echo ok", &bytes.Buffer{}))
+	stop()
+	require.NoError(t, ExecCommand(context.Background(), mockedContainerID, "not recorded", &bytes.Buffer{}))
+
+	expected := []ExecCall{
+		{Cmd: []string{"bash", "-c", "migrate 001_init.sql"}},
+		{Cmd: []string{"migrate", "002_users.sql"}, User: "postgres", WorkingDir: "/migrations"},
+	}
+	require.Equal(t, expected, transcript.ExecCalls(mockedContainerName))
+	require.Equal(t, expected, transcript.ExecCalls(mockedContainerID))
+	require.Equal(t, []ExecCall{{Cmd: []string{"bash", "-c", "echo ok"}}}, transcript.ExecCalls("other"))
+	require.Empty(t, transcript.ExecCalls("unknown"))
+}
+
+func Test_ExecTranscriptConcurrentOrder(t *testing.T) {
+	transcript := &ExecTranscript{names: map[string]string{}}
+	c := &transcriptClient{client: &execOnlyClient{}, transcript: transcript}
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(container string) {
+			defer wg.Done()
+			for step := 0; step < 50; step++ {
+				_, err := c.execWithResult(context.Background(), container, types.ExecConfig{
+					Cmd: []string{"step", fmt.Sprint(step)},
+				}, nil)
+				require.NoError(t, err)
+			}
+		}(fmt.Sprintf("container-%d", i))
+	}
+	wg.Wait()
+
+	for i := 0; i < 8; i++ {
+		calls := transcript.ExecCalls(fmt.Sprintf("container-%d", i))
+		require.Len(t, calls, 50)
+		for step, call := range calls {
+			require.Equal(t, []string{"step", fmt.Sprint(step)}, call.Cmd)
+		}
+	}
+}
+
+func Test_ExecTranscriptRanBefore(t *testing.T) {
+	transcript := &ExecTranscript{names: map[string]string{"0123abcd": "db"}}
+	transcript.record("0123abcd", ExecCall{Cmd: []string{"psql", "-f", "001_init.sql"}})
+	transcript.record("0123abcd", ExecCall{Cmd: []string{"psql", "-f", "003_orders.sql"}, User: "postgres"})
+	transcript.record("0123abcd", ExecCall{Cmd: []string{"psql", "-f", "002_users.sql"}, WorkingDir: "/migrations"})
+	tests := []struct {
+		name          string
+		container     string
+		before, after string
+		expectedError error
+		expectedText  string
+	}{
+		{"in_order", "db", "001_init", "002_users", nil, ""},
+		{"by_id", "0123abcd", "001_init", "003_orders", nil, ""},
+		{
+			"out_of_order", "db", "002_users", "003_orders", errExecOrder,
+			`"003_orders" has been run in container db as command 2, before "002_users" run as command 3, commands run:
+  1. psql -f 001_init.sql
+  2. psql -f 003_orders.sql (user postgres)
+  3. psql -f 002_users.sql (in /migrations)`,
+		},
+		{"before_not_run", "db", "000_schema", "001_init", errExecNotRun, `"000_schema" in container db, commands run:`},
+		{"after_not_run", "db", "001_init", "004_items", errExecNotRun, `"004_items" in container db, commands run:`},
+		{"unknown_container", "cache", "001_init", "002_users", errExecNotRun,
+			`"001_init" in container cache, no commands have been run`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := transcript.RanBefore(test.container, test.before, test.after)
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, test.expectedError)
+			require.Contains(t, err.Error(), test.expectedText)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// defaultFakeTimeLibrary is libfaketime shared object path in Debian-based images with `faketime` package installed.
+const defaultFakeTimeLibrary = "/usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1"
+
+// fakeTimeCheckScript checks that libfaketime, passed as the first argument, can be preloaded in container.
+// Exits with code 3 if the library is not found and with code 4 if the image uses musl libc.
+const fakeTimeCheckScript = `test -r "$0" || exit 3; ls /lib/ld-musl-* >/dev/null 2>&1 && exit 4; exit 0`
+
+var errFakeTimeUnsupported = errors.New("fake time is not supported by container image")
+
+// fakeTimeLibrary returns libfaketime shared object path in container.
+func fakeTimeLibrary(options *Options) string {
+	if len(options.FakeTimeLibrary) > 0 {
+		return options.FakeTimeLibrary
+	}
+	return defaultFakeTimeLibrary
+}
+
+// applyFakeTime adds environment variables and mounts making the container processes use a faked time
+// to the given options, if [Options.FakeTime] is set. Options slices are copied, so that the original ones
+// are not modified.
+func applyFakeTime(options *Options) {
+	if options.FakeTime == nil {
+		return
+	}
+	library := fakeTimeLibrary(options)
+	env := make([]string, 0, len(options.EnvironmentVariables)+2)
+	env = append(env, options.EnvironmentVariables...)
+	options.EnvironmentVariables = append(env,
+		"LD_PRELOAD="+library,
+		"FAKETIME=@"+options.FakeTime.UTC().Format("2006-01-02 15:04:05"),
+	)
+	if len(options.FakeTimeHostLibrary) > 0 {
+		mounts := make([]Mount, 0, len(options.Mounts)+1)
+		mounts = append(mounts, options.Mounts...)
+		options.Mounts = append(mounts, Mount{Source: options.FakeTimeHostLibrary, Target: library, ReadOnly: true})
+	}
+}
+
+// checkFakeTime checks that libfaketime can be preloaded in the started container, if [Options.FakeTime] is set.
+func (c *container) checkFakeTime(ctx context.Context) error {
+	if c.options.FakeTime == nil {
+		return nil
+	}
+	library := fakeTimeLibrary(&c.options)
+	result, err := ExecWithResult(ctx, c.id, types.ExecConfig{Cmd: []string{"sh", "-c", fakeTimeCheckScript, library}})
+	if err != nil {
+		return errors.Wrapf(errFakeTimeUnsupported, "cannot run shell in container, e.g. distroless image: %v", err)
+	}
+	switch result.ExitCode {
+	case 0:
+		return nil
+	case 3:
+		return errors.Wrapf(errFakeTimeUnsupported, "libfaketime is not found at %s", library)
+	case 4:
+		return errors.Wrap(errFakeTimeUnsupported, "image uses musl libc")
+	default:
+		return errors.Wrapf(
+			errFakeTimeUnsupported,
+			"cannot run shell in container, e.g. distroless image, exit code %d: %s",
+			result.ExitCode, strings.TrimSpace(result.Stderr),
+		)
+	}
+}
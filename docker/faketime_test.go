@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_createContainerFakeTime(t *testing.T) {
+	fakeTime := time.Date(2030, 2, 28, 23, 59, 30, 0, time.FixedZone("CET", 3600))
+	tests := []struct {
+		name           string
+		options        Options
+		expectedEnv    []string
+		expectedMounts []mount.Mount
+	}{
+		{"not_set", Options{EnvironmentVariables: []string{"A=1"}}, []string{"A=1"}, nil},
+		{"image_library", Options{EnvironmentVariables: []string{"A=1"}, FakeTime: &fakeTime}, []string{
+			"A=1",
+			"FAKETIME=@2030-02-28 22:59:30",
+			"LD_PRELOAD=/usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1",
+		}, nil},
+		{"host_library", Options{
+			FakeTime:            &fakeTime,
+			FakeTimeLibrary:     "/opt/faketime/libfaketime.so.1",
+			FakeTimeHostLibrary: "/usr/local/lib/faketime/libfaketime.so.1",
+			Mounts:              []Mount{{Source: "/src", Target: "/data"}},
+		}, []string{
+			"FAKETIME=@2030-02-28 22:59:30",
+			"LD_PRELOAD=/opt/faketime/libfaketime.so.1",
+		}, []mount.Mount{
+			{Type: mount.TypeBind, Source: "/src", Target: "/data"},
+			{
+				Type:     mount.TypeBind,
+				Source:   "/usr/local/lib/faketime/libfaketime.so.1",
+				Target:   "/opt/faketime/libfaketime.so.1",
+				ReadOnly: true,
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			mountsBefore := len(test.options.Mounts)
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedEnv, mockedContainerCreateConfig.Env)
+			require.Equal(t, test.expectedMounts, mockedContainerCreateHostConfig.Mounts)
+			require.Len(t, test.options.Mounts, mountsBefore)
+		})
+	}
+}
+
+func Test_checkFakeTime(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	fakeTime := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		fakeTime      *time.Time
+		exitCode      int
+		expectedError error
+		expectedText  string
+	}{
+		{"not_set", nil, 0, nil, ""},
+		{"supported", &fakeTime, 0, nil, ""},
+		{"library_not_found", &fakeTime, 3, errFakeTimeUnsupported, "libfaketime is not found"},
+		{"musl", &fakeTime, 4, errFakeTimeUnsupported, "musl"},
+		{"distroless", &fakeTime, 127, errFakeTimeUnsupported, "distroless"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(types.ExecConfig) ExecResult { return ExecResult{ExitCode: test.exitCode} }
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, FakeTime: test.fakeTime})
+			c.(*container).id = mockedContainerID
+			err := c.(*container).checkFakeTime(context.Background())
+			require.ErrorIs(t, err, test.expectedError)
+			if err != nil {
+				require.Contains(t, err.Error(), test.expectedText)
+			}
+			if test.fakeTime == nil {
+				require.Empty(t, mockedExecConfigs)
+				return
+			}
+			require.Equal(t, []string{
+				"sh", "-c", fakeTimeCheckScript, "/usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1",
+			}, mockedExecConfigs[0].Cmd)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+// groupPollInterval is the interval between checks of group members readiness in WaitAllReady.
+const groupPollInterval = 500 * time.Millisecond
+
+// Group member readiness states reported by [ContainerGroup.WaitAllReady].
+const (
+	ReadinessReady     = "ready"
+	ReadinessStarting  = "starting"
+	ReadinessUnhealthy = "unhealthy"
+)
+
+var (
+	// ErrGroupNotReady is returned by [ContainerGroup.WaitAllReady] if some of the group members are not ready.
+	ErrGroupNotReady = errors.New("container group is not ready")
+
+	errGroupStart      = errors.New("container group start failure")
+	errGroupStopRemove = errors.New("container group stop and remove failure")
+)
+
+// ContainerGroup holds containers which are started, waited for, and removed together, e.g. services of a test stack.
+type ContainerGroup struct {
+	members []Container
+}
+
+// MemberReadiness holds a container group member readiness status.
+type MemberReadiness struct {
+	// Name holds container name, or the member index in the group, e.g. `#1`, if the name is not known.
+	Name string
+	// State is one of ReadinessReady, ReadinessStarting, or ReadinessUnhealthy.
+	State string
+	// LastProbeOutput holds the last healthcheck probe output of a container which is not ready, if any.
+	LastProbeOutput string
+	// Err holds the error which occurred while checking the container readiness.
+	Err error
+}
+
+// groupMember is implemented by containers created with this package. It allows starting a container without
+// waiting for the service inside it to start.
+type groupMember interface {
+	launch(ctx context.Context) (bool, error)
+}
+
+// NewContainerGroup creates a new [ContainerGroup] object with the given members.
+func NewContainerGroup(members ...Container) *ContainerGroup {
+	return &ContainerGroup{members: members}
+}
+
+// StartAll creates and starts all group members concurrently. Containers created with this package are started
+// without waiting for the services inside them to start, use WaitAllReady for that. Other [Container]
+// implementations are started with CreateStart. Returned error lists all members which have failed to start.
+func (g *ContainerGroup) StartAll(ctx context.Context) error {
+	errs := make([]error, len(g.members))
+	wg := sync.WaitGroup{}
+	for i := range g.members {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			member := g.members[i]
+			launcher, ok := member.(groupMember)
+			if !ok {
+				errs[i] = member.CreateStart(ctx)
+				return
+			}
+			if errs[i] = member.Create(ctx); errs[i] == nil {
+				_, errs[i] = launcher.launch(ctx)
+			}
+		}(i)
+	}
+	wg.Wait()
+	return g.membersError(errGroupStart, errs)
+}
+
+// StopRemoveAll stops and removes all group members concurrently. Returned error lists all members which have
+// failed to stop or be removed.
+func (g *ContainerGroup) StopRemoveAll(ctx context.Context) error {
+	errs := make([]error, len(g.members))
+	wg := sync.WaitGroup{}
+	for i := range g.members {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = g.members[i].StopRemove(ctx)
+		}(i)
+	}
+	wg.Wait()
+	return g.membersError(errGroupStopRemove, errs)
+}
+
+// WaitAllReady waits until the services inside all group members have started or the timeout expires.
+// Members are checked concurrently. A member is ready once [Container.HasStarted] reports it, a member marked
+// unhealthy by Docker daemon or failing the check is not waited for anymore. The returned report holds
+// readiness of each member in the group order. If some members are not ready, [ErrGroupNotReady] listing them
+// is returned together with the report.
+func (g *ContainerGroup) WaitAllReady(ctx context.Context, timeout time.Duration) ([]MemberReadiness, error) {
+	report := make([]MemberReadiness, len(g.members))
+	for i := range report {
+		report[i] = MemberReadiness{Name: g.memberLabel(i), State: ReadinessStarting}
+	}
+	deadline := nowFn().Add(timeout)
+	for {
+		pending := g.checkPending(ctx, report)
+		switch {
+		case pending == 0:
+			return report, groupReadinessError(report)
+		case ctx.Err() != nil:
+			return report, ctx.Err()
+		case !nowFn().Before(deadline):
+			return report, groupReadinessError(report)
+		}
+		sleepFn(groupPollInterval)
+	}
+}
+
+// checkPending concurrently checks readiness of the members which are still starting and updates the report.
+// Returns the number of members which are still starting.
+func (g *ContainerGroup) checkPending(ctx context.Context, report []MemberReadiness) int {
+	wg := sync.WaitGroup{}
+	for i := range g.members {
+		if report[i].State != ReadinessStarting || report[i].Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkMemberReadiness(ctx, g.members[i], &report[i])
+		}(i)
+	}
+	wg.Wait()
+	pending := 0
+	for _, member := range report {
+		if member.State == ReadinessStarting && member.Err == nil {
+			pending++
+		}
+	}
+	return pending
+}
+
+// checkMemberReadiness checks the member readiness and updates its status.
+func checkMemberReadiness(ctx context.Context, member Container, status *MemberReadiness) {
+	started, err := member.HasStarted(ctx)
+	if err != nil {
+		status.Err = err
+		return
+	}
+	data, err := member.Inspect(ctx)
+	if err == nil && data.ContainerJSONBase != nil {
+		if len(data.Name) > 0 {
+			status.Name = strings.TrimPrefix(data.Name, "/")
+		}
+		if !started && data.State != nil && data.State.Health != nil {
+			health := data.State.Health
+			if len(health.Log) > 0 {
+				status.LastProbeOutput = strings.TrimSpace(health.Log[len(health.Log)-1].Output)
+			}
+			if health.Status == types.Unhealthy {
+				status.State = ReadinessUnhealthy
+			}
+		}
+	}
+	if started {
+		status.State = ReadinessReady
+		status.LastProbeOutput = ""
+	}
+}
+
+// groupReadinessError returns [ErrGroupNotReady] listing the members which are not ready, or nil if all are ready.
+func groupReadinessError(report []MemberReadiness) error {
+	var notReady []string
+	for _, member := range report {
+		switch {
+		case member.Err != nil:
+			notReady = append(notReady, fmt.Sprintf("%s (%s: %v)", member.Name, member.State, member.Err))
+		case member.State == ReadinessReady:
+		case len(member.LastProbeOutput) > 0:
+			notReady = append(notReady, fmt.Sprintf("%s (%s, last probe output: %q)", member.Name, member.State, member.LastProbeOutput))
+		default:
+			notReady = append(notReady, fmt.Sprintf("%s (%s)", member.Name, member.State))
+		}
+	}
+	if len(notReady) == 0 {
+		return nil
+	}
+	return errors.Wrap(ErrGroupNotReady, strings.Join(notReady, ", "))
+}
+
+// membersError wraps the given error with the list of members which have failed with their errors.
+// Returns nil if no member has failed.
+func (g *ContainerGroup) membersError(err error, errs []error) error {
+	var failed []string
+	for i, memberErr := range errs {
+		if memberErr != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", g.memberLabel(i), memberErr))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Wrap(err, strings.Join(failed, "; "))
+}
+
+// memberLabel returns the member name, if it is known, or its index in the group.
+func (g *ContainerGroup) memberLabel(i int) string {
+	if name := g.members[i].Name(); len(name) > 0 {
+		return name
+	}
+	return fmt.Sprintf("#%d", i)
+}
@@ -0,0 +1,150 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+var errCollectDiagnostics = errors.New("container group diagnostics collection failure")
+
+// CollectDiagnostics writes logs, low-level information, and a state summary of each group member to
+// `<name>.log`, `<name>.inspect.json`, and `<name>.state.txt` files in dir, e.g. to find out which service
+// misbehaved in a failed test. The directory is created if it does not exist. Collection continues past
+// individual errors, the returned error lists all members which have failed.
+func (g *ContainerGroup) CollectDiagnostics(ctx context.Context, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	used := make(map[string]bool, len(g.members))
+	errs := make([]error, len(g.members))
+	for i, member := range g.members {
+		name := diagnosticsFileName(g.memberLabel(i))
+		if used[name] {
+			name = fmt.Sprintf("%s-%d", name, i)
+		}
+		used[name] = true
+		errs[i] = collectMemberDiagnostics(ctx, member, filepath.Join(dir, name))
+	}
+	return g.membersError(errCollectDiagnostics, errs)
+}
+
+// CollectDiagnosticsOnFailure makes the group diagnostics be collected into dir with CollectDiagnostics when
+// the given test finishes, if it has failed. If dir is empty, a new temporary directory is created. The directory
+// path and collection errors are written to the test log.
+func (g *ContainerGroup) CollectDiagnosticsOnFailure(tb testing.TB, dir string) {
+	tb.Cleanup(func() {
+		if !tb.Failed() {
+			return
+		}
+		if len(dir) == 0 {
+			var err error
+			if dir, err = os.MkdirTemp("", "testutils-diagnostics-"); err != nil {
+				tb.Logf("container group diagnostics are not collected: %v", err)
+				return
+			}
+		}
+		if err := g.CollectDiagnostics(context.Background(), dir); err != nil {
+			tb.Logf("container group diagnostics are collected partially into %s: %v", dir, err)
+			return
+		}
+		tb.Logf("container group diagnostics are collected into %s", dir)
+	})
+}
+
+// diagnosticsFileName returns the given member label with characters unsafe in file names replaced with `_`,
+// e.g. `#1` is replaced with `_1`. Leading dots are replaced as well, so that names like `..` stay in the directory.
+func diagnosticsFileName(label string) string {
+	name := unsafeNameChars.ReplaceAllString(label, "_")
+	if trimmed := strings.TrimLeft(name, "."); len(trimmed) < len(name) {
+		name = strings.Repeat("_", len(name)-len(trimmed)) + trimmed
+	}
+	if len(name) == 0 {
+		return "_"
+	}
+	return name
+}
+
+// collectMemberDiagnostics writes the member diagnostics files with the given path prefix. Returns the errors
+// which have occurred, continuing past them.
+func collectMemberDiagnostics(ctx context.Context, member Container, prefix string) error {
+	var failed []string
+	fail := func(what string, err error) {
+		failed = append(failed, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if logs, err := member.Logs(ctx); err != nil {
+		fail("logs", err)
+	} else if err = os.WriteFile(prefix+".log", []byte(logs), 0o644); err != nil {
+		fail("logs", err)
+	}
+
+	data, err := member.Inspect(ctx)
+	if err != nil {
+		fail("inspect", err)
+	} else {
+		if err = writeJSONFile(prefix+".inspect.json", data); err != nil {
+			fail("inspect", err)
+		}
+		if err = os.WriteFile(prefix+".state.txt", []byte(memberState(ctx, member, data)), 0o644); err != nil {
+			fail("state", err)
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(failed, ", "))
+}
+
+// writeJSONFile writes the given value indented JSON encoding to the file at path.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// memberState returns a human-readable summary of the member state: the test which has created the container,
+// status, exit code, health, and processes running in the container.
+func memberState(ctx context.Context, member Container, data types.ContainerJSON) string {
+	b := strings.Builder{}
+	if data.ContainerJSONBase == nil || data.State == nil {
+		return "state is not known\n"
+	}
+	state := data.State
+	if data.Config != nil && len(data.Config.Labels[labelTest]) > 0 {
+		fmt.Fprintf(&b, "test: %s\n", data.Config.Labels[labelTest])
+	}
+	fmt.Fprintf(&b, "status: %s\nexit code: %d\nOOM killed: %t\nrestart count: %d\n",
+		state.Status, state.ExitCode, state.OOMKilled, data.RestartCount)
+	if len(state.Error) > 0 {
+		fmt.Fprintf(&b, "error: %s\n", state.Error)
+	}
+	if state.Health != nil {
+		fmt.Fprintf(&b, "health: %s\n", state.Health.Status)
+		for _, probe := range state.Health.Log {
+			fmt.Fprintf(&b, "  probe exit code %d: %s\n", probe.ExitCode, strings.TrimSpace(probe.Output))
+		}
+	}
+	if state.Running {
+		processes, err := member.Top(ctx, "")
+		if err != nil {
+			fmt.Fprintf(&b, "processes: %v\n", err)
+		} else {
+			b.WriteString("processes:\n")
+			for _, process := range processes {
+				fmt.Fprintf(&b, "  %s\n", strings.Join(process, " "))
+			}
+		}
+	}
+	return b.String()
+}
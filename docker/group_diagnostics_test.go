@@ -0,0 +1,158 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/require"
+)
+
+// diagnosticsMember is a group member returning mocked diagnostics data.
+type diagnosticsMember struct {
+	Container
+	name                string
+	logs                string
+	logsErr, inspectErr error
+	state               *types.ContainerState
+	labels              map[string]string
+}
+
+func (m *diagnosticsMember) Name() string {
+	return m.name
+}
+
+func (m *diagnosticsMember) Logs(context.Context) (string, error) {
+	return m.logs, m.logsErr
+}
+
+func (m *diagnosticsMember) Inspect(context.Context) (types.ContainerJSON, error) {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{Name: "/" + m.name, State: m.state},
+		Config:            &dockerContainer.Config{Labels: m.labels},
+	}, m.inspectErr
+}
+
+func (m *diagnosticsMember) Top(context.Context, string) ([][]string, error) {
+	return [][]string{{"root", "1", "postgres"}}, nil
+}
+
+func Test_diagnosticsFileName(t *testing.T) {
+	tests := []struct {
+		label, expected string
+	}{
+		{"db", "db"},
+		{"api-1.v2_x", "api-1.v2_x"},
+		{"#1", "_1"},
+		{"../etc/passwd", "___etc_passwd"},
+		{"..", "__"},
+		{"a b/c\\d:e", "a_b_c_d_e"},
+		{"", "_"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.label, func(t *testing.T) {
+			require.Equal(t, test.expected, diagnosticsFileName(test.label))
+		})
+	}
+}
+
+func Test_ContainerGroupCollectDiagnostics(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "diagnostics")
+	running := &types.ContainerState{Status: "running", Running: true, Health: &types.Health{
+		Status: types.Unhealthy,
+		Log:    []*types.HealthcheckResult{{ExitCode: 1, Output: "connection refused\n"}},
+	}}
+	group := NewContainerGroup(
+		&diagnosticsMember{name: "db", logs: "ready\n", state: running, labels: map[string]string{labelTest: "TestOrders"}},
+		&diagnosticsMember{name: "api", logsErr: errors.New("logs unavailable"), state: &types.ContainerState{
+			Status:   "exited",
+			ExitCode: 2,
+		}},
+		&diagnosticsMember{name: "cache", logs: "started\n", inspectErr: errContainerNotFound},
+		&diagnosticsMember{name: "../db", logs: "other\n", state: running},
+		&diagnosticsMember{logs: "unnamed\n", state: running},
+	)
+
+	err := group.CollectDiagnostics(context.Background(), dir)
+	require.ErrorIs(t, err, errCollectDiagnostics)
+	require.ErrorContains(t, err, "api: logs: logs unavailable; cache: inspect: container not found")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	require.Equal(t, []string{
+		"_4.inspect.json", "_4.log", "_4.state.txt",
+		"___db.inspect.json", "___db.log", "___db.state.txt",
+		"api.inspect.json", "api.state.txt",
+		"cache.log",
+		"db.inspect.json", "db.log", "db.state.txt",
+	}, names)
+
+	logs, err := os.ReadFile(filepath.Join(dir, "db.log"))
+	require.NoError(t, err)
+	require.Equal(t, "ready\n", string(logs))
+	var inspect types.ContainerJSON
+	data, err := os.ReadFile(filepath.Join(dir, "db.inspect.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &inspect))
+	require.Equal(t, "/db", inspect.Name)
+	state, err := os.ReadFile(filepath.Join(dir, "db.state.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "test: TestOrders\nstatus: running\nexit code: 0\nOOM killed: false\nrestart count: 0\nhealth: unhealthy\n"+
+		"  probe exit code 1: connection refused\nprocesses:\n  root 1 postgres\n", string(state))
+	state, err = os.ReadFile(filepath.Join(dir, "api.state.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "status: exited\nexit code: 2\nOOM killed: false\nrestart count: 0\n", string(state))
+}
+
+// failingTB records test log messages and cleanup functions of a failed test.
+type failingTB struct {
+	recordingTB
+	failed   bool
+	cleanups []func()
+}
+
+func (tb *failingTB) Failed() bool {
+	return tb.failed
+}
+
+func (tb *failingTB) Cleanup(f func()) {
+	tb.cleanups = append(tb.cleanups, f)
+}
+
+func Test_ContainerGroupCollectDiagnosticsOnFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		failed        bool
+		expectedFiles int
+	}{
+		{"failed", true, 3},
+		{"passed", false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tb := &failingTB{failed: test.failed}
+			group := NewContainerGroup(&diagnosticsMember{name: "db", state: &types.ContainerState{Status: "exited"}})
+			group.CollectDiagnosticsOnFailure(tb, dir)
+			require.Len(t, tb.cleanups, 1)
+			tb.cleanups[0]()
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, test.expectedFiles)
+			require.Len(t, tb.messages, test.expectedFiles/3)
+		})
+	}
+}
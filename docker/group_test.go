@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockedProbeInspect returns mocked container low-level information with the given health status and
+// the last probe output.
+func mockedProbeInspect(name, status, output string) types.ContainerJSON {
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+		ID:   name,
+		Name: "/" + name,
+		State: &types.ContainerState{Health: &types.Health{
+			Status: status,
+			Log:    []*types.HealthcheckResult{{ExitCode: 1, Output: output + "\n"}},
+		}},
+	}}
+}
+
+func Test_ContainerGroupStartAllWaitAllReady(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	group := NewContainerGroup(
+		NewContainerWithOptions(mockedImageName, Options{Name: "api"}),
+		NewDatabaseContainerWithOptions(mockedImageName, Database{}, Options{Name: "db"}),
+	)
+
+	require.NoError(t, group.StartAll(context.Background()))
+	require.ElementsMatch(t, []string{"create api ", "create db ", "start api", "start db"}, mockedDaemonCalls)
+
+	report, err := group.WaitAllReady(context.Background(), time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, []MemberReadiness{{Name: "api", State: ReadinessReady}, {Name: "db", State: ReadinessReady}}, report)
+
+	mockedDaemonCalls = nil
+	require.NoError(t, group.StopRemoveAll(context.Background()))
+	require.ElementsMatch(t, []string{"stop api", "remove api", "stop db", "remove db"}, mockedDaemonCalls)
+}
+
+func Test_ContainerGroupStartAllFailure(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	mockedDaemonStartErrors = map[string]error{"db": errors.New("port is already allocated")}
+	group := NewContainerGroup(
+		NewContainerWithOptions(mockedImageName, Options{Name: "api"}),
+		NewContainerWithOptions(mockedImageName, Options{Name: "db"}),
+	)
+
+	err := group.StartAll(context.Background())
+	require.ErrorIs(t, err, errGroupStart)
+	require.ErrorContains(t, err, "db: port is already allocated")
+	require.NotContains(t, err.Error(), "api")
+	require.Equal(t, containerStateRunning, mockedDaemonContainers["api"])
+}
+
+func Test_ContainerGroupWaitAllReadyMixed(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{
+		"api":   containerStateRunning,
+		"db":    containerStateRunning,
+		"cache": containerStateRunning,
+	}
+	mockedDaemonStatuses = map[string]string{
+		"db":    "Up 10 seconds (health: starting)",
+		"cache": "Up 10 seconds (unhealthy)",
+	}
+	mockedContainerInspectSequences = map[string][]types.ContainerJSON{
+		"db":    {mockedProbeInspect("db", types.Starting, "connection refused")},
+		"cache": {mockedProbeInspect("cache", types.Unhealthy, "PING failed")},
+	}
+	group := NewContainerGroup(
+		NewContainerWithOptions(mockedImageName, Options{Name: "api"}),
+		NewContainerWithOptions(mockedImageName, Options{Name: "db"}),
+		NewContainerWithOptions(mockedImageName, Options{Name: "cache"}),
+		NewContainerWithOptions(mockedImageName, Options{Name: "queue"}),
+	)
+
+	start := nowFn()
+	report, err := group.WaitAllReady(context.Background(), 5*time.Second)
+	require.ErrorIs(t, err, ErrGroupNotReady)
+	require.ErrorContains(t, err, `db (starting, last probe output: "connection refused"), `+
+		`cache (unhealthy, last probe output: "PING failed"), queue (starting: container not found)`)
+	require.Equal(t, []MemberReadiness{
+		{Name: "api", State: ReadinessReady},
+		{Name: "db", State: ReadinessStarting, LastProbeOutput: "connection refused"},
+		{Name: "cache", State: ReadinessUnhealthy, LastProbeOutput: "PING failed"},
+		{Name: "queue", State: ReadinessStarting, Err: errContainerNotFound},
+	}, report)
+	// Only the starting member is waited for until the timeout.
+	require.Equal(t, 5*time.Second, nowFn().Sub(start))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = group.WaitAllReady(ctx, 5*time.Second)
+	require.ErrorIs(t, err, context.Canceled)
+}
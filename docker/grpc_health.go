@@ -0,0 +1,168 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+const (
+	// grpcHealthPollInterval is the interval between health checks in WaitForGRPCHealth.
+	grpcHealthPollInterval = 500 * time.Millisecond
+	// grpcHealthCheckTimeout limits the duration of a single health check.
+	grpcHealthCheckTimeout = 2 * time.Second
+	// grpcHealthCheckPath is the path of grpc.health.v1.Health service Check method.
+	grpcHealthCheckPath = "/grpc.health.v1.Health/Check"
+	// grpcHealthResponseLimit limits the size of health check response read.
+	grpcHealthResponseLimit = 1024
+	// grpcServing is grpc.health.v1.HealthCheckResponse SERVING status.
+	grpcServing = 1
+)
+
+var (
+	errWaitForGRPCHealthTimeout = errors.New("gRPC health wait timeout")
+	errGRPCHealthCheck          = errors.New("gRPC health check failed")
+	errGRPCNotServing           = errors.New("gRPC service is not serving")
+
+	// grpcServingStatuses holds grpc.health.v1.HealthCheckResponse status names.
+	grpcServingStatuses = map[uint64]string{0: "UNKNOWN", 1: "SERVING", 2: "NOT_SERVING", 3: "SERVICE_UNKNOWN"}
+)
+
+// WaitForGRPCHealth repeatedly calls the standard grpc.health.v1 Health service Check method on the host port bound
+// to the given container port until the given service, or the whole server if service is empty, reports SERVING
+// status or the timeout expires. Plaintext connections are used unless [Options.GRPCTLSConfig] is set. Timeout error
+// includes the last check error.
+func (c *container) WaitForGRPCHealth(ctx context.Context, containerPort string, service string, timeout time.Duration) error {
+	if err := c.resolveID(ctx); err != nil {
+		return err
+	}
+	endpoint, release := c.probeEndpoint(containerPort)
+	defer release()
+	return poll(ctx, timeout, grpcHealthPollInterval, errWaitForGRPCHealthTimeout, func(ctx context.Context) error {
+		address, err := endpoint(ctx)
+		if err != nil {
+			return err
+		}
+		return checkGRPCHealth(ctx, address, service, c.options.GRPCTLSConfig)
+	})
+}
+
+// checkGRPCHealth calls Health service Check method of the gRPC server at the given address. Returns nil if
+// the service is serving. The request is sent over HTTP/2 with TLS if tlsConfig is set, and in plaintext otherwise.
+func checkGRPCHealth(ctx context.Context, address, service string, tlsConfig *tls.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, grpcHealthCheckTimeout)
+	defer cancel()
+	transport := &http2.Transport{TLSClientConfig: tlsConfig}
+	scheme := "https"
+	if tlsConfig == nil {
+		scheme = "http"
+		transport.AllowHTTP = true
+		transport.DialTLS = func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.DialTimeout(network, addr, readyDialTimeout)
+		}
+	}
+	defer transport.CloseIdleConnections()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, scheme+"://"+address+grpcHealthCheckPath,
+		bytes.NewReader(grpcFrame(grpcHealthCheckRequest(service))))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrapf(errGRPCHealthCheck, "HTTP status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, grpcHealthResponseLimit))
+	if err != nil {
+		return err
+	}
+	// Errors are reported in headers of trailers-only responses and in trailers otherwise.
+	code, message := resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	if len(code) == 0 {
+		code, message = resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	}
+	if code != "0" {
+		return errors.Wrapf(errGRPCHealthCheck, "status code %s: %s", code, message)
+	}
+	status, err := grpcHealthCheckStatus(body)
+	if err != nil {
+		return err
+	}
+	if status != grpcServing {
+		name, known := grpcServingStatuses[status]
+		if !known {
+			name = fmt.Sprintf("status %d", status)
+		}
+		return errors.Wrap(errGRPCNotServing, name)
+	}
+	return nil
+}
+
+// grpcHealthCheckRequest returns protobuf encoding of grpc.health.v1.HealthCheckRequest message with the given
+// service name.
+func grpcHealthCheckRequest(service string) []byte {
+	if len(service) == 0 {
+		return nil
+	}
+	message := []byte{0x0a} // field 1, length-delimited
+	message = binary.AppendUvarint(message, uint64(len(service)))
+	return append(message, service...)
+}
+
+// grpcFrame returns the given message prefixed with gRPC length-prefixed message header of an uncompressed message.
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// grpcHealthCheckStatus decodes the status field of grpc.health.v1.HealthCheckResponse message in the given
+// gRPC response body. A missing field means UNKNOWN status.
+func grpcHealthCheckStatus(body []byte) (uint64, error) {
+	if len(body) < 5 || body[0] != 0 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return 0, errors.Wrap(errGRPCHealthCheck, "malformed response message")
+	}
+	message := body[5:]
+	var status uint64
+	for len(message) > 0 {
+		tag, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.Wrap(errGRPCHealthCheck, "malformed response message")
+		}
+		message = message[n:]
+		value, n := binary.Uvarint(message)
+		if n <= 0 {
+			return 0, errors.Wrap(errGRPCHealthCheck, "malformed response message")
+		}
+		message = message[n:]
+		switch tag & 7 {
+		case 0: // varint
+			if tag>>3 == 1 {
+				status = value
+			}
+		case 2: // length-delimited, value holds the length
+			if value > uint64(len(message)) {
+				return 0, errors.Wrap(errGRPCHealthCheck, "malformed response message")
+			}
+			message = message[value:]
+		default:
+			return 0, errors.Wrap(errGRPCHealthCheck, "unsupported response message field")
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// grpcHealthServer is an in-process grpc.health.v1 Health service serving statuses by service name. Statuses of
+// unknown services are reported with NOT_FOUND gRPC status code.
+type grpcHealthServer struct {
+	statuses map[string]uint64
+	services []string
+}
+
+func (s *grpcHealthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var service string
+	if len(body) > 7 {
+		service = string(body[7:]) // frame header, field tag, and one byte length
+	}
+	s.services = append(s.services, service)
+	w.Header().Set("Content-Type", "application/grpc")
+	status, known := s.statuses[service]
+	if !known {
+		w.Header().Set("Grpc-Status", "5")
+		w.Header().Set("Grpc-Message", "unknown service")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	w.Header().Set("Trailer", "Grpc-Status")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(grpcFrame(binary.AppendUvarint([]byte{0x08}, status)))
+	w.Header().Set("Grpc-Status", "0")
+}
+
+// mockGRPCServerPort makes the mocked container port 50051 bound to the port of the given server address.
+func mockGRPCServerPort(t *testing.T, address string) {
+	_, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"50051/tcp": []nat.PortBinding{{HostIP: "127.0.0.1", HostPort: port}},
+		}}},
+	}
+}
+
+func Test_WaitForGRPCHealth(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name          string
+		service       string
+		statuses      map[string]uint64
+		expectedError error
+		expectedLast  string
+	}{
+		{"server_serving", "", map[string]uint64{"": 1}, nil, ""},
+		{"service_serving", "orders.v1.Orders", map[string]uint64{"": 2, "orders.v1.Orders": 1}, nil, ""},
+		{"not_serving", "", map[string]uint64{"": 2}, errWaitForGRPCHealthTimeout, "NOT_SERVING"},
+		{"unknown_service", "billing", map[string]uint64{"": 1}, errWaitForGRPCHealthTimeout, "status code 5: unknown service"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer useFakeClock()()
+			resetMocks()
+			health := &grpcHealthServer{statuses: test.statuses}
+			server := httptest.NewServer(h2c.NewHandler(health, &http2.Server{}))
+			defer server.Close()
+			mockGRPCServerPort(t, server.Listener.Addr().String())
+
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			err := c.WaitForGRPCHealth(context.Background(), "50051", test.service, 2*grpcHealthPollInterval)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				require.ErrorContains(t, err, test.expectedLast)
+				require.Len(t, health.services, 3)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, health.services, 1)
+			}
+			require.Equal(t, test.service, health.services[0])
+		})
+	}
+}
+
+func Test_WaitForGRPCHealthTLS(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	server := httptest.NewUnstartedServer(&grpcHealthServer{statuses: map[string]uint64{"": 1}})
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	mockGRPCServerPort(t, server.Listener.Addr().String())
+
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	tlsConfig.ServerName = "example.com"
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, GRPCTLSConfig: tlsConfig})
+	require.NoError(t, c.WaitForGRPCHealth(context.Background(), "50051", "", grpcHealthPollInterval))
+
+	// Checks fail if the server certificate is not trusted.
+	c = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, GRPCTLSConfig: &tls.Config{}})
+	require.Error(t, c.WaitForGRPCHealth(context.Background(), "50051", "", 0))
+}
+
+func Test_grpcHealthCheckStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           []byte
+		expectedStatus uint64
+		expectedError  error
+	}{
+		{"serving", grpcFrame([]byte{0x08, 0x01}), 1, nil},
+		{"missing_status", grpcFrame(nil), 0, nil},
+		{"unknown_fields", grpcFrame([]byte{0x12, 0x02, 'o', 'k', 0x08, 0x02}), 2, nil},
+		{"short", []byte{0, 0, 0}, 0, errGRPCHealthCheck},
+		{"length_mismatch", []byte{0, 0, 0, 0, 5, 0x08}, 0, errGRPCHealthCheck},
+		{"truncated_field", grpcFrame([]byte{0x12, 0x05, 'o'}), 0, errGRPCHealthCheck},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := grpcHealthCheckStatus(test.body)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedStatus, status)
+		})
+	}
+}
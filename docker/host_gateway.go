@@ -0,0 +1,71 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// desktopHostGateway is the host name resolving to the host in Docker Desktop containers.
+	desktopHostGateway = "host.docker.internal"
+	// rootlessHostGateway is the host address in containers of rootless Docker daemons using slirp4netns network.
+	rootlessHostGateway = "10.0.2.2"
+	// defaultBridgeGateway is the default bridge network gateway address used if the network cannot be inspected.
+	defaultBridgeGateway = "172.17.0.1"
+	// defaultBridgeNetwork is the name of Docker default bridge network.
+	defaultBridgeNetwork = "bridge"
+)
+
+// hostGateway detects Docker daemon environment and returns the address at which the host is reachable from
+// containers: `host.docker.internal` for Docker Desktop and non-Linux daemons, the slirp4netns host address for
+// rootless daemons, and the default bridge network gateway for native Linux daemons.
+func (c *defaultClient) hostGateway(ctx context.Context) (string, error) {
+	info, err := c.handler.Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	// The daemon operating system is checked rather than the client one, e.g. a macOS client may use a remote daemon
+	// running on native Linux.
+	if (len(info.OSType) > 0 && info.OSType != "linux") || strings.Contains(info.OperatingSystem, "Docker Desktop") {
+		return desktopHostGateway, nil
+	}
+	for _, option := range info.SecurityOptions {
+		if strings.Contains(option, "name=rootless") {
+			return rootlessHostGateway, nil
+		}
+	}
+	bridge, err := c.handler.NetworkInspect(ctx, defaultBridgeNetwork, types.NetworkInspectOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, config := range bridge.IPAM.Config {
+		if len(config.Gateway) > 0 {
+			return config.Gateway, nil
+		}
+	}
+	return defaultBridgeGateway, nil
+}
+
+// hostGatewayExtraHost returns the ExtraHosts entry making `host.docker.internal` resolve to the host gateway
+// address, or an empty string if the name is resolved by Docker Desktop itself.
+func (c *defaultClient) hostGatewayExtraHost(ctx context.Context) (string, error) {
+	address, err := c.hostGateway(ctx)
+	if err != nil || address == desktopHostGateway {
+		return "", err
+	}
+	return desktopHostGateway + ":" + address, nil
+}
+
+// HostGatewayAddress returns the address at which the host, e.g. a server started by the test process, is reachable
+// from containers: `host.docker.internal` on Docker Desktop, the slirp4netns host address `10.0.2.2` for rootless
+// Docker daemons, and the default bridge network gateway IP address on native Linux.
+func HostGatewayAddress(ctx context.Context) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	return c.hostGateway(ctx)
+}
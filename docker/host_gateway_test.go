@@ -0,0 +1,86 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostGatewayAddress(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer func() { goos = "linux" }()
+	bridge := types.NetworkResource{Name: "bridge", IPAM: network.IPAM{Config: []network.IPAMConfig{
+		{Subnet: "fd00::/64"},
+		{Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"},
+	}}}
+	tests := []struct {
+		name     string
+		goos     string
+		info     types.Info
+		bridge   types.NetworkResource
+		expected string
+	}{
+		{"desktop_mac", "darwin", types.Info{OperatingSystem: "Docker Desktop", OSType: "linux"}, bridge, "host.docker.internal"},
+		{"desktop_windows", "windows", types.Info{OperatingSystem: "Docker Desktop", OSType: "linux"}, bridge, "host.docker.internal"},
+		{"desktop_linux", "linux", types.Info{OperatingSystem: "Docker Desktop", OSType: "linux"}, bridge, "host.docker.internal"},
+		{"windows_daemon", "windows", types.Info{OperatingSystem: "Windows Server 2022", OSType: "windows"}, bridge,
+			"host.docker.internal"},
+		{"native_linux", "linux", types.Info{OperatingSystem: "Ubuntu 22.04.3 LTS", OSType: "linux"}, bridge, "172.18.0.1"},
+		// A macOS client using a remote daemon, e.g. over ssh.
+		{"remote_native_linux", "darwin", types.Info{OperatingSystem: "Ubuntu 22.04.3 LTS", OSType: "linux"}, bridge, "172.18.0.1"},
+		{"native_linux_no_gateway", "linux", types.Info{OperatingSystem: "Debian GNU/Linux 12"}, types.NetworkResource{},
+			"172.17.0.1"},
+		{"rootless", "linux", types.Info{
+			OperatingSystem: "Fedora Linux 39",
+			SecurityOptions: []string{"name=seccomp,profile=builtin", "name=rootless", "name=cgroupns"},
+		}, bridge, "10.0.2.2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			goos = test.goos
+			mockedDaemonInfo = test.info
+			mockedBridgeNetwork = test.bridge
+			address, err := HostGatewayAddress(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, test.expected, address)
+		})
+	}
+}
+
+func Test_createContainerAddHostGatewayAlias(t *testing.T) {
+	tests := []struct {
+		name          string
+		info          types.Info
+		options       Options
+		expectedHosts []string
+		expectedError error
+	}{
+		{"not_set", types.Info{}, Options{ExtraHosts: []string{"db:10.0.0.2"}}, []string{"db:10.0.0.2"}, nil},
+		{"native_linux", types.Info{}, Options{AddHostGatewayAlias: true, ExtraHosts: []string{"db:10.0.0.2"}},
+			[]string{"db:10.0.0.2", "host.docker.internal:172.17.0.1"}, nil},
+		{"desktop", types.Info{OperatingSystem: "Docker Desktop", OSType: "linux"}, Options{AddHostGatewayAlias: true}, nil, nil},
+		{"host_gateway_entry", types.Info{}, Options{AddHostGatewayAlias: true, EnableHostGateway: true}, nil, errHostGatewayConflict},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedDaemonInfo = test.info
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			extraHosts := append([]string{}, test.options.ExtraHosts...)
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError != nil {
+				require.Nil(t, mockedContainerCreateConfig)
+				return
+			}
+			require.Equal(t, test.expectedHosts, mockedContainerCreateHostConfig.ExtraHosts)
+			require.Equal(t, extraHosts, append([]string{}, test.options.ExtraHosts...))
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultHostWaitTimeout is the time to wait for a host dependency if [HostWait.Timeout] is not set.
+	defaultHostWaitTimeout = 30 * time.Second
+	// hostWaitPollInterval is the interval between host dependency checks.
+	hostWaitPollInterval = 500 * time.Millisecond
+)
+
+var (
+	errHostDependencyNotReady = errors.New("host dependency is not ready")
+	errInvalidHostWait        = errors.New("either TCPAddr or URL must be set in host wait")
+	errUnexpectedStatus       = errors.New("unexpected HTTP status")
+)
+
+// HostWait describes a service on host the container depends on, e.g. a mock server the container calls
+// at startup. Either TCPAddr or URL must be set.
+type HostWait struct {
+	// TCPAddr is an address which must accept TCP connections, e.g. `localhost:8080`.
+	TCPAddr string
+	// URL is an HTTP address which must respond with Status to GET requests.
+	URL string
+	// Status is the expected HTTP response status code. Zero value means any 2xx status.
+	Status int
+	// Timeout is the maximum time to wait for the dependency. Zero value means 30 seconds.
+	Timeout time.Duration
+}
+
+// String returns the dependency description used in errors, e.g. `tcp localhost:8080`.
+func (w HostWait) String() string {
+	if len(w.URL) > 0 {
+		return "http " + w.URL
+	}
+	return "tcp " + w.TCPAddr
+}
+
+// check checks the dependency once.
+func (w HostWait) check(ctx context.Context) error {
+	switch {
+	case len(w.URL) > 0:
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, w.URL, nil)
+		if err != nil {
+			return err
+		}
+		response, err := (&http.Client{Timeout: readyDialTimeout}).Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if (w.Status == 0 && response.StatusCode/100 != 2) || (w.Status != 0 && response.StatusCode != w.Status) {
+			return errors.Wrap(errUnexpectedStatus, fmt.Sprint(response.StatusCode))
+		}
+		return nil
+	case len(w.TCPAddr) > 0:
+		conn, err := net.DialTimeout("tcp", w.TCPAddr, readyDialTimeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	return errInvalidHostWait
+}
+
+// waitHostDependencies waits until all the given host dependencies are available. Returned error names the first
+// unmet dependency.
+func waitHostDependencies(ctx context.Context, waits []HostWait) error {
+	for _, wait := range waits {
+		if len(wait.URL) == 0 && len(wait.TCPAddr) == 0 {
+			return errInvalidHostWait
+		}
+		timeout := wait.Timeout
+		if timeout == 0 {
+			timeout = defaultHostWaitTimeout
+		}
+		if err := poll(ctx, timeout, hostWaitPollInterval, errHostDependencyNotReady, wait.check); err != nil {
+			return errors.Wrap(err, wait.String())
+		}
+	}
+	return nil
+}
+
+// stopPolling wraps a probe error which makes poll return the error at once, e.g. a daemon error or a state
+// which the awaited one cannot follow.
+type stopPolling struct {
+	err error
+}
+
+// Error returns the wrapped error message.
+func (e *stopPolling) Error() string {
+	return e.err.Error()
+}
+
+// poll repeatedly calls probe until it succeeds, the context is canceled, or the timeout expires. On timeout,
+// timeoutErr is returned with the last probe error. Probe errors wrapped in [stopPolling] are returned at once.
+func poll(
+	ctx context.Context,
+	timeout, interval time.Duration,
+	timeoutErr error,
+	probe func(ctx context.Context) error,
+) error {
+	deadline := nowFn().Add(timeout)
+	for {
+		err := probe(ctx)
+		var stop *stopPolling
+		switch {
+		case err == nil:
+			return nil
+		case errors.As(err, &stop):
+			return stop.err
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !nowFn().Before(deadline):
+			return errors.Wrapf(timeoutErr, "last error: %v", err)
+		}
+		sleepFn(interval)
+	}
+}
@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_waitHostDependencies(t *testing.T) {
+	defer useFakeClock()()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		waits          []HostWait
+		expectedError  error
+		expectedDetail string
+	}{
+		{"none", nil, nil, ""},
+		{"tcp", []HostWait{{TCPAddr: listener.Addr().String()}}, nil, ""},
+		{"http", []HostWait{{URL: server.URL}}, nil, ""},
+		{"http_status", []HostWait{{URL: server.URL + "/missing", Status: http.StatusNotFound}}, nil, ""},
+		{
+			"tcp_unmet",
+			[]HostWait{{URL: server.URL}, {TCPAddr: closedAddr, Timeout: time.Second}},
+			errHostDependencyNotReady,
+			"tcp " + closedAddr + ": last error: ",
+		},
+		{
+			"http_unexpected_status",
+			[]HostWait{{URL: server.URL + "/missing"}},
+			errHostDependencyNotReady,
+			"http " + server.URL + "/missing: last error: 404: unexpected HTTP status",
+		},
+		{"invalid", []HostWait{{Status: http.StatusOK}}, errInvalidHostWait, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := waitHostDependencies(context.Background(), test.waits)
+			require.ErrorIs(t, err, test.expectedError)
+			if len(test.expectedDetail) > 0 {
+				require.Contains(t, err.Error(), test.expectedDetail)
+			}
+		})
+	}
+}
+
+func Test_createContainerPreStartWait(t *testing.T) {
+	defer useFakeClock()()
+	resetMocks()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	start := nowFn()
+	_, err = c.createContainer(context.Background(), mockedImageName, &Options{
+		PreStartWait: []HostWait{{TCPAddr: closed.Addr().String(), Timeout: 5 * time.Second}},
+	})
+	require.ErrorIs(t, err, errHostDependencyNotReady)
+	require.Equal(t, 5*time.Second, nowFn().Sub(start))
+	require.Nil(t, mockedContainerCreateConfig)
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/pkg/errors"
+)
+
+// emptyRegistryAuth is a base64-encoded empty JSON object, used as registry credentials for registries
+// which do not require authentication.
+const emptyRegistryAuth = "e30="
+
+var errInvalidImageReference = errors.New("invalid image reference")
+
+// tagImage calls Docker client ImageTag method.
+func (c *defaultClient) tagImage(ctx context.Context, source, target string) error {
+	return c.handler.ImageTag(ctx, source, target)
+}
+
+// pushImage calls Docker client ImagePush method and waits for push completion.
+// Returns an error if the push progress stream reports one.
+func (c *defaultClient) pushImage(ctx context.Context, ref string) error {
+	reader, err := c.handler.ImagePush(ctx, ref, types.ImagePushOptions{RegistryAuth: emptyRegistryAuth})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return jsonmessage.DisplayJSONMessagesStream(reader, io.Discard, 0, false, nil)
+}
+
+// commitContainer calls Docker client ContainerCommit method. Returns the created image id.
+func (c *defaultClient) commitContainer(ctx context.Context, id string) (string, error) {
+	response, err := c.handler.ContainerCommit(ctx, id, types.ContainerCommitOptions{Pause: true})
+	if err != nil {
+		return "", err
+	}
+	return response.ID, nil
+}
+
+// CommitContainer creates a new Docker image from the current state of the given container, pausing it for
+// the duration of the commit. Returns the created image id.
+func CommitContainer(ctx context.Context, id string) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	return c.commitContainer(ctx, id)
+}
+
+// TagImage creates target tag referring to source Docker image.
+func TagImage(ctx context.Context, source, target string) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.tagImage(ctx, source, target)
+}
+
+// PushImage pushes Docker image identified by the given reference, e.g. `localhost:5000/alpine:3.17`,
+// to the registry specified in the reference. Registries requiring authentication are not supported.
+func PushImage(ctx context.Context, ref string) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.pushImage(ctx, ref)
+}
+
+// RegistryImageRef returns the given image reference in the registry at the given address,
+// e.g. `localhost:5000/library/alpine:3.17` for `alpine:3.17` image and `localhost:5000` registry address.
+func RegistryImageRef(registryAddress, image string) (string, error) {
+	parsed, err := parseImageRef(image)
+	if err != nil {
+		return "", err
+	}
+	ref := registryAddress + "/" + parsed.path
+	if len(parsed.tag) > 0 {
+		ref += ":" + parsed.tag
+	}
+	return ref, nil
+}
+
+// LocalRegistryAddress returns the address of the given registry container which can be used in image references
+// pushed to and pulled from it, e.g. `localhost:5000`. Docker daemon pushes images to `localhost` registries
+// over plain HTTP, so the address always refers to `localhost` and the host port bound to the given container port.
+// As registry ports are published on Docker daemon host, the address is valid for remote Docker daemons as well.
+func LocalRegistryAddress(ctx context.Context, registry Container, containerPort string) (string, error) {
+	port, err := registry.MappedPort(ctx, containerPort)
+	if err != nil {
+		return "", err
+	}
+	return "localhost:" + port, nil
+}
+
+// PushToRegistry tags the given local Docker image into the registry at the given address and pushes it.
+// Returns the pushed image reference, which can be pulled back using [PullImage].
+func PushToRegistry(ctx context.Context, image, registryAddress string) (string, error) {
+	ref, err := RegistryImageRef(registryAddress, image)
+	if err != nil {
+		return "", err
+	}
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	if err = c.tagImage(ctx, image, ref); err != nil {
+		return "", err
+	}
+	if err = c.pushImage(ctx, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
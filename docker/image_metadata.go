@@ -0,0 +1,245 @@
+package docker
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+// ImageInfo holds Docker image metadata which is used to derive container defaults.
+type ImageInfo struct {
+	ID string
+	// ExposedPorts holds sorted ports exposed by the image in "port/protocol" format, e.g. "5432/tcp".
+	ExposedPorts []string
+	// Env holds default image environment variables in "name=value" format.
+	Env        []string
+	Entrypoint []string
+	Cmd        []string
+	// Size holds the image size in bytes and Layers holds the number of image filesystem layers.
+	Size   int64
+	Layers int
+	// Created holds the image creation time, zero if it is not known.
+	Created time.Time
+}
+
+var (
+	errPortNotExposedByImage = errors.New("container port is not exposed by the image")
+	errImageTooLarge         = errors.New("image size exceeds the limit")
+)
+
+// healthcheckPortPatterns match ports referenced in healthcheck commands, e.g. `localhost:8080` or `-p 5432`.
+var healthcheckPortPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1\]):(\d{1,5})\b`),
+	regexp.MustCompile(`(?:^|\s)(?:-p|--port)[\s=]*(\d{1,5})\b`),
+}
+
+// imageMetadata returns metadata of the given Docker image. The image is pulled if it is not present locally.
+// Metadata is cached in the client, so that each image is inspected once until it is pulled again.
+func (c *defaultClient) imageMetadata(ctx context.Context, ref string) (ImageInfo, error) {
+	return c.imageMetadataWithPolicy(ctx, ref, PullMissing)
+}
+
+// imageMetadataWithPolicy returns metadata of the given Docker image. The image is pulled if it is not present
+// locally, unless the pull policy is [PullNever], in which case the not found error is returned.
+func (c *defaultClient) imageMetadataWithPolicy(ctx context.Context, ref string, policy PullPolicy) (ImageInfo, error) {
+	c.imagesMu.Lock()
+	info, found := c.images[ref]
+	c.imagesMu.Unlock()
+	if found {
+		return info, nil
+	}
+
+	data, _, err := c.handler.ImageInspectWithRaw(ctx, mirroredImage(ref))
+	if dockerClient.IsErrNotFound(err) && policy != PullNever {
+		if err = c.pullImage(ctx, ref); err != nil {
+			return ImageInfo{}, err
+		}
+		data, _, err = c.handler.ImageInspectWithRaw(ctx, mirroredImage(ref))
+	}
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	info = imageInfo(data)
+
+	c.imagesMu.Lock()
+	defer c.imagesMu.Unlock()
+	if c.images == nil {
+		c.images = map[string]ImageInfo{}
+	}
+	c.images[ref] = info
+	return info, nil
+}
+
+// forgetImageMetadata removes the given image metadata from the client cache, e.g. after the image is pulled,
+// as the reference may point to another image then.
+func (c *defaultClient) forgetImageMetadata(ref string) {
+	c.imagesMu.Lock()
+	defer c.imagesMu.Unlock()
+	delete(c.images, ref)
+}
+
+// imageInfo converts Docker image low-level information into [ImageInfo].
+func imageInfo(data types.ImageInspect) ImageInfo {
+	info := ImageInfo{ID: data.ID, Size: data.Size, Layers: len(data.RootFS.Layers)}
+	if created, err := time.Parse(time.RFC3339Nano, data.Created); err == nil {
+		info.Created = created
+	}
+	if data.Config == nil {
+		return info
+	}
+	for port := range data.Config.ExposedPorts {
+		info.ExposedPorts = append(info.ExposedPorts, string(port))
+	}
+	sort.Strings(info.ExposedPorts)
+	info.Env = data.Config.Env
+	info.Entrypoint = data.Config.Entrypoint
+	info.Cmd = data.Config.Cmd
+	return info
+}
+
+// applyImageMetadata publishes ports exposed by the image to ephemeral host ports if
+// [Options.PublishAllExposedPorts] is set and warns about healthcheck ports which are not exposed.
+// Image must be present locally. Metadata errors are ignored, unless ports have to be published.
+func (c *defaultClient) applyImageMetadata(
+	ctx context.Context,
+	image string,
+	options *Options,
+	config *dockerContainer.Config,
+	hostConfig *dockerContainer.HostConfig,
+) error {
+	if !options.PublishAllExposedPorts && len(options.Healthcheck) == 0 {
+		return nil
+	}
+	info, err := c.imageMetadataWithPolicy(ctx, image, options.PullPolicy)
+	if err != nil {
+		if options.PublishAllExposedPorts {
+			return err
+		}
+		return nil
+	}
+	if options.PublishAllExposedPorts {
+		daemonOS, err := c.daemonOS(ctx)
+		if err != nil {
+			return err
+		}
+		publishExposedPorts(info, config, hostConfig, bindingHostIP(daemonOS))
+	}
+	warnUnexposedHealthcheckPorts(image, options.Healthcheck, info, config)
+	return nil
+}
+
+// checkImage calls [Options.ImageCheck] function, if set, with the image metadata. Image must be present locally.
+func (c *defaultClient) checkImage(ctx context.Context, image string, options *Options) error {
+	check := options.ImageCheck
+	if check == nil {
+		return nil
+	}
+	info, err := c.imageMetadataWithPolicy(ctx, image, options.PullPolicy)
+	if err != nil {
+		return err
+	}
+	if err = check(ctx, info); err != nil {
+		return errors.Wrapf(err, "image %s check", image)
+	}
+	return nil
+}
+
+// MaxImageSize returns an [Options.ImageCheck] function failing if the image is larger than the given number of
+// bytes, e.g. to keep test images from blowing up CI cache.
+func MaxImageSize(bytes int64) func(ctx context.Context, info ImageInfo) error {
+	return func(_ context.Context, info ImageInfo) error {
+		if info.Size > bytes {
+			return errors.Wrapf(errImageTooLarge, "image size is %d bytes, limit is %d bytes", info.Size, bytes)
+		}
+		return nil
+	}
+}
+
+// publishExposedPorts binds ports exposed by the image, which are not bound yet, to ephemeral ports of the given
+// host IP, the same way as `docker run -P` does. Both tcp and udp ports are published.
+func publishExposedPorts(info ImageInfo, config *dockerContainer.Config, hostConfig *dockerContainer.HostConfig, hostIP string) {
+	for _, exposedPort := range info.ExposedPorts {
+		port := nat.Port(exposedPort)
+		if _, bound := hostConfig.PortBindings[port]; bound {
+			continue
+		}
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = nat.PortSet{}
+		}
+		if hostConfig.PortBindings == nil {
+			hostConfig.PortBindings = nat.PortMap{}
+		}
+		config.ExposedPorts[port] = struct{}{}
+		hostConfig.PortBindings[port] = []nat.PortBinding{{HostIP: hostIP}}
+	}
+}
+
+// warnUnexposedHealthcheckPorts warns about ports referenced in the healthcheck command which are neither exposed
+// by the image nor by the container configuration, as the healthcheck will likely never pass.
+func warnUnexposedHealthcheckPorts(image, healthcheck string, info ImageInfo, config *dockerContainer.Config) {
+	exposed := map[string]bool{}
+	for _, port := range info.ExposedPorts {
+		exposed[nat.Port(port).Port()] = true
+	}
+	for port := range config.ExposedPorts {
+		exposed[port.Port()] = true
+	}
+	for _, pattern := range healthcheckPortPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(healthcheck, -1) {
+			if port := match[1]; !exposed[port] {
+				warnf("healthcheck %q references port %s which is not exposed by %s image", healthcheck, port, image)
+				exposed[port] = true
+			}
+		}
+	}
+}
+
+// ImageMetadata returns Docker image metadata: exposed ports, default environment, entrypoint, and command.
+// The image is pulled if it is not present locally. Metadata is cached until the image is pulled again.
+func ImageMetadata(ctx context.Context, ref string) (ImageInfo, error) {
+	c, err := getClient()
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	defer c.close()
+	return c.imageMetadata(ctx, ref)
+}
+
+// ValidateExposedPorts checks that the container ports set in [Options.ExposedPorts] are exposed by the image,
+// e.g. to catch a port the service in the image does not listen on. The check is not made on container creation,
+// as services may listen on ports not declared in the image, e.g. configured with environment variables.
+// The image is pulled if it is not present locally.
+func (c *container) ValidateExposedPorts(ctx context.Context) error {
+	ports, _, err := containerPorts(c.options.ExposedPorts, "")
+	if err != nil {
+		return err
+	}
+	info, err := ImageMetadata(ctx, c.image)
+	if err != nil {
+		return err
+	}
+	exposed := make(map[string]bool, len(info.ExposedPorts))
+	for _, port := range info.ExposedPorts {
+		exposed[port] = true
+	}
+	var missing []string
+	for port := range ports {
+		if !exposed[string(port)] {
+			missing = append(missing, string(port))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return errors.Wrapf(errPortNotExposedByImage, "%s, image %s exposes: %s",
+		strings.Join(missing, ", "), c.image, strings.Join(info.ExposedPorts, ", "))
+}
@@ -0,0 +1,310 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	dockerClient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+// mockedPostgresImage returns mocked image low-level information of an image exposing the given ports.
+func mockedPostgresImage(ports ...string) types.ImageInspect {
+	exposedPorts := nat.PortSet{}
+	for _, port := range ports {
+		exposedPorts[nat.Port(port)] = struct{}{}
+	}
+	return types.ImageInspect{
+		ID:      "sha256:mockedImageID",
+		Size:    412 << 20,
+		Created: "2023-05-01T10:00:00.123456789Z",
+		RootFS:  types.RootFS{Type: "layers", Layers: []string{"sha256:1", "sha256:2", "sha256:3"}},
+		Config: &dockerContainer.Config{
+			ExposedPorts: exposedPorts,
+			Env:          []string{"PATH=/usr/bin", "PGDATA=/var/lib/postgresql/data"},
+			Entrypoint:   []string{"docker-entrypoint.sh"},
+			Cmd:          []string{"postgres"},
+		},
+	}
+}
+
+func Test_imageMetadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		notFound     int
+		inspect      types.ImageInspect
+		expectedInfo ImageInfo
+	}{
+		{
+			"local_image",
+			0,
+			mockedPostgresImage("5432/tcp", "8008/tcp"),
+			ImageInfo{
+				ID:           "sha256:mockedImageID",
+				ExposedPorts: []string{"5432/tcp", "8008/tcp"},
+				Env:          []string{"PATH=/usr/bin", "PGDATA=/var/lib/postgresql/data"},
+				Entrypoint:   []string{"docker-entrypoint.sh"},
+				Cmd:          []string{"postgres"},
+				Size:         412 << 20,
+				Layers:       3,
+				Created:      time.Date(2023, 5, 1, 10, 0, 0, 123456789, time.UTC),
+			},
+		},
+		{"pulled_image", 1, types.ImageInspect{ID: "sha256:mockedImageID"}, ImageInfo{ID: "sha256:mockedImageID"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImageInspectNotFound = test.notFound
+			mockedImageInspect = test.inspect
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			for i := 0; i < 3; i++ {
+				info, err := c.imageMetadata(context.Background(), mockedImageName)
+				require.NoError(t, err)
+				require.Equal(t, test.expectedInfo, info)
+			}
+			// Image is inspected once, inspection of a missing image is repeated after pull.
+			require.Equal(t, 1+test.notFound, mockedImageInspectCalls)
+		})
+	}
+
+	resetMocks()
+	mockedImageInspectNotFound = 1
+	mockedImagePullError = errInvalidImagePullMock
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	_, err := c.imageMetadata(context.Background(), mockedImageName)
+	require.ErrorIs(t, err, errInvalidImagePullMock)
+
+	// Missing image is not pulled with PullNever policy.
+	resetMocks()
+	mockedImageInspectNotFound = 1
+	c = &defaultClient{handler: &mockedDockerClient{}}
+	_, err = c.imageMetadataWithPolicy(context.Background(), mockedImageName, PullNever)
+	require.True(t, dockerClient.IsErrNotFound(err))
+	require.Equal(t, 0, mockedImagePulls)
+}
+
+func Test_imageMetadataPulledAgain(t *testing.T) {
+	resetMocks()
+	mockedImageInspect = types.ImageInspect{ID: "sha256:oldImageID"}
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	info, err := c.imageMetadata(context.Background(), mockedImageName)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:oldImageID", info.ID)
+
+	// The reference points to a newer image after a pull.
+	mockedImageInspect = types.ImageInspect{ID: "sha256:newImageID"}
+	require.NoError(t, c.pullImage(context.Background(), mockedImageName))
+	info, err = c.imageMetadata(context.Background(), mockedImageName)
+	require.NoError(t, err)
+	require.Equal(t, "sha256:newImageID", info.ID)
+	require.Equal(t, 2, mockedImageInspectCalls)
+}
+
+func Test_createContainerImageCheck(t *testing.T) {
+	var (
+		calls     []string
+		checkInfo ImageInfo
+	)
+	recordingCheck := func(check func(context.Context, ImageInfo) error) func(context.Context, ImageInfo) error {
+		return func(ctx context.Context, info ImageInfo) error {
+			// The image is pulled before the check, the container is created after it.
+			calls = append(calls, fmt.Sprintf("check pulls=%d created=%t", mockedImagePulls, mockedContainerCreateConfig != nil))
+			checkInfo = info
+			return check(ctx, info)
+		}
+	}
+	tests := []struct {
+		name          string
+		check         func(context.Context, ImageInfo) error
+		expectedCalls []string
+		expectedError error
+		expectedText  string
+	}{
+		{"not_set", nil, nil, nil, ""},
+		{"within_budget", recordingCheck(MaxImageSize(500 << 20)), []string{"check pulls=1 created=false"}, nil, ""},
+		{"exact_budget", recordingCheck(MaxImageSize(412 << 20)), []string{"check pulls=1 created=false"}, nil, ""},
+		{
+			"over_budget",
+			recordingCheck(MaxImageSize(100 << 20)),
+			[]string{"check pulls=1 created=false"},
+			errImageTooLarge,
+			"image mockedImageName check: image size is 432013312 bytes, limit is 104857600 bytes",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			calls, checkInfo = nil, ImageInfo{}
+			mockedImageInspect = mockedPostgresImage("5432/tcp")
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &Options{ImageCheck: test.check})
+			require.Equal(t, test.expectedCalls, calls)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+				require.Contains(t, err.Error(), test.expectedText)
+				require.Nil(t, mockedContainerCreateConfig)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, mockedContainerCreateConfig)
+			if test.check != nil {
+				require.Equal(t, int64(412<<20), checkInfo.Size)
+				require.Equal(t, 3, checkInfo.Layers)
+			}
+		})
+	}
+}
+
+func Test_createContainerPublishAllExposedPorts(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          Options
+		imagePorts       []string
+		expectedBindings nat.PortMap
+	}{
+		{
+			"image_ports",
+			Options{PublishAllExposedPorts: true},
+			[]string{"5432/tcp", "8008/tcp"},
+			nat.PortMap{
+				"5432/tcp": {{HostIP: "0.0.0.0"}},
+				"8008/tcp": {{HostIP: "0.0.0.0"}},
+			},
+		},
+		{
+			"explicit_port_kept",
+			Options{PublishAllExposedPorts: true, ExposedPorts: []string{"5433:5432"}},
+			[]string{"5432/tcp", "8008/tcp"},
+			nat.PortMap{
+				"5432/tcp": {{HostIP: "0.0.0.0", HostPort: "5433"}},
+				"8008/tcp": {{HostIP: "0.0.0.0"}},
+			},
+		},
+		{
+			"udp_ports",
+			Options{PublishAllExposedPorts: true, ExposedPorts: []string{"5353:53/udp"}},
+			[]string{"53/udp", "123/udp", "8080/tcp"},
+			nat.PortMap{
+				"53/udp":   {{HostIP: "0.0.0.0", HostPort: "5353"}},
+				"123/udp":  {{HostIP: "0.0.0.0"}},
+				"8080/tcp": {{HostIP: "0.0.0.0"}},
+			},
+		},
+		{
+			"disabled",
+			Options{},
+			[]string{"5432/tcp"},
+			nat.PortMap{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImageInspect = mockedPostgresImage(test.imagePorts...)
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedBindings, mockedContainerCreateHostConfig.PortBindings)
+			for port := range test.expectedBindings {
+				require.Contains(t, mockedContainerCreateConfig.ExposedPorts, port)
+			}
+		})
+	}
+}
+
+func Test_createContainerHealthcheckPortWarning(t *testing.T) {
+	defer SetLogger(nil)
+	tests := []struct {
+		name             string
+		options          Options
+		imagePorts       []string
+		expectedMessages []string
+	}{
+		{
+			"port_exposed_by_image",
+			Options{Healthcheck: "pg_isready -p 5432"},
+			[]string{"5432/tcp"},
+			nil,
+		},
+		{
+			"port_not_exposed",
+			Options{Healthcheck: "pg_isready -p 5433"},
+			[]string{"5432/tcp"},
+			[]string{`WARNING: healthcheck "pg_isready -p 5433" references port 5433 which is not exposed by mockedImageName image`},
+		},
+		{
+			"port_exposed_by_options",
+			Options{Healthcheck: "curl -f http://localhost:8080/health", ExposedPorts: []string{":8080"}},
+			[]string{"5432/tcp"},
+			nil,
+		},
+		{
+			"several_ports",
+			Options{Healthcheck: "curl -f http://localhost:8080/health && redis-cli --port=6379 ping"},
+			nil,
+			[]string{
+				`WARNING: healthcheck "curl -f http://localhost:8080/health && redis-cli --port=6379 ping" ` +
+					`references port 8080 which is not exposed by mockedImageName image`,
+				`WARNING: healthcheck "curl -f http://localhost:8080/health && redis-cli --port=6379 ping" ` +
+					`references port 6379 which is not exposed by mockedImageName image`,
+			},
+		},
+		{
+			"no_ports",
+			Options{Healthcheck: "pg_isready"},
+			nil,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			l := &mockedLogger{}
+			SetLogger(l)
+			mockedImageInspect = mockedPostgresImage(test.imagePorts...)
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMessages, l.messages)
+		})
+	}
+}
+
+func Test_ValidateExposedPorts(t *testing.T) {
+	tests := []struct {
+		name          string
+		ports         []string
+		expectedError error
+		expectedText  string
+	}{
+		{"not_set", nil, nil, ""},
+		{"exposed", []string{"15432:5432", ":8008/tcp"}, nil, ""},
+		{"mismatch", []string{"15432:5433", ":8008", "5353:53/udp"}, errPortNotExposedByImage,
+			"53/udp, 5433/tcp, image mockedImageName exposes: 5432/tcp, 8008/tcp"},
+		{"invalid", []string{"5432"}, errIncorrectPortConfig, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			cli = &defaultClient{handler: &mockedDockerClient{}}
+			mockedImageInspect = mockedPostgresImage("5432/tcp", "8008/tcp")
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, ExposedPorts: test.ports})
+			err := c.ValidateExposedPorts(context.Background())
+			require.ErrorIs(t, err, test.expectedError)
+			if len(test.expectedText) > 0 {
+				require.ErrorContains(t, err, test.expectedText)
+			}
+		})
+	}
+}
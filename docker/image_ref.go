@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"github.com/docker/distribution/reference"
+	"github.com/pkg/errors"
+)
+
+// imageRef holds parts of a Docker image reference. Unlike splitting on ":", parsing distinguishes registry ports
+// from tags, e.g. in `localhost:5000/app:dev`.
+type imageRef struct {
+	// domain holds the registry host with an optional port, e.g. `docker.io` or `localhost:5000`.
+	domain string
+	// path holds the repository path in the registry, e.g. `library/postgres`.
+	path        string
+	tag, digest string
+}
+
+// parseImageRef splits the given image reference into its parts. Short references are normalized,
+// e.g. `postgres` has `docker.io` domain and `library/postgres` path.
+func parseImageRef(image string) (imageRef, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return imageRef{}, errors.Wrap(errInvalidImageReference, err.Error())
+	}
+	ref := imageRef{domain: reference.Domain(named), path: reference.Path(named)}
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref.tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref.digest = digested.Digest().String()
+	}
+	return ref, nil
+}
+
+// name returns the fully qualified repository name, e.g. `docker.io/library/postgres`.
+func (r imageRef) name() string {
+	return r.domain + "/" + r.path
+}
+
+// String returns the fully qualified image reference, e.g. `docker.io/library/postgres:15`.
+func (r imageRef) String() string {
+	ref := r.name()
+	if len(r.tag) > 0 {
+		ref += ":" + r.tag
+	}
+	if len(r.digest) > 0 {
+		ref += "@" + r.digest
+	}
+	return ref
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseImageRef(t *testing.T) {
+	const digest = "sha256:4f9b0bd8e0a1e8d3a4f1b6c1e5b7a7d8c3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8"
+	tests := []struct {
+		name          string
+		image         string
+		expected      imageRef
+		expectedError error
+	}{
+		{"short", "postgres", imageRef{domain: "docker.io", path: "library/postgres"}, nil},
+		{"tag", "postgres:15", imageRef{domain: "docker.io", path: "library/postgres", tag: "15"}, nil},
+		{"registry_port", "localhost:5000/myimage", imageRef{domain: "localhost:5000", path: "myimage"}, nil},
+		{"registry_port_and_tag", "localhost:5000/myimage:dev", imageRef{domain: "localhost:5000", path: "myimage", tag: "dev"}, nil},
+		{"digest", "redis@" + digest, imageRef{domain: "docker.io", path: "library/redis", digest: digest}, nil},
+		{
+			"combined",
+			"registry.corp:8443/team/app:1.2@" + digest,
+			imageRef{domain: "registry.corp:8443", path: "team/app", tag: "1.2", digest: digest},
+			nil,
+		},
+		{"invalid", "Invalid:Image", imageRef{}, errInvalidImageReference},
+		{"missing_tag", "localhost:5000/myimage:", imageRef{}, errInvalidImageReference},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref, err := parseImageRef(test.image)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expected, ref)
+		})
+	}
+}
+
+func Test_imageRefString(t *testing.T) {
+	ref, err := parseImageRef("localhost:5000/myimage:dev")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:5000/myimage", ref.name())
+	require.Equal(t, "localhost:5000/myimage:dev", ref.String())
+}
@@ -0,0 +1,78 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegistryImageRef(t *testing.T) {
+	tests := []struct {
+		name          string
+		image         string
+		expectedRef   string
+		expectedError error
+	}{
+		{"official", "alpine:3.17", "localhost:5000/library/alpine:3.17", nil},
+		{"untagged", "alpine", "localhost:5000/library/alpine", nil},
+		{"namespaced", "ygrebnov/app:v1", "localhost:5000/ygrebnov/app:v1", nil},
+		{"other_registry", "ghcr.io/ygrebnov/app:v1", "localhost:5000/ygrebnov/app:v1", nil},
+		{"invalid", "Alpine:3.17", "", errInvalidImageReference},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ref, err := RegistryImageRef("localhost:5000", test.image)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedRef, ref)
+		})
+	}
+}
+
+func Test_PushToRegistry(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name          string
+		pushStream    string
+		expectedError string
+	}{
+		{"pushed", `{"status":"Pushed"}` + "\n" + `{"status":"latest: digest: sha256:0123 size: 528"}` + "\n", ""},
+		{"push_error", `{"errorDetail":{"message":"connection refused"},"error":"connection refused"}` + "\n", "connection refused"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImagePushStream = test.pushStream
+			ref, err := PushToRegistry(context.Background(), "alpine:3.17", "localhost:5000")
+			if len(test.expectedError) > 0 {
+				require.ErrorContains(t, err, test.expectedError)
+				require.Empty(t, ref)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "localhost:5000/library/alpine:3.17", ref)
+			}
+			require.Equal(t, [][2]string{{"alpine:3.17", "localhost:5000/library/alpine:3.17"}}, mockedImageTags)
+			require.Equal(t, "localhost:5000/library/alpine:3.17", mockedImagePushRef)
+			require.Equal(t, types.ImagePushOptions{RegistryAuth: emptyRegistryAuth}, *mockedImagePushOptions)
+		})
+	}
+}
+
+func Test_LocalRegistryAddress(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5000/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "49153"}},
+		}}},
+	}
+	registry := NewContainerWithOptions("registry:2", Options{Name: mockedContainerName})
+	address, err := LocalRegistryAddress(context.Background(), registry, "5000")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:49153", address)
+}
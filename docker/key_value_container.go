@@ -0,0 +1,115 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeyValueContainer extends [Container] interface with key-value store interaction methods, e.g. for Redis
+// or etcd containers.
+type KeyValueContainer interface {
+	Container
+	Flush(ctx context.Context) error
+	Set(ctx context.Context, key, value string) error
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// KeyValueStore holds key-value store client command templates. Commands are run with the container shell,
+// [Options.Shell]. Besides container template placeholders, e.g. `{{ .Env "REDIS_PASSWORD" }}`, commands may
+// contain `{{ .Key }}` and `{{ .Value }}` placeholders replaced with shell-quoted key and value.
+type KeyValueStore struct {
+	// FlushCommand removes all keys, e.g. `redis-cli FLUSHALL`.
+	FlushCommand string
+	// SetCommand sets the key value, e.g. `redis-cli SET {{ .Key }} {{ .Value }}`.
+	SetCommand string
+	// GetCommand writes the key value to stdout and nothing, or an empty line, if the key is not set,
+	// e.g. `redis-cli --raw GET {{ .Key }}`.
+	GetCommand string
+}
+
+var (
+	// ErrKeyNotFound is returned by [KeyValueContainer.Get] if the key is not set.
+	ErrKeyNotFound = errors.New("key not found")
+
+	errKeyValueCommandNotSet = errors.New("key-value store command is not set")
+	errKeyValueCommandFailed = errors.New("key-value store command failed")
+)
+
+// keyValueContainer holds container and inner key-value store metadata. Implements [KeyValueContainer] interface.
+type keyValueContainer struct {
+	container
+	store KeyValueStore
+}
+
+// keyValueTemplateData is passed to key-value store command templates. Key and Value hold shell-quoted
+// command arguments.
+type keyValueTemplateData struct {
+	templateData
+	Key, Value string
+}
+
+// Flush removes all keys from the store using FlushCommand.
+func (kc *keyValueContainer) Flush(ctx context.Context) error {
+	_, err := kc.execStoreCommand(ctx, "flush", kc.store.FlushCommand, "", "")
+	return err
+}
+
+// Set sets the key value using SetCommand.
+func (kc *keyValueContainer) Set(ctx context.Context, key, value string) error {
+	_, err := kc.execStoreCommand(ctx, "set", kc.store.SetCommand, key, value)
+	return err
+}
+
+// Get returns the key value using GetCommand. [ErrKeyNotFound] is returned if the command output is empty,
+// so empty values are reported as not set.
+func (kc *keyValueContainer) Get(ctx context.Context, key string) (string, error) {
+	output, err := kc.execStoreCommand(ctx, "get", kc.store.GetCommand, key, "")
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSuffix(strings.TrimSuffix(output, "\n"), "\r")
+	if len(value) == 0 {
+		return "", errors.Wrapf(ErrKeyNotFound, "%q", key)
+	}
+	return value, nil
+}
+
+// execStoreCommand renders the named store command with the given key and value, executes it in container,
+// and returns its stdout.
+func (kc *keyValueContainer) execStoreCommand(ctx context.Context, name, command, key, value string) (string, error) {
+	if len(command) == 0 {
+		return "", errors.Wrapf(errKeyValueCommandNotSet, "%q", name)
+	}
+	command, err := kc.renderWith(ctx, command, func(data templateData) any {
+		return keyValueTemplateData{templateData: data, Key: shellQuote(key), Value: shellQuote(value)}
+	})
+	if err != nil {
+		return "", err
+	}
+	result, err := kc.ExecScript(ctx, command)
+	if err != nil {
+		return "", err
+	}
+	if result.ExitCode != 0 {
+		return result.Stdout, errors.Wrapf(errKeyValueCommandFailed, "%q exit code %d: %s", name, result.ExitCode, result.Stderr)
+	}
+	return result.Stdout, nil
+}
+
+// NewKeyValueContainer creates a new [KeyValueContainer] object.
+func NewKeyValueContainer(image string, store KeyValueStore) KeyValueContainer {
+	return NewKeyValueContainerWithOptions(image, store, Options{})
+}
+
+// NewKeyValueContainerWithOptions creates a new [KeyValueContainer] object with optional attributes values specified.
+func NewKeyValueContainerWithOptions(image string, store KeyValueStore, options Options) KeyValueContainer {
+	if options.StartTimeout == 0 {
+		options.StartTimeout = defaultContainerStartTimeout
+	}
+	kvContainer := keyValueContainer{store: store}
+	kvContainer.image = image
+	kvContainer.options = options
+	return &kvContainer
+}
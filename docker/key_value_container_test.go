@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_KeyValueContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	store := KeyValueStore{
+		FlushCommand: "redis-cli FLUSHALL",
+		SetCommand:   `redis-cli -a {{ .Env "REDIS_PASSWORD" }} SET {{ .Key }} {{ .Value }}`,
+		GetCommand:   "redis-cli --raw GET {{ .Key }}",
+	}
+	tests := []struct {
+		name            string
+		call            func(kc KeyValueContainer) (string, error)
+		stdout          string
+		exitCode        int
+		expectedCommand string
+		expectedOutput  string
+		expectedError   error
+	}{
+		{
+			"flush",
+			func(kc KeyValueContainer) (string, error) { return "", kc.Flush(context.Background()) },
+			"OK\n", 0, "redis-cli FLUSHALL", "", nil,
+		},
+		{
+			"set_quoted",
+			func(kc KeyValueContainer) (string, error) {
+				return "", kc.Set(context.Background(), "user:1", "it's $HOME; rm -rf /")
+			},
+			"OK\n", 0, `redis-cli -a secret SET 'user:1' 'it'\''s $HOME; rm -rf /'`, "", nil,
+		},
+		{
+			"get",
+			func(kc KeyValueContainer) (string, error) { return kc.Get(context.Background(), "user:1") },
+			"it's $HOME\n", 0, "redis-cli --raw GET 'user:1'", "it's $HOME", nil,
+		},
+		{
+			"get_missing",
+			func(kc KeyValueContainer) (string, error) { return kc.Get(context.Background(), "user:2") },
+			"\n", 0, "redis-cli --raw GET 'user:2'", "", ErrKeyNotFound,
+		},
+		{
+			"failure",
+			func(kc KeyValueContainer) (string, error) { return "", kc.Flush(context.Background()) },
+			"", 1, "redis-cli FLUSHALL", "", errKeyValueCommandFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = func(config types.ExecConfig) ExecResult {
+				return ExecResult{ExitCode: test.exitCode, Stdout: test.stdout}
+			}
+			mockedContainerInspect = types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID},
+			}
+			kc := NewKeyValueContainerWithOptions(mockedImageName, store, Options{
+				Name:                 mockedContainerName,
+				EnvironmentVariables: []string{"REDIS_PASSWORD=secret"},
+			})
+			output, err := test.call(kc)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedOutput, output)
+			require.Equal(t, []string{"/bin/sh", "-c", test.expectedCommand}, mockedExecConfigs[0].Cmd)
+		})
+	}
+}
+
+func Test_KeyValueContainerCommandNotSet(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	kc := NewKeyValueContainer(mockedImageName, KeyValueStore{FlushCommand: "redis-cli FLUSHALL"})
+	_, err := kc.Get(context.Background(), "key")
+	require.ErrorIs(t, err, errKeyValueCommandNotSet)
+	require.ErrorIs(t, kc.Set(context.Background(), "key", "value"), errKeyValueCommandNotSet)
+	require.Empty(t, mockedExecConfigs)
+}
@@ -0,0 +1,26 @@
+package docker
+
+import (
+	"log"
+	"os"
+)
+
+// Logger defines methods used by the package to report warnings which do not prevent an operation from completing.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// logger points to a Logger used by the package. Warnings are written to stderr by default.
+var logger Logger = log.New(os.Stderr, "testutils: ", log.LstdFlags)
+
+// SetLogger replaces the package logger. Passing nil disables logging.
+func SetLogger(l Logger) {
+	logger = l
+}
+
+// warnf reports a warning using the package logger.
+func warnf(format string, v ...any) {
+	if logger != nil {
+		logger.Printf("WARNING: "+format, v...)
+	}
+}
@@ -0,0 +1,35 @@
+package docker
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockedLogger records logged messages.
+type mockedLogger struct {
+	messages []string
+}
+
+// Printf is a mocked [Logger] method.
+func (l *mockedLogger) Printf(format string, v ...any) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+// useMockedLogger replaces the package logger with a mocked one and returns a function restoring the previous logger.
+func useMockedLogger() (*mockedLogger, func()) {
+	previous, l := logger, &mockedLogger{}
+	SetLogger(l)
+	return l, func() { SetLogger(previous) }
+}
+
+func Test_warnf(t *testing.T) {
+	l, restore := useMockedLogger()
+	defer restore()
+	warnf("value %d", 1)
+	require.Equal(t, []string{"WARNING: value 1"}, l.messages)
+
+	SetLogger(nil)
+	require.NotPanics(t, func() { warnf("value %d", 2) })
+}
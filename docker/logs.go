@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+)
+
+// LogsOptions holds container logs filtering options.
+type LogsOptions struct {
+	// Since limits logs to the lines written after the given time. Zero value means no limit.
+	Since time.Time
+	// Tail limits logs to the given number of the last lines. Zero value means no limit.
+	Tail int
+	// Grep limits logs to the lines matching the given regular expression. It is applied after Since and Tail.
+	Grep *regexp.Regexp
+	// Stdout and Stderr select the output streams. If neither is set, both streams are returned.
+	Stdout, Stderr bool
+}
+
+// dockerLogsOptions returns Docker client logs options applying the daemon-side part of the given options.
+func (o LogsOptions) dockerLogsOptions() types.ContainerLogsOptions {
+	options := types.ContainerLogsOptions{ShowStdout: o.Stdout, ShowStderr: o.Stderr}
+	if !o.Stdout && !o.Stderr {
+		options.ShowStdout, options.ShowStderr = true, true
+	}
+	if !o.Since.IsZero() {
+		options.Since = o.Since.Format(time.RFC3339Nano)
+	}
+	if o.Tail > 0 {
+		options.Tail = strconv.Itoa(o.Tail)
+	}
+	return options
+}
+
+// grep returns the given text lines matching the options regular expression. If it is not set, text is returned as is.
+func (o LogsOptions) grep(text string) string {
+	if o.Grep == nil || len(text) == 0 {
+		return text
+	}
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if o.Grep.MatchString(strings.TrimSuffix(line, "\n")) {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// LogsWithOptions returns container output filtered with the given options.
+func (c *container) LogsWithOptions(ctx context.Context, options LogsOptions) (string, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return "", err
+	}
+	return ContainerLogsWithOptions(ctx, c.id, options)
+}
+
+// ContainerLogsWithOptions returns Docker container output filtered with the given options.
+// Since, Tail, and output streams are applied by Docker daemon, Grep is applied to the fetched lines.
+func ContainerLogsWithOptions(ctx context.Context, id string, options LogsOptions) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	logs, err := c.containerLogs(ctx, id, options.dockerLogsOptions())
+	if err != nil {
+		return "", err
+	}
+	return options.grep(logs), nil
+}
+
+// LogsWithTimestamps returns container stdout and stderr output with each line prefixed with its RFC3339 timestamp.
+func (c *container) LogsWithTimestamps(ctx context.Context) (string, error) {
+	if err := c.resolveID(ctx); err != nil {
+		return "", err
+	}
+	return ContainerLogsWithTimestamps(ctx, c.id)
+}
+
+// ContainerLogsWithTimestamps returns Docker container stdout and stderr output with each line prefixed
+// with its RFC3339 timestamp, e.g. for correlating events across containers.
+func ContainerLogsWithTimestamps(ctx context.Context, id string) (string, error) {
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	return c.containerLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true})
+}
+
+// LogsGzip writes container stdout and stderr output compressed with gzip to dst, e.g. to keep CI artifacts
+// of chatty containers small. Output is streamed without being buffered in memory.
+func (c *container) LogsGzip(ctx context.Context, dst io.Writer) error {
+	if err := c.resolveID(ctx); err != nil {
+		return err
+	}
+	return ContainerLogsGzip(ctx, c.id, dst)
+}
+
+// ContainerLogsGzip writes Docker container stdout and stderr output compressed with gzip to dst.
+// Output is demultiplexed before compression and is streamed without being buffered in memory.
+func ContainerLogsGzip(ctx context.Context, id string, dst io.Writer) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	compressed := gzip.NewWriter(dst)
+	if err = c.streamLogs(ctx, id, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}, compressed); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LogsWithOptions(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	since := time.Date(2023, 1, 1, 10, 0, 0, 500, time.UTC)
+	errorLines := regexp.MustCompile(`(?i)\berror\b`)
+	tests := []struct {
+		name            string
+		options         LogsOptions
+		expectedOptions types.ContainerLogsOptions
+		expectedLogs    string
+	}{
+		{"default", LogsOptions{}, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true},
+			"starting\nERROR: no config\nlistening\nerror: connection reset\nready\n"},
+		{"stdout", LogsOptions{Stdout: true}, types.ContainerLogsOptions{ShowStdout: true},
+			"starting\nlistening\nready\n"},
+		{"stderr", LogsOptions{Stderr: true}, types.ContainerLogsOptions{ShowStderr: true},
+			"ERROR: no config\nerror: connection reset\n"},
+		{"since", LogsOptions{Since: since}, types.ContainerLogsOptions{
+			ShowStdout: true, ShowStderr: true, Since: "2023-01-01T10:00:00.0000005Z",
+		}, "starting\nERROR: no config\nlistening\nerror: connection reset\nready\n"},
+		{"tail", LogsOptions{Tail: 2}, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Tail: "2"},
+			"error: connection reset\nready\n"},
+		{"grep", LogsOptions{Grep: errorLines}, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true},
+			"ERROR: no config\nerror: connection reset\n"},
+		{"tail_grep", LogsOptions{Tail: 3, Grep: errorLines}, types.ContainerLogsOptions{
+			ShowStdout: true, ShowStderr: true, Tail: "3",
+		}, "error: connection reset\n"},
+		{"stdout_grep", LogsOptions{Stdout: true, Grep: errorLines}, types.ContainerLogsOptions{ShowStdout: true}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerLogs = []mockedLogLine{
+				{stdcopy.Stdout, "starting\n"},
+				{stdcopy.Stderr, "ERROR: no config\n"},
+				{stdcopy.Stdout, "listening\n"},
+				{stdcopy.Stderr, "error: connection reset\n"},
+				{stdcopy.Stdout, "ready\n"},
+			}
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			logs, err := c.LogsWithOptions(context.Background(), test.options)
+			require.NoError(t, err)
+			require.Equal(t, test.expectedLogs, logs)
+			require.Equal(t, test.expectedOptions, *mockedContainerLogsOptions)
+		})
+	}
+}
+
+func Test_LogsWithTimestamps(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerLogs = []mockedLogLine{
+		{stdcopy.Stdout, "2023-01-01T10:00:00.000000001Z starting\n"},
+		{stdcopy.Stderr, "2023-01-01T10:00:01.000000002Z warning: low memory\n"},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	logs, err := c.LogsWithTimestamps(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "2023-01-01T10:00:00.000000001Z starting\n2023-01-01T10:00:01.000000002Z warning: low memory\n", logs)
+	require.Equal(t, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true}, *mockedContainerLogsOptions)
+}
+
+func Test_LogsGzip(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	// Large output is not truncated, as it is streamed.
+	SetMaxOutputBytes(16)
+	defer SetMaxOutputBytes(DefaultMaxOutputBytes)
+	spam := strings.Repeat("spam\n", 1000)
+	mockedContainerLogs = []mockedLogLine{
+		{stdcopy.Stdout, "starting\n"},
+		{stdcopy.Stderr, "warning: low memory\n"},
+		{stdcopy.Stdout, spam},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	compressed := bytes.Buffer{}
+	require.NoError(t, c.LogsGzip(context.Background(), &compressed))
+	require.Equal(t, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true}, *mockedContainerLogsOptions)
+
+	size := compressed.Len()
+	reader, err := gzip.NewReader(&compressed)
+	require.NoError(t, err)
+	logs, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "starting\nwarning: low memory\n"+spam, string(logs))
+	require.Less(t, size, len(logs))
+}
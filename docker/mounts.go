@@ -0,0 +1,67 @@
+package docker
+
+import (
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/pkg/errors"
+)
+
+// Mount holds data of a host directory or file bind mounted into container.
+type Mount struct {
+	// Source is an absolute path on host.
+	Source string
+	// Target is an absolute path in container. On Windows hosts, Windows paths, e.g. `C:\data`, are also accepted.
+	Target   string
+	ReadOnly bool
+}
+
+var (
+	errInvalidMountPath = errors.New("mount path must be absolute")
+	errInvalidIsolation = errors.New(`invalid isolation, expected one of: "default", "process", "hyperv"`)
+)
+
+// containerMounts validates the given mounts and converts them into Docker bind mounts.
+func containerMounts(mounts []Mount) ([]mount.Mount, error) {
+	var converted []mount.Mount
+	for _, m := range mounts {
+		if !isAbsPath(m.Source) {
+			return nil, errors.Wrap(errInvalidMountPath, m.Source)
+		}
+		if !isAbsPath(m.Target) {
+			return nil, errors.Wrap(errInvalidMountPath, m.Target)
+		}
+		converted = append(converted, mount.Mount{Type: mount.TypeBind, Source: m.Source, Target: m.Target, ReadOnly: m.ReadOnly})
+	}
+	return converted, nil
+}
+
+// isAbsPath reports whether the given path is absolute. Unix paths are accepted on all hosts,
+// Windows paths, e.g. `C:\data`, `C:/data`, or `\\server\share`, are accepted on Windows hosts only.
+func isAbsPath(p string) bool {
+	if path.IsAbs(p) {
+		return true
+	}
+	if goos != "windows" {
+		return false
+	}
+	if strings.HasPrefix(p, `\\`) {
+		return len(p) > 2
+	}
+	return len(p) >= 3 && isDriveLetter(p[0]) && p[1] == ':' && (p[2] == '\\' || p[2] == '/')
+}
+
+// isDriveLetter reports whether the given character is a Windows drive letter.
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// validateIsolation checks that the given container isolation technology is supported.
+func validateIsolation(isolation string) error {
+	switch strings.ToLower(isolation) {
+	case "", "default", "process", "hyperv":
+		return nil
+	}
+	return errors.Wrap(errInvalidIsolation, isolation)
+}
@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isAbsPath(t *testing.T) {
+	defer func() { goos = runtime.GOOS }()
+	tests := []struct {
+		path            string
+		expectedUnix    bool
+		expectedWindows bool
+	}{
+		{"/data", true, true},
+		{"data", false, false},
+		{"./data", false, false},
+		{`C:\data`, false, true},
+		{"c:/data", false, true},
+		{`\\server\share`, false, true},
+		{`C:data`, false, false},
+		{`C:`, false, false},
+		{`\\`, false, false},
+		{"", false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			goos = "linux"
+			require.Equal(t, test.expectedUnix, isAbsPath(test.path))
+			goos = "windows"
+			require.Equal(t, test.expectedWindows, isAbsPath(test.path))
+		})
+	}
+}
+
+func Test_createContainerWindowsOptions(t *testing.T) {
+	defer func() { goos = runtime.GOOS }()
+	tests := []struct {
+		name               string
+		goos               string
+		daemonOS           string
+		options            Options
+		expectedIsolation  dockerContainer.Isolation
+		expectedMounts     []mount.Mount
+		expectedPortHostIP string
+		expectedError      error
+	}{
+		{"windows_hyperv", "windows", "windows", Options{
+			Isolation:    "hyperv",
+			Mounts:       []Mount{{Source: `C:\src`, Target: `C:\data`, ReadOnly: true}},
+			ExposedPorts: []string{"8080:80"},
+		}, "hyperv", []mount.Mount{{Type: mount.TypeBind, Source: `C:\src`, Target: `C:\data`, ReadOnly: true}}, "", nil},
+		{"windows_process", "windows", "windows", Options{Isolation: "process", ExposedPorts: []string{"8080:80"}}, "process", nil, "", nil},
+		{"linux", "linux", "linux", Options{
+			Mounts:       []Mount{{Source: "/src", Target: "/data"}},
+			ExposedPorts: []string{"8080:80"},
+		}, "", []mount.Mount{{Type: mount.TypeBind, Source: "/src", Target: "/data"}}, "0.0.0.0", nil},
+		{"linux_windows_path", "linux", "linux", Options{Mounts: []Mount{{Source: "/src", Target: `C:\data`}}}, "", nil, "", errInvalidMountPath},
+		{"relative_source", "windows", "windows", Options{Mounts: []Mount{{Source: "src", Target: `C:\data`}}}, "", nil, "", errInvalidMountPath},
+		// Ports published by a Linux daemon, e.g. Docker Desktop on Windows, are bound to all interfaces.
+		{"windows_linux_daemon", "windows", "linux", Options{ExposedPorts: []string{"8080:80"}}, "", nil, "0.0.0.0", nil},
+		{"invalid_isolation", "windows", "windows", Options{Isolation: "vm"}, "", nil, "", errInvalidIsolation},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			goos = test.goos
+			mockedDaemonInfo.OSType = test.daemonOS
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(context.Background(), mockedImageName, &test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError != nil {
+				return
+			}
+			require.Equal(t, test.expectedIsolation, mockedContainerCreateHostConfig.Isolation)
+			require.Equal(t, test.expectedMounts, mockedContainerCreateHostConfig.Mounts)
+			for _, bindings := range mockedContainerCreateHostConfig.PortBindings {
+				require.Equal(t, test.expectedPortHostIP, bindings[0].HostIP)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	dockerNetwork "github.com/docker/docker/api/types/network"
+	"github.com/pkg/errors"
+)
+
+// defaultNetworkDriver is the network driver used if no driver is specified in [NetworkOptions].
+const defaultNetworkDriver = "bridge"
+
+var errEmptyNetworkName = errors.New("empty network name")
+
+// NetworkOptions holds Docker network optional attributes values.
+type NetworkOptions struct {
+	// Driver is the network driver name, e.g. "overlay" or "macvlan". Defaults to "bridge".
+	Driver string
+	// Options holds network driver specific options, e.g. "parent" for "macvlan" driver.
+	Options map[string]string
+	// Labels holds custom labels applied to the network.
+	Labels map[string]string
+}
+
+// createNetwork calls Docker client NetworkCreate method.
+func (c *defaultClient) createNetwork(ctx context.Context, name string, options NetworkOptions) (string, error) {
+	if len(options.Driver) == 0 {
+		options.Driver = defaultNetworkDriver
+	}
+	r, err := c.handler.NetworkCreate(ctx, name, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         options.Driver,
+		Options:        options.Options,
+		Labels:         containerLabels(options.Labels),
+	})
+	if err != nil {
+		return "", err
+	}
+	return r.ID, nil
+}
+
+// removeNetwork calls Docker client NetworkRemove method.
+func (c *defaultClient) removeNetwork(ctx context.Context, id string) error {
+	return c.handler.NetworkRemove(ctx, id)
+}
+
+// connectNetwork calls Docker client NetworkConnect method. The container gets the given aliases in the network.
+func (c *defaultClient) connectNetwork(ctx context.Context, network, containerID string, aliases []string) error {
+	var settings *dockerNetwork.EndpointSettings
+	if len(aliases) > 0 {
+		settings = &dockerNetwork.EndpointSettings{Aliases: aliases}
+	}
+	return c.handler.NetworkConnect(ctx, network, containerID, settings)
+}
+
+// CreateNetwork creates a new Docker network with the given name and returns its id.
+// Optional network attributes values can be specified in options argument.
+func CreateNetwork(ctx context.Context, name string, options *NetworkOptions) (string, error) {
+	if len(name) == 0 {
+		return "", errEmptyNetworkName
+	}
+	c, err := getClient()
+	if err != nil {
+		return "", err
+	}
+	defer c.close()
+	var o NetworkOptions
+	if options != nil {
+		o = *options
+	}
+	return c.createNetwork(ctx, name, o)
+}
+
+// RemoveNetwork removes Docker network identified by the given id or name.
+func RemoveNetwork(ctx context.Context, id string) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.removeNetwork(ctx, id)
+}
+
+// ConnectNetwork connects Docker container identified by the given id to the network identified by the given
+// id or name.
+func ConnectNetwork(ctx context.Context, network, containerID string) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.connectNetwork(ctx, network, containerID, nil)
+}
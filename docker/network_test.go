@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateNetwork(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name            string
+		networkName     string
+		options         *NetworkOptions
+		expectedOptions *types.NetworkCreate
+		expectedError   error
+	}{
+		{"default_driver", "mockedNetwork", nil, &types.NetworkCreate{
+			CheckDuplicate: true,
+			Driver:         "bridge",
+			Labels:         containerLabels(nil),
+		}, nil},
+		{"custom_driver", "mockedNetwork", &NetworkOptions{
+			Driver:  "macvlan",
+			Options: map[string]string{"parent": "eth0"},
+		}, &types.NetworkCreate{
+			CheckDuplicate: true,
+			Driver:         "macvlan",
+			Options:        map[string]string{"parent": "eth0"},
+			Labels:         containerLabels(nil),
+		}, nil},
+		{"empty_name", "", nil, nil, errEmptyNetworkName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			id, err := CreateNetwork(context.Background(), test.networkName, test.options)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedOptions, mockedNetworkCreateOptions)
+			if test.expectedError == nil {
+				require.Equal(t, mockedNetworkID, id)
+				require.Equal(t, test.networkName, mockedNetworkCreateName)
+			}
+		})
+	}
+}
+
+func Test_RemoveNetwork(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	require.NoError(t, RemoveNetwork(context.Background(), mockedNetworkID))
+	require.Equal(t, []string{mockedNetworkID}, mockedRemovedNetworks)
+}
@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DiffOptions returns human-readable differences between the given container options, one per differing field,
+// e.g. `Name: "db" != "cache"`. Nil options are compared as zero ones. Function fields, e.g. OnEvent, are only
+// compared for being set, and TB for identity. Returns nil if the options are equal.
+func DiffOptions(a, b *Options) []string {
+	if a == nil {
+		a = &Options{}
+	}
+	if b == nil {
+		b = &Options{}
+	}
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var diff []string
+	for i := 0; i < va.NumField(); i++ {
+		name := va.Type().Field(i).Name
+		fa, fb := va.Field(i), vb.Field(i)
+		switch fa.Kind() {
+		case reflect.Func:
+			if fa.IsNil() != fb.IsNil() {
+				diff = append(diff, fmt.Sprintf("%s: %s != %s", name, setLabel(fa), setLabel(fb)))
+			}
+		case reflect.Interface:
+			if fa.Interface() != fb.Interface() {
+				diff = append(diff, fmt.Sprintf("%s: %s != %s", name, formatOption(fa), formatOption(fb)))
+			}
+		default:
+			if !reflect.DeepEqual(fa.Interface(), fb.Interface()) {
+				diff = append(diff, fmt.Sprintf("%s: %s != %s", name, formatOption(fa), formatOption(fb)))
+			}
+		}
+	}
+	return diff
+}
+
+// setLabel returns whether the given function option value is set.
+func setLabel(v reflect.Value) string {
+	if v.IsNil() {
+		return "not set"
+	}
+	return "set"
+}
+
+// formatOption returns a human-readable representation of the given option value. Strings are quoted,
+// pointers are dereferenced.
+func formatOption(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return "<nil>"
+		}
+		if v.Kind() == reflect.Pointer {
+			return formatOption(v.Elem())
+		}
+		return fmt.Sprintf("%T", v.Interface())
+	case reflect.String:
+		return fmt.Sprintf("%q", v.Interface())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			return fmt.Sprintf("%q", v.Interface())
+		}
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
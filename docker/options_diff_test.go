@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DiffOptions(t *testing.T) {
+	fakeTime := time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)
+	base := Options{
+		Name:                 "db",
+		EnvironmentVariables: []string{"POSTGRES_USER=postgres"},
+		ExposedPorts:         []string{"5432:5432"},
+		Mounts:               []Mount{{Source: "data", Target: "/var/lib/postgresql/data"}},
+		PostReadyDelay:       time.Second,
+	}
+	tests := []struct {
+		name     string
+		a, b     *Options
+		expected []string
+	}{
+		{"equal", &base, &Options{
+			Name:                 "db",
+			EnvironmentVariables: []string{"POSTGRES_USER=postgres"},
+			ExposedPorts:         []string{"5432:5432"},
+			Mounts:               []Mount{{Source: "data", Target: "/var/lib/postgresql/data"}},
+			PostReadyDelay:       time.Second,
+		}, nil},
+		{"several_fields", &base, &Options{
+			Name:                 "cache",
+			EnvironmentVariables: []string{"POSTGRES_USER=admin"},
+			ExposedPorts:         []string{"5432:5432"},
+			PostReadyDelay:       time.Second,
+			FakeTime:             &fakeTime,
+			OnEvent:              func(LifecycleEvent) {},
+		}, []string{
+			`Name: "db" != "cache"`,
+			`EnvironmentVariables: ["POSTGRES_USER=postgres"] != ["POSTGRES_USER=admin"]`,
+			`Mounts: [{Source:data Target:/var/lib/postgresql/data ReadOnly:false}] != []`,
+			`OnEvent: not set != set`,
+			`FakeTime: <nil> != 2024-02-29 12:00:00 +0000 UTC`,
+		}},
+		{"nil", nil, &Options{StartTimeout: 30}, []string{"StartTimeout: 0 != 30"}},
+		{"test", &Options{TB: t}, &Options{TB: t}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, DiffOptions(test.a, test.b))
+		})
+	}
+}
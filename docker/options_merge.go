@@ -0,0 +1,37 @@
+package docker
+
+import (
+	"reflect"
+)
+
+// MergeOptions returns base options with the fields set in override replacing the base ones, e.g. to layer
+// application defaults over preset options. A field is set in override if it is a non-empty string, slice, or map,
+// a positive number, true, or a non-nil pointer, interface, or function. Slices and maps are replaced as a whole,
+// e.g. override EnvironmentVariables replace all base ones, and Labels are not merged key by key. Consequently,
+// override cannot unset a base field, e.g. turn a boolean option off or clear a list.
+func MergeOptions(base, override Options) Options {
+	merged := base
+	vm, vo := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override)
+	for i := 0; i < vo.NumField(); i++ {
+		if optionSet(vo.Field(i)) {
+			vm.Field(i).Set(vo.Field(i))
+		}
+	}
+	return merged
+}
+
+// optionSet reports whether the given option value is set, according to MergeOptions rules.
+func optionSet(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() > 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() > 0
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Pointer, reflect.Interface, reflect.Func:
+		return !v.IsNil()
+	default:
+		return !v.IsZero()
+	}
+}
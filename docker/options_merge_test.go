@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Distinct function literals, so that function fields of fullOptions with different n are told apart by identity.
+var (
+	testBackoffs    = []Backoff{nil, func(int) time.Duration { return time.Second }, func(int) time.Duration { return 2 * time.Second }}
+	testOnEvents    = []func(LifecycleEvent){nil, func(LifecycleEvent) {}, func(LifecycleEvent) {}}
+	testImageChecks = []func(context.Context, ImageInfo) error{
+		nil, func(context.Context, ImageInfo) error { return nil }, func(context.Context, ImageInfo) error { return nil },
+	}
+)
+
+// fullOptions returns options with all fields set to values derived from the given prefix and n, 1 or 2.
+func fullOptions(tb testing.TB, prefix string, n int) Options {
+	init, fakeTime := true, time.Date(2024, 1, n, 0, 0, 0, 0, time.UTC)
+	d := time.Duration(n) * time.Second
+	return Options{
+		Name:                   prefix + "-name",
+		Healthcheck:            prefix + "-healthcheck",
+		EnvironmentVariables:   []string{prefix + "=1"},
+		ExposedPorts:           []string{":" + prefix},
+		StartTimeout:           n,
+		Cmd:                    []string{prefix + "-cmd"},
+		Entrypoint:             []string{prefix + "-entrypoint"},
+		RestartPolicy:          "on-failure:" + prefix,
+		StartBackoff:           testBackoffs[n],
+		StopTimeout:            n,
+		HealthcheckInterval:    d,
+		HealthcheckTimeout:     d,
+		HealthcheckStartPeriod: d,
+		HealthcheckRetries:     n,
+		PostReadyDelay:         d,
+		CgroupnsMode:           prefix + "-cgroupns",
+		CgroupParent:           prefix + "-cgroup",
+		Runtime:                prefix + "-runtime",
+		SecurityOpts:           []string{prefix + "-security"},
+		Init:                   &init,
+		StrictDaemonFeatures:   true,
+		MountDockerSocket:      true,
+		Mounts:                 []Mount{{Source: "/" + prefix, Target: "/data"}},
+		Isolation:              prefix + "-isolation",
+		OnEvent:                testOnEvents[n],
+		DNSSearch:              []string{prefix + ".local"},
+		DNSOptions:             []string{"ndots:" + prefix},
+		MacAddress:             prefix + "-mac",
+		User:                   prefix + "-user",
+		ExtraHosts:             []string{prefix + ":127.0.0.1"},
+		Labels:                 map[string]string{prefix: "label"},
+		PullPolicy:             PullPolicy(prefix),
+		ImageCheck:             testImageChecks[n],
+		PublishAllExposedPorts: true,
+		Network:                prefix + "-network",
+		Networks:               []string{prefix + "-networks"},
+		NetworkAliases:         map[string][]string{prefix: {"alias"}},
+		IsolatedNetwork:        true,
+		Sidecars:               []SidecarSpec{{Image: prefix + "-sidecar"}},
+		EnableHostGateway:      true,
+		AddHostGatewayAlias:    true,
+		FakeTime:               &fakeTime,
+		FakeTimeLibrary:        prefix + "-faketime",
+		FakeTimeHostLibrary:    prefix + "-faketime-host",
+		Shell:                  []string{prefix + "-shell"},
+		KeepOnFailure:          true,
+		ForwardPorts:           true,
+		GRPCTLSConfig:          &tls.Config{ServerName: prefix},
+		PreStartWait:           []HostWait{{TCPAddr: prefix + ":80"}},
+		DebugHold:              true,
+		TB:                     tb,
+	}
+}
+
+// sameOption reports whether the given option values are equal. Functions are compared by identity.
+func sameOption(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Func {
+		return a.Pointer() == b.Pointer()
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func Test_MergeOptions(t *testing.T) {
+	base, override := fullOptions(t, "base", 1), fullOptions(t, "override", 2)
+	vb, vo := reflect.ValueOf(base), reflect.ValueOf(override)
+	for i := 0; i < vb.NumField(); i++ {
+		name := vb.Type().Field(i).Name
+		require.True(t, optionSet(vb.Field(i)), "fullOptions does not set %s", name)
+	}
+
+	for i := 0; i < vo.NumField(); i++ {
+		name := vo.Type().Field(i).Name
+		t.Run(name, func(t *testing.T) {
+			single := Options{}
+			reflect.ValueOf(&single).Elem().Field(i).Set(vo.Field(i))
+			merged := reflect.ValueOf(MergeOptions(base, single))
+			for j := 0; j < merged.NumField(); j++ {
+				expected := vb.Field(j)
+				if j == i {
+					expected = vo.Field(j)
+				}
+				require.True(t, sameOption(expected, merged.Field(j)), "unexpected %s value", merged.Type().Field(j).Name)
+			}
+		})
+	}
+}
+
+func Test_MergeOptionsNotSet(t *testing.T) {
+	base := fullOptions(t, "base", 1)
+	tests := []struct {
+		name     string
+		override Options
+	}{
+		{"zero", Options{}},
+		{"empty_collections", Options{EnvironmentVariables: []string{}, Labels: map[string]string{}, Mounts: []Mount{}}},
+		{"negative_numbers", Options{StartTimeout: -1, StopTimeout: -1, HealthcheckRetries: -1, HealthcheckInterval: -time.Second}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged := reflect.ValueOf(MergeOptions(base, test.override))
+			vb := reflect.ValueOf(base)
+			for j := 0; j < merged.NumField(); j++ {
+				require.True(t, sameOption(vb.Field(j), merged.Field(j)), "unexpected %s value", merged.Type().Field(j).Name)
+			}
+		})
+	}
+}
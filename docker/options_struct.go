@@ -0,0 +1,130 @@
+package docker
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// optionsTag is the name of the struct tag read by OptionsFromStruct.
+const optionsTag = "container"
+
+var (
+	errOptionsSourceNotStruct = errors.New("options source is not a struct or a pointer to struct")
+	errInvalidOptionsTag      = errors.New("invalid container struct tag")
+	errUnsupportedFieldType   = errors.New("unsupported container struct tag field type")
+
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// OptionsFromStruct returns container options built from `container` tags of the given struct fields, so that
+// one configuration struct drives both the test expectations and the container configuration. Tags hold
+// comma-separated directives:
+//   - `env=NAME` sets NAME environment variable to the field value,
+//   - `port=5432` binds the given container port to the host port set in the field, zero value binds it
+//     to an ephemeral host port,
+//   - `name` and `user` set the container name and user to the field value.
+//
+// Fields may be strings, booleans, signed and unsigned integers, and [time.Duration] values. Nested structs
+// are read recursively, fields tagged with `container:"-"` are skipped.
+func OptionsFromStruct(v any) (*Options, error) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil, errors.Wrapf(errOptionsSourceNotStruct, "%T", v)
+	}
+	options := Options{}
+	if err := readOptionsStruct(value, "", &options); err != nil {
+		return nil, err
+	}
+	return &options, nil
+}
+
+// readOptionsStruct applies `container` tags of the given struct fields to options. prefix holds the path of
+// the nested struct used in error messages.
+func readOptionsStruct(value reflect.Value, prefix string, options *Options) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, tagged := field.Tag.Lookup(optionsTag)
+		if tag == "-" {
+			continue
+		}
+		path := prefix + field.Name
+		if !tagged {
+			if field.Type.Kind() == reflect.Struct {
+				if err := readOptionsStruct(value.Field(i), path+".", options); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		fieldValue, err := formatOptionsField(value.Field(i))
+		if err != nil {
+			return errors.Wrapf(err, "%s: %s", path, field.Type)
+		}
+		for _, directive := range strings.Split(tag, ",") {
+			if err = applyOptionsDirective(strings.TrimSpace(directive), fieldValue, options); err != nil {
+				return errors.Wrapf(err, "%s: %q", path, tag)
+			}
+		}
+	}
+	return nil
+}
+
+// formatOptionsField returns the string representation of the given field value.
+func formatOptionsField(value reflect.Value) (string, error) {
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String(), nil
+	}
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	}
+	return "", errUnsupportedFieldType
+}
+
+// applyOptionsDirective applies a single `container` tag directive, e.g. `env=POSTGRES_PASSWORD`, with the given
+// field value to options.
+func applyOptionsDirective(directive, fieldValue string, options *Options) error {
+	key, argument, hasArgument := strings.Cut(directive, "=")
+	switch key {
+	case "env":
+		if len(argument) == 0 {
+			return errors.Wrap(errInvalidOptionsTag, "env directive requires a variable name")
+		}
+		options.EnvironmentVariables = append(options.EnvironmentVariables, argument+"="+fieldValue)
+	case "port":
+		number, _, _ := strings.Cut(argument, "/")
+		if port, err := strconv.ParseUint(number, 10, 16); err != nil || port == 0 {
+			return errors.Wrap(errInvalidOptionsTag, "port directive requires a container port number")
+		}
+		hostPort := fieldValue
+		if hostPort == "0" {
+			hostPort = ""
+		}
+		options.ExposedPorts = append(options.ExposedPorts, hostPort+":"+argument)
+	case "name", "user":
+		if hasArgument {
+			return errors.Wrapf(errInvalidOptionsTag, "%s directive takes no argument", key)
+		}
+		if key == "name" {
+			options.Name = fieldValue
+		} else {
+			options.User = fieldValue
+		}
+	default:
+		return errors.Wrapf(errInvalidOptionsTag, "unknown directive %q", key)
+	}
+	return nil
+}
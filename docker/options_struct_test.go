@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+type postgresTestConfig struct {
+	Name     string        `container:"name"`
+	Password string        `container:"env=POSTGRES_PASSWORD"`
+	Port     int           `container:"port=5432,env=PGPORT"`
+	Debug    bool          `container:"env=DEBUG"`
+	Timeout  time.Duration `container:"env=STATEMENT_TIMEOUT"`
+	Metrics  metricsTestConfig
+	Expected string `container:"-"`
+	Rows     int
+}
+
+type metricsTestConfig struct {
+	Port    uint16 `container:"port=9187/tcp"`
+	Enabled bool   `container:"env=METRICS_ENABLED"`
+}
+
+func TestOptionsFromStruct(t *testing.T) {
+	config := postgresTestConfig{
+		Name:     "db",
+		Password: "secret=1",
+		Port:     15432,
+		Timeout:  time.Second * 30,
+		Metrics:  metricsTestConfig{Enabled: true},
+		Expected: "ignored",
+		Rows:     10,
+	}
+	expected := &Options{
+		Name: "db",
+		EnvironmentVariables: []string{
+			"POSTGRES_PASSWORD=secret=1", "PGPORT=15432", "DEBUG=false", "STATEMENT_TIMEOUT=30s", "METRICS_ENABLED=true",
+		},
+		ExposedPorts: []string{"15432:5432", ":9187/tcp"},
+	}
+	for _, v := range []any{config, &config} {
+		options, err := OptionsFromStruct(v)
+		require.NoError(t, err)
+		require.Equal(t, expected, options)
+	}
+}
+
+func TestOptionsFromStructErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         any
+		expectedError error
+	}{
+		{"nil", nil, errOptionsSourceNotStruct},
+		{"not_struct", "db", errOptionsSourceNotStruct},
+		{"nil_pointer", (*postgresTestConfig)(nil), errOptionsSourceNotStruct},
+		{"unknown_directive", struct {
+			Host string `container:"host"`
+		}{}, errInvalidOptionsTag},
+		{"env_without_name", struct {
+			Password string `container:"env="`
+		}{}, errInvalidOptionsTag},
+		{"port_without_number", struct {
+			Port int `container:"port"`
+		}{}, errInvalidOptionsTag},
+		{"port_not_number", struct {
+			Port int `container:"port=postgres"`
+		}{}, errInvalidOptionsTag},
+		{"name_with_argument", struct {
+			Name string `container:"name=db"`
+		}{}, errInvalidOptionsTag},
+		{"unsupported_type", struct {
+			Hosts []string `container:"env=HOSTS"`
+		}{}, errUnsupportedFieldType},
+		{"nested_error", struct {
+			Metrics struct {
+				Port float64 `container:"port=9187"`
+			}
+		}{}, errUnsupportedFieldType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := OptionsFromStruct(test.value)
+			require.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func Test_createContainerOptionsFromStruct(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	options, err := OptionsFromStruct(postgresTestConfig{Password: "secret", Port: 15432})
+	require.NoError(t, err)
+	_, err = c.createContainer(context.Background(), mockedImageName, options)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"DEBUG=false", "METRICS_ENABLED=false", "PGPORT=15432", "POSTGRES_PASSWORD=secret", "STATEMENT_TIMEOUT=0s",
+	}, mockedContainerCreateConfig.Env)
+	require.Equal(t, nat.PortSet{"5432/tcp": {}, "9187/tcp": {}}, mockedContainerCreateConfig.ExposedPorts)
+	require.Equal(t, "15432", mockedContainerCreateHostConfig.PortBindings["5432/tcp"][0].HostPort)
+	require.Empty(t, mockedContainerCreateHostConfig.PortBindings["9187/tcp"][0].HostPort)
+}
@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+)
+
+// DefaultMaxOutputBytes is the default limit of the container output buffered by Exec, ExecWithResult, and Logs.
+const DefaultMaxOutputBytes = 10 << 20
+
+// maxOutputBytes holds the container output limit set with SetMaxOutputBytes.
+var maxOutputBytes atomic.Int64
+
+func init() {
+	maxOutputBytes.Store(DefaultMaxOutputBytes)
+}
+
+// SetMaxOutputBytes sets the limit of the container output buffered by Exec, ExecWithResult, and Logs, so that
+// a runaway command cannot exhaust the test process memory. Output exceeding the limit is discarded and replaced
+// with `[truncated after N bytes]` marker, [ExecResult] Truncated flag is set. A non-positive value disables the limit.
+// Streaming functions, e.g. Export, are not limited.
+func SetMaxOutputBytes(limit int64) {
+	maxOutputBytes.Store(limit)
+}
+
+// limitedBuffer is a writer buffering up to limit bytes and discarding the rest.
+type limitedBuffer struct {
+	buffer         *bytes.Buffer
+	limit, written int64
+	truncated      bool
+}
+
+// newLimitedBuffer returns a limitedBuffer writing to the given buffer with the limit set with SetMaxOutputBytes.
+func newLimitedBuffer(buffer *bytes.Buffer) *limitedBuffer {
+	return &limitedBuffer{buffer: buffer, limit: maxOutputBytes.Load()}
+}
+
+// Write buffers p up to the limit. The rest is discarded and reported as written, so that the output stream is
+// read to the end.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.written+int64(len(p)) > b.limit {
+		b.truncated = true
+		b.buffer.Write(p[:b.limit-b.written])
+		b.written = b.limit
+		return len(p), nil
+	}
+	b.written += int64(len(p))
+	return b.buffer.Write(p)
+}
+
+// close appends the truncation marker to the buffer if output has been discarded and reports whether it has.
+func (b *limitedBuffer) close() bool {
+	if b.truncated {
+		fmt.Fprintf(b.buffer, "\n[truncated after %d bytes]", b.limit)
+	}
+	return b.truncated
+}
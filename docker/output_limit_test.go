@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ExecWithResultOutputLimit(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetMaxOutputBytes(DefaultMaxOutputBytes)
+	tests := []struct {
+		name     string
+		limit    int64
+		output   ExecResult
+		expected ExecResult
+	}{
+		{"within_limit", 16, ExecResult{Stdout: "ok", Stderr: "warning"}, ExecResult{Stdout: "ok", Stderr: "warning"}},
+		{"stdout_truncated", 4, ExecResult{Stdout: strings.Repeat("x", 100), Stderr: "warn"}, ExecResult{
+			Stdout:    "xxxx\n[truncated after 4 bytes]",
+			Stderr:    "warn",
+			Truncated: true,
+		}},
+		{"stderr_truncated", 4, ExecResult{ExitCode: 1, Stderr: "error: out of memory"}, ExecResult{
+			ExitCode:  1,
+			Stderr:    "erro\n[truncated after 4 bytes]",
+			Truncated: true,
+		}},
+		{"unlimited", 0, ExecResult{Stdout: strings.Repeat("x", 100)}, ExecResult{Stdout: strings.Repeat("x", 100)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			SetMaxOutputBytes(test.limit)
+			mockedExecScript = func(types.ExecConfig) ExecResult { return test.output }
+			result, err := ExecWithResult(context.Background(), mockedContainerID, types.ExecConfig{Cmd: []string{"yes"}})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, result)
+		})
+	}
+}
+
+func Test_ExecOutputLimit(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetMaxOutputBytes(DefaultMaxOutputBytes)
+	resetMocks()
+	SetMaxOutputBytes(64)
+	mockedExecScript = func(types.ExecConfig) ExecResult { return ExecResult{Stdout: strings.Repeat("x", 1<<20)} }
+	buffer := bytes.Buffer{}
+	buffer.WriteString("previous output\n")
+	require.NoError(t, ExecCommand(context.Background(), mockedContainerID, "yes", &buffer))
+	require.True(t, strings.HasPrefix(buffer.String(), "previous output\n"))
+	require.True(t, strings.HasSuffix(buffer.String(), "\n[truncated after 64 bytes]"))
+	require.Equal(t, len("previous output\n")+64+len("\n[truncated after 64 bytes]"), buffer.Len())
+}
+
+func Test_LogsOutputLimit(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetMaxOutputBytes(DefaultMaxOutputBytes)
+	resetMocks()
+	SetMaxOutputBytes(12)
+	mockedContainerLogs = []mockedLogLine{
+		{stdcopy.Stdout, "starting\n"},
+		{stdcopy.Stderr, "warning: low memory\n"},
+		{stdcopy.Stdout, strings.Repeat("spam\n", 1000)},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	logs, err := c.Logs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "starting\nwar\n[truncated after 12 bytes]", logs)
+}
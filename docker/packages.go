@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// packageManager holds data needed to detect a package manager inside a container and install packages with it.
+type packageManager struct {
+	binary string
+	// installCommand returns a shell command installing the given packages, which are shell-quoted and space separated.
+	installCommand func(packages string) string
+}
+
+// packageManagers holds the list of supported package managers in the order of detection.
+var packageManagers = []packageManager{
+	{"apk", func(packages string) string { return "apk add --no-cache " + packages }},
+	{"apt-get", func(packages string) string {
+		return "apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends " + packages
+	}},
+	{"microdnf", func(packages string) string { return "microdnf install -y " + packages }},
+	{"yum", func(packages string) string { return "yum install -y " + packages }},
+}
+
+var (
+	// ErrUnknownPackageManager is returned by InstallPackages if none of the supported package managers
+	// is available in container.
+	ErrUnknownPackageManager = errors.New("unknown package manager")
+	errPackagesInstall       = errors.New("packages installation failed")
+	errInvalidPackageName    = errors.New("invalid package name")
+)
+
+// InstallPackages installs packages in container using the package manager available in the container image.
+// Supported package managers are apk, apt-get, microdnf, and yum. Package names are passed to the package manager
+// shell-quoted, names which are empty or start with `-`, i.e. would be taken for options, are rejected.
+func (c *container) InstallPackages(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		if len(name) == 0 || strings.HasPrefix(name, "-") {
+			return errors.Wrapf(errInvalidPackageName, "%q", name)
+		}
+		quoted = append(quoted, shellQuote(name))
+	}
+	manager, err := c.detectPackageManager(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := c.ExecAsRoot(ctx, []string{"sh", "-c", manager.installCommand(strings.Join(quoted, " "))})
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return errors.Wrapf(errPackagesInstall, "%s exit code %d: %s", manager.binary, result.ExitCode, result.Stderr)
+	}
+	return nil
+}
+
+// detectPackageManager probes container for supported package managers binaries.
+func (c *container) detectPackageManager(ctx context.Context) (packageManager, error) {
+	for _, manager := range packageManagers {
+		result, err := c.ExecAsRoot(ctx, []string{"sh", "-c", "command -v " + manager.binary})
+		if err != nil {
+			return packageManager{}, err
+		}
+		if result.ExitCode == 0 {
+			return manager, nil
+		}
+	}
+	return packageManager{}, ErrUnknownPackageManager
+}
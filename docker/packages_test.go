@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// packageManagerScript returns a mocked exec script emulating a container with the given package manager binary.
+func packageManagerScript(binary string, installExitCode int) func(config types.ExecConfig) ExecResult {
+	return func(config types.ExecConfig) ExecResult {
+		command := config.Cmd[len(config.Cmd)-1]
+		if strings.HasPrefix(command, "command -v ") {
+			if strings.TrimPrefix(command, "command -v ") == binary {
+				return ExecResult{Stdout: "/usr/bin/" + binary}
+			}
+			return ExecResult{ExitCode: 1}
+		}
+		return ExecResult{ExitCode: installExitCode, Stderr: "mocked install output"}
+	}
+}
+
+func Test_InstallPackages(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name            string
+		binary          string
+		installExitCode int
+		expectedCommand string
+		expectedError   error
+	}{
+		{"apk", "apk", 0, "apk add --no-cache 'curl' 'jq'", nil},
+		{
+			"apt-get", "apt-get", 0,
+			"apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends 'curl' 'jq'", nil,
+		},
+		{"microdnf", "microdnf", 0, "microdnf install -y 'curl' 'jq'", nil},
+		{"yum", "yum", 0, "yum install -y 'curl' 'jq'", nil},
+		{"install_failure", "apk", 1, "apk add --no-cache 'curl' 'jq'", errPackagesInstall},
+		{"unknown", "pacman", 0, "", ErrUnknownPackageManager},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = packageManagerScript(test.binary, test.installExitCode)
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			require.ErrorIs(t, c.InstallPackages(context.Background(), "curl", "jq"), test.expectedError)
+			for _, config := range mockedExecConfigs {
+				require.Equal(t, "0", config.User)
+			}
+			if len(test.expectedCommand) > 0 {
+				lastConfig := mockedExecConfigs[len(mockedExecConfigs)-1]
+				require.Equal(t, []string{"sh", "-c", test.expectedCommand}, lastConfig.Cmd)
+			}
+		})
+	}
+}
+
+func Test_InstallPackagesNames(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name            string
+		packages        []string
+		expectedCommand string
+		expectedError   error
+	}{
+		{"shell_metacharacters", []string{"curl; touch /pwned", "it's"}, `apk add --no-cache 'curl; touch /pwned' 'it'\''s'`, nil},
+		{"option", []string{"curl", "--allow-untrusted"}, "", errInvalidPackageName},
+		{"empty", []string{""}, "", errInvalidPackageName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedExecScript = packageManagerScript("apk", 0)
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			require.ErrorIs(t, c.InstallPackages(context.Background(), test.packages...), test.expectedError)
+			if test.expectedError != nil {
+				require.Empty(t, mockedExecConfigs)
+				return
+			}
+			require.Equal(t, []string{"sh", "-c", test.expectedCommand}, mockedExecConfigs[len(mockedExecConfigs)-1].Cmd)
+		})
+	}
+}
+
+func Test_ExecAsRoot(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedExecScript = func(config types.ExecConfig) ExecResult {
+		return ExecResult{ExitCode: 2, Stdout: "out", Stderr: "err"}
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	result, err := c.ExecAsRoot(context.Background(), []string{"chmod", "777", "/data"})
+	require.NoError(t, err)
+	require.Equal(t, ExecResult{ExitCode: 2, Stdout: "out", Stderr: "err"}, result)
+	require.Equal(t, []types.ExecConfig{{
+		User: "0", AttachStdout: true, AttachStderr: true, Cmd: []string{"chmod", "777", "/data"},
+	}}, mockedExecConfigs)
+}
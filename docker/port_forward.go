@@ -0,0 +1,123 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// portForwardTimeout is the maximum time to wait for an SSH port forward to start accepting connections.
+	portForwardTimeout = 10 * time.Second
+	// portForwardPollInterval is the interval between checks whether an SSH port forward accepts connections.
+	portForwardPollInterval = 100 * time.Millisecond
+)
+
+var (
+	errPortForward = errors.New("cannot forward container port")
+
+	// sshCommandFn returns the command running ssh client with the given arguments. It is replaced in tests.
+	sshCommandFn = func(args ...string) *exec.Cmd { return exec.Command("ssh", args...) }
+)
+
+// ForwardPort returns a local address at which the given container port is reachable, and a function closing
+// the forward. If Docker daemon is accessed over SSH, e.g. `DOCKER_HOST=ssh://user@host`, mapped container ports
+// are only reachable on the remote host, so an SSH local port forward to the mapped port is opened using `ssh`
+// client. For other daemons, the container port [Container.Endpoint] is returned and the close function does nothing.
+func ForwardPort(ctx context.Context, c Container, containerPort string) (string, func(), error) {
+	cl, err := getClient()
+	if err != nil {
+		return "", nil, err
+	}
+	host := cl.daemonHost()
+	cl.close()
+
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme != "ssh" {
+		address, err := c.Endpoint(ctx, containerPort)
+		if err != nil {
+			return "", nil, err
+		}
+		return address, func() {}, nil
+	}
+	port, err := c.MappedPort(ctx, containerPort)
+	if err != nil {
+		return "", nil, err
+	}
+	return forwardSSHPort(ctx, u, port)
+}
+
+// forwardSSHPort opens an SSH local port forward to the given port on the remote host and waits until it accepts
+// connections. The forward is served by `ssh` process which is killed by the returned close function.
+func forwardSSHPort(ctx context.Context, host *url.URL, remotePort string) (string, func(), error) {
+	localAddress, err := freeLocalAddress()
+	if err != nil {
+		return "", nil, errors.Wrap(errPortForward, err.Error())
+	}
+	cmd := sshCommandFn(sshForwardArgs(host, localAddress, remotePort)...)
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	if err = cmd.Start(); err != nil {
+		return "", nil, errors.Wrap(errPortForward, err.Error())
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	once := sync.Once{}
+	closeForward := func() {
+		once.Do(func() {
+			_ = cmd.Process.Kill()
+			<-exited
+		})
+	}
+
+	deadline := nowFn().Add(portForwardTimeout)
+	for {
+		select {
+		case err = <-exited:
+			return "", nil, errors.Wrapf(errPortForward, "ssh exited: %v: %s", err, strings.TrimSpace(stderr.String()))
+		default:
+		}
+		conn, dialErr := net.DialTimeout("tcp", localAddress, portForwardPollInterval)
+		switch {
+		case dialErr == nil:
+			_ = conn.Close()
+			return localAddress, closeForward, nil
+		case ctx.Err() != nil:
+			closeForward()
+			return "", nil, ctx.Err()
+		case !nowFn().Before(deadline):
+			closeForward()
+			return "", nil, errors.Wrapf(errPortForward, "%s is not accepting connections: %v", localAddress, dialErr)
+		}
+		sleepFn(portForwardPollInterval)
+	}
+}
+
+// sshForwardArgs returns `ssh` arguments forwarding the given local address to the given port on the remote host.
+func sshForwardArgs(host *url.URL, localAddress, remotePort string) []string {
+	args := []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes", "-L", localAddress + ":localhost:" + remotePort}
+	if host.User != nil {
+		args = append(args, "-l", host.User.Username())
+	}
+	if len(host.Port()) > 0 {
+		args = append(args, "-p", host.Port())
+	}
+	return append(args, "--", host.Hostname())
+}
+
+// freeLocalAddress returns a loopback address with a port which is free at the moment.
+func freeLocalAddress() (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer listener.Close()
+	return listener.Addr().String(), nil
+}
@@ -0,0 +1,189 @@
+//go:build ssh
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fakeSSHEnv     = "TESTUTILS_FAKE_SSH"
+	fakeSSHFailEnv = "TESTUTILS_FAKE_SSH_FAIL"
+)
+
+// TestFakeSSH is not a real test. It is run as a subprocess replacing `ssh` client: it serves the local forward
+// given in `-L` argument by proxying connections to the forwarded port on the loopback interface.
+func TestFakeSSH(t *testing.T) {
+	if os.Getenv(fakeSSHEnv) != "1" {
+		t.Skip("fake ssh client subprocess")
+	}
+	if message := os.Getenv(fakeSSHFailEnv); len(message) > 0 {
+		fmt.Fprintln(os.Stderr, message)
+		os.Exit(255)
+	}
+	var forward string
+	for i, arg := range os.Args {
+		if arg == "-L" {
+			forward = os.Args[i+1]
+		}
+	}
+	parts := strings.Split(forward, ":")
+	listener, err := net.Listen("tcp", parts[0]+":"+parts[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(255)
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			os.Exit(255)
+		}
+		go func() {
+			defer conn.Close()
+			remote, err := net.Dial("tcp", "127.0.0.1:"+parts[3])
+			if err != nil {
+				return
+			}
+			defer remote.Close()
+			go io.Copy(remote, conn) // nolint: errcheck
+			io.Copy(conn, remote)    // nolint: errcheck
+		}()
+	}
+}
+
+// useFakeSSH makes the package run fake ssh client subprocess. Returns a function restoring the real client.
+func useFakeSSH(failure string) func() {
+	previous := sshCommandFn
+	sshCommandFn = func(args ...string) *exec.Cmd {
+		cmd := exec.Command(os.Args[0], append([]string{"-test.run=^TestFakeSSH$", "--"}, args...)...)
+		cmd.Env = append(os.Environ(), fakeSSHEnv+"=1", fakeSSHFailEnv+"="+failure)
+		return cmd
+	}
+	return func() { sshCommandFn = previous }
+}
+
+// echoServer starts a TCP server echoing received data back. Returns the server port.
+func echoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn) // nolint: errcheck
+			}()
+		}
+	}()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	return port
+}
+
+func Test_ForwardPortSSH(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeSSH("")()
+	resetMocks()
+	mockedDaemonHost = "ssh://ci@docker.corp:2222"
+	remotePort := echoServer(t)
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, Name: "/" + mockedContainerName},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: remotePort}},
+		}}},
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+
+	address, closeForward, err := ForwardPort(context.Background(), c, "5432")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(address, "127.0.0.1:"))
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	reply := make([]byte, 4)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(reply))
+	require.NoError(t, conn.Close())
+
+	closeForward()
+	closeForward()
+	_, err = net.Dial("tcp", address)
+	require.Error(t, err)
+}
+
+func Test_ForwardPortSSHFailure(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeSSH("ssh: connect to host docker.corp port 2222: Connection refused")()
+	resetMocks()
+	mockedDaemonHost = "ssh://ci@docker.corp:2222"
+	mockedContainerInspect = mockedMultiPortInspect
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+
+	_, _, err := ForwardPort(context.Background(), c, "80")
+	require.ErrorIs(t, err, errPortForward)
+	require.ErrorContains(t, err, "ssh: connect to host docker.corp port 2222: Connection refused")
+
+	_, _, err = ForwardPort(context.Background(), c, "5432")
+	require.ErrorIs(t, err, errPortNotMapped)
+}
+
+func Test_WaitReadyForwardPorts(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeSSH("")()
+	resetMocks()
+	mockedDaemonHost = "ssh://docker.corp"
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, Name: "/" + mockedContainerName},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: echoServer(t)}},
+		}}},
+	}
+	dc := NewDatabaseContainerWithOptions(mockedImageName, Database{Port: "5432"},
+		Options{Name: mockedContainerName, ForwardPorts: true})
+	require.NoError(t, dc.WaitReady(context.Background(), time.Second))
+}
+
+func Test_WaitReadyTCPForwardPortsOnce(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeSSH("")()
+	fakeSSH := sshCommandFn
+	starts := 0
+	sshCommandFn = func(args ...string) *exec.Cmd {
+		starts++
+		return fakeSSH(args...)
+	}
+	resetMocks()
+	mockedDaemonHost = "ssh://docker.corp"
+	mockedContainerInspect = types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{ID: mockedContainerID, Name: "/" + mockedContainerName},
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"6379/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: echoServer(t)}},
+		}}},
+	}
+	dc := NewDatabaseContainerWithOptions(mockedImageName, Database{Port: "6379", ReadyProbe: ReadyProbeRedis},
+		Options{Name: mockedContainerName, ForwardPorts: true})
+
+	// The echo server replies PING, which is not a Redis reply, so all probes fail.
+	err := dc.WaitReadyTCP(context.Background(), time.Second)
+	require.ErrorIs(t, err, errWaitReadyTimeout)
+	require.ErrorContains(t, err, errUnexpectedResponse.Error())
+	require.Equal(t, 1, starts)
+}
@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ForwardPortLocal(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	tests := []struct {
+		name            string
+		daemonHost      string
+		containerPort   string
+		expectedAddress string
+		expectedError   error
+	}{
+		{"unix", "unix:///var/run/docker.sock", "80", "localhost:8080", nil},
+		{"tcp", "tcp://docker.corp:2376", "80", "docker.corp:8080", nil},
+		{"not_mapped", "unix:///var/run/docker.sock", "5432", "", errPortNotMapped},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedDaemonHost = test.daemonHost
+			mockedContainerInspect = mockedMultiPortInspect
+			c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			address, closeForward, err := ForwardPort(context.Background(), c, test.containerPort)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedAddress, address)
+			if err == nil {
+				closeForward()
+			}
+		})
+	}
+}
+
+func Test_sshForwardArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		expected []string
+	}{
+		{"host", "ssh://docker.corp", []string{"--", "docker.corp"}},
+		{"user_and_port", "ssh://ci@docker.corp:2222", []string{"-l", "ci", "-p", "2222", "--", "docker.corp"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := url.Parse(test.host)
+			require.NoError(t, err)
+			expected := append([]string{
+				"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes", "-L", "127.0.0.1:40000:localhost:8080",
+			}, test.expected...)
+			require.Equal(t, expected, sshForwardArgs(u, "127.0.0.1:40000", "8080"))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package docker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// Test_presetHealthchecks checks healthcheck configurations produced from the presets package values files,
+// so that multi-word probe commands are run by the shell as a whole.
+func Test_presetHealthchecks(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{"registry.yaml", []string{"CMD-SHELL", "wget -q --spider http://localhost:5000/v2/"}},
+		{"solr.yaml", []string{"CMD-SHELL", "wget -q -O /dev/null http://localhost:8983/solr/admin/info/system"}},
+		{"typesense.yaml", []string{
+			"CMD-SHELL",
+			`bash -c 'exec 3<>/dev/tcp/127.0.0.1/8108 && printf "GET /health HTTP/1.0\r\n\r\n" >&3 && grep -q true <&3'`,
+		}},
+		{"redis.yaml", []string{"CMD-SHELL", "redis-cli ping"}},
+		{"etcd.yaml", []string{"CMD-SHELL", "etcdctl endpoint health"}},
+		{"mysql.yaml", []string{"CMD-SHELL", "mysqladmin ping --host=127.0.0.1 --user=root --password=mysql --silent"}},
+		{"temporal.yaml", []string{"CMD-SHELL", "temporal operator cluster health --address 127.0.0.1:7233"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "presets", test.file))
+			require.NoError(t, err)
+			var values struct {
+				Container struct {
+					Healthcheck string `yaml:"healthcheck"`
+				} `yaml:"container"`
+			}
+			require.NoError(t, yaml.Unmarshal(data, &values))
+			require.Equal(t, test.expected, containerHealthcheck(&Options{Healthcheck: values.Container.Healthcheck}).Test)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/pkg/errors"
+)
+
+var errPruneStale = errors.New("cannot prune some of the stale resources")
+
+// PruneReport holds results of [PruneStaleSessionResources].
+type PruneReport struct {
+	// Containers, Networks, and Volumes hold ids of removed containers and networks, and names of removed volumes.
+	// In dry-run mode, they hold the objects which would have been removed.
+	Containers, Networks, Volumes []string
+	// Failed holds errors of objects which have not been removed, by object description, e.g. `container 4f1c`.
+	Failed map[string]error
+}
+
+// PruneOption sets an optional attribute of [PruneStaleSessionResources].
+type PruneOption func(config *pruneConfig)
+
+// pruneConfig holds optional attributes of PruneStaleSessionResources.
+type pruneConfig struct {
+	dryRun bool
+}
+
+// WithDryRun makes [PruneStaleSessionResources] report stale objects without removing them.
+func WithDryRun() PruneOption {
+	return func(config *pruneConfig) {
+		config.dryRun = true
+	}
+}
+
+// PruneStaleSessionResources removes containers, networks, and volumes created by the package, which are older
+// than the given age, e.g. ones left on a shared CI daemon by crashed test runs. Containers are force-removed
+// first, so that networks and volumes they use can be removed. Objects labeled with the run id set using SetRunID
+// are kept. Removal continues past individual failures, which are reported in the returned report and error.
+func PruneStaleSessionResources(ctx context.Context, olderThan time.Duration, opts ...PruneOption) (PruneReport, error) {
+	config := pruneConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	c, err := getClient()
+	if err != nil {
+		return PruneReport{}, err
+	}
+	defer c.close()
+
+	filters := dockerContainerFilters.NewArgs(dockerContainerFilters.Arg("label", labelManaged+"=true"))
+	containers, err := c.listContainers(ctx, filters)
+	if err != nil {
+		return PruneReport{}, err
+	}
+	networks, err := c.listNetworks(ctx, filters)
+	if err != nil {
+		return PruneReport{}, err
+	}
+	volumes, err := c.listVolumes(ctx, filters)
+	if err != nil {
+		return PruneReport{}, err
+	}
+
+	p := stalePruner{dryRun: config.dryRun, before: nowFn().Add(-olderThan), report: PruneReport{Failed: map[string]error{}}}
+	for _, container := range containers {
+		p.prune(ctx, &p.report.Containers, describeContainer("container "+container.ID, container.Labels), container.ID,
+			time.Unix(container.Created, 0), container.Labels, c.forceRemoveContainer)
+	}
+	for _, network := range networks {
+		p.prune(ctx, &p.report.Networks, "network "+network.Name, network.ID, network.Created, network.Labels, c.removeNetwork)
+	}
+	for _, volume := range volumes {
+		created, err := time.Parse(time.RFC3339Nano, volume.CreatedAt)
+		if err != nil {
+			p.report.Failed["volume "+volume.Name] = errors.Wrap(err, "creation time")
+			continue
+		}
+		p.prune(ctx, &p.report.Volumes, "volume "+volume.Name, volume.Name, created, volume.Labels, c.removeVolume)
+	}
+
+	if len(p.report.Failed) > 0 {
+		return p.report, errors.Wrapf(errPruneStale, "%d failed", len(p.report.Failed))
+	}
+	return p.report, nil
+}
+
+// stalePruner removes Docker objects created before the given time and collects the results.
+type stalePruner struct {
+	dryRun bool
+	before time.Time
+	report PruneReport
+}
+
+// prune removes the object with the given id using remove function, if it is stale, and adds the id to removed
+// or the error to the report failures, under the given description.
+func (p *stalePruner) prune(
+	ctx context.Context,
+	removed *[]string,
+	description, id string,
+	created time.Time,
+	labels map[string]string,
+	remove func(ctx context.Context, id string) error,
+) {
+	if !created.Before(p.before) {
+		return
+	}
+	if runID := getRunID(); len(runID) > 0 && labels[labelRunID] == runID {
+		return
+	}
+	if !p.dryRun {
+		if err := remove(ctx, id); err != nil {
+			p.report.Failed[description] = err
+			return
+		}
+	}
+	*removed = append(*removed, id)
+}
+
+// listNetworks calls Docker client NetworkList method with the given filters.
+func (c *defaultClient) listNetworks(ctx context.Context, filters dockerContainerFilters.Args) ([]types.NetworkResource, error) {
+	return c.handler.NetworkList(ctx, types.NetworkListOptions{Filters: filters})
+}
+
+// listVolumes calls Docker client VolumeList method with the given filters.
+func (c *defaultClient) listVolumes(ctx context.Context, filters dockerContainerFilters.Args) ([]*volume.Volume, error) {
+	response, err := c.handler.VolumeList(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	return response.Volumes, nil
+}
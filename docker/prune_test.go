@@ -0,0 +1,99 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PruneStaleSessionResources(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	defer SetRunID("")
+	errRemoveMock := errors.New("mockedRemoveError")
+	stale, fresh := nowFn().Add(-2*time.Hour), nowFn().Add(-10*time.Minute)
+	current := map[string]string{labelManaged: "true", labelRunID: "ci-job-42"}
+	tests := []struct {
+		name               string
+		runID              string
+		opts               []PruneOption
+		removeErrors       map[string]error
+		volumeCreatedAt    string
+		expectedReport     PruneReport
+		expectedFailed     []string
+		expectedError      error
+		expectedRemoveCall bool
+	}{
+		{
+			name: "stale", volumeCreatedAt: stale.Format(time.RFC3339),
+			expectedReport:     PruneReport{Containers: []string{"stale", "current"}, Networks: []string{"stale-network"}, Volumes: []string{"stale-volume"}},
+			expectedRemoveCall: true,
+		},
+		{
+			name: "current_run_kept", runID: "ci-job-42", volumeCreatedAt: stale.Format(time.RFC3339),
+			expectedReport:     PruneReport{Containers: []string{"stale"}, Networks: []string{"stale-network"}, Volumes: []string{"stale-volume"}},
+			expectedRemoveCall: true,
+		},
+		{
+			name: "dry_run", opts: []PruneOption{WithDryRun()}, volumeCreatedAt: stale.Format(time.RFC3339),
+			expectedReport: PruneReport{Containers: []string{"stale", "current"}, Networks: []string{"stale-network"}, Volumes: []string{"stale-volume"}},
+		},
+		{
+			name: "partial_failure", volumeCreatedAt: "yesterday",
+			removeErrors:       map[string]error{"stale": errRemoveMock},
+			expectedReport:     PruneReport{Containers: []string{"current"}, Networks: []string{"stale-network"}},
+			expectedFailed:     []string{"container stale (test Test_Stale)", "volume stale-volume"},
+			expectedError:      errPruneStale,
+			expectedRemoveCall: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			SetRunID(test.runID)
+			mockedContainerListValues = newContainerListMockValues(containerListMockValue{[]types.Container{
+				{ID: "stale", Created: stale.Unix(), Labels: map[string]string{labelManaged: "true", labelTest: "Test_Stale"}},
+				{ID: "fresh", Created: fresh.Unix()},
+				{ID: "current", Created: stale.Unix(), Labels: current},
+			}, nil})
+			mockedContainerRemoveErrors = test.removeErrors
+			mockedNetworkList = []types.NetworkResource{
+				{ID: "stale-network", Name: "stale", Created: stale},
+				{ID: "fresh-network", Name: "fresh", Created: fresh},
+			}
+			mockedVolumeList = []*volume.Volume{
+				{Name: "stale-volume", CreatedAt: test.volumeCreatedAt},
+				{Name: "fresh-volume", CreatedAt: fresh.Format(time.RFC3339)},
+			}
+
+			report, err := PruneStaleSessionResources(context.Background(), time.Hour, test.opts...)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedReport.Containers, report.Containers)
+			require.Equal(t, test.expectedReport.Networks, report.Networks)
+			require.Equal(t, test.expectedReport.Volumes, report.Volumes)
+			failed := make([]string, 0, len(report.Failed))
+			for description := range report.Failed {
+				failed = append(failed, description)
+			}
+			require.ElementsMatch(t, test.expectedFailed, failed)
+
+			require.Equal(t, []string{labelManaged + "=true"}, mockedContainerListOptions.Filters.Get("label"))
+			require.Equal(t, []string{labelManaged + "=true"}, mockedNetworkListFilters.Get("label"))
+			require.Equal(t, []string{labelManaged + "=true"}, mockedVolumeListFilters.Get("label"))
+			if test.expectedRemoveCall {
+				require.NotEmpty(t, mockedRemovedContainers)
+				require.Equal(t, []string{"stale-network"}, mockedRemovedNetworks)
+			} else {
+				require.Empty(t, mockedRemovedContainers)
+				require.Empty(t, mockedRemovedNetworks)
+				require.Empty(t, mockedRemovedVolumes)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package docker
+
+import (
+	"context"
+	"sync"
+
+	dockerClient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// PullPolicy defines when an image is pulled before a container is created.
+type PullPolicy string
+
+// Image pull policies.
+const (
+	// PullAlways pulls the image before each container creation. It is the default policy.
+	PullAlways PullPolicy = "always"
+	// PullMissing pulls the image only if it is not present locally.
+	PullMissing PullPolicy = "missing"
+	// PullNever never pulls the image, e.g. in air-gapped environments with pre-loaded images.
+	PullNever PullPolicy = "never"
+	// PullOnce pulls the image only if it has not been pulled earlier in the test process, e.g. in stacks where
+	// several containers share a base image. Pulls made with other policies, [PullImage], and [PrePull] count.
+	PullOnce PullPolicy = "once"
+)
+
+var errUnknownPullPolicy = errors.New("unknown pull policy")
+
+// pulledImage identifies an image pulled from a Docker daemon. Images are pulled for the daemon platform, so that
+// the daemon host identifies the platform as well.
+type pulledImage struct {
+	host, image string
+}
+
+var (
+	// pulledImages holds images pulled in the test process, guarded by pulledImagesMu.
+	pulledImages   = map[pulledImage]bool{}
+	pulledImagesMu sync.Mutex
+)
+
+// markPulled remembers that the given image has been pulled from the client daemon.
+func (c *defaultClient) markPulled(name string) {
+	pulledImagesMu.Lock()
+	defer pulledImagesMu.Unlock()
+	pulledImages[pulledImage{c.daemonHost(), name}] = true
+}
+
+// wasPulled reports whether the given image has been pulled from the client daemon earlier in the test process.
+func (c *defaultClient) wasPulled(name string) bool {
+	pulledImagesMu.Lock()
+	defer pulledImagesMu.Unlock()
+	return pulledImages[pulledImage{c.daemonHost(), name}]
+}
+
+// ensureImage pulls Docker image according to the given pull policy. Empty policy means [PullAlways].
+func (c *defaultClient) ensureImage(ctx context.Context, name string, policy PullPolicy) error {
+	switch policy {
+	case "", PullAlways:
+		return c.pullImage(ctx, name)
+	case PullNever:
+		return nil
+	case PullOnce:
+		if c.wasPulled(name) {
+			return nil
+		}
+		return c.pullImage(ctx, name)
+	case PullMissing:
+		_, _, err := c.handler.ImageInspectWithRaw(ctx, mirroredImage(name))
+		if dockerClient.IsErrNotFound(err) {
+			return c.pullImage(ctx, name)
+		}
+		return err
+	}
+	return errors.Wrap(errUnknownPullPolicy, string(policy))
+}
+
+// PullImageWithPolicy pulls a Docker image with the given name according to the given pull policy.
+func PullImageWithPolicy(ctx context.Context, name string, policy PullPolicy) error {
+	if len(name) == 0 {
+		return errEmptyImageName
+	}
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.ensureImage(ctx, name, policy)
+}
@@ -0,0 +1,81 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ensureImage(t *testing.T) {
+	tests := []struct {
+		name            string
+		policy          PullPolicy
+		imageNotFound   int
+		expectedPulls   int
+		expectedInspect int
+		expectedError   error
+	}{
+		{"default", "", 0, 1, 0, nil},
+		{"always", PullAlways, 0, 1, 0, nil},
+		{"never", PullNever, 1, 0, 0, nil},
+		{"missing_present", PullMissing, 0, 0, 1, nil},
+		{"missing_absent", PullMissing, 1, 1, 1, nil},
+		{"once", PullOnce, 0, 1, 0, nil},
+		{"unknown", PullPolicy("sometimes"), 0, 0, 0, errUnknownPullPolicy},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedImageInspectNotFound = test.imageNotFound
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			err := c.ensureImage(context.Background(), mockedImageName, test.policy)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedPulls, mockedImagePulls)
+			require.Equal(t, test.expectedInspect, mockedImageInspectCalls)
+		})
+	}
+}
+
+func Test_createContainerPullNever(t *testing.T) {
+	resetMocks()
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, PullPolicy: PullNever})
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, 0, mockedImagePulls)
+}
+
+func Test_createContainerPullOnce(t *testing.T) {
+	resetMocks()
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	for i := 0; i < 2; i++ {
+		c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, PullPolicy: PullOnce})
+		require.NoError(t, c.Create(context.Background()))
+	}
+	require.Equal(t, 1, mockedImagePulls)
+
+	// A pull made with another policy counts as a prior pull.
+	resetMocks()
+	require.NoError(t, PullImage(context.Background(), "alpine:3.17"))
+	c := NewContainerWithOptions("alpine:3.17", Options{Name: mockedContainerName, PullPolicy: PullOnce})
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, 1, mockedImagePulls)
+
+	// Images pulled from another daemon are pulled again.
+	mockedDaemonHost = "tcp://remote:2376"
+	c = NewContainerWithOptions("alpine:3.17", Options{Name: mockedContainerName, PullPolicy: PullOnce})
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, 2, mockedImagePulls)
+}
+
+func Test_createContainerPullsOnce(t *testing.T) {
+	resetMocks()
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	for _, policy := range []PullPolicy{"", PullAlways} {
+		mockedImagePulls = 0
+		c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName, PullPolicy: policy})
+		require.NoError(t, c.Create(context.Background()))
+		require.Equal(t, 1, mockedImagePulls, "policy %q", policy)
+	}
+}
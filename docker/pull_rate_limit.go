@@ -0,0 +1,235 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultPullRateWindow is the default pull rate limit window, the one of Docker Hub anonymous pull limits.
+	defaultPullRateWindow = 6 * time.Hour
+	// pullRateSleepStep is the longest time the limiter sleeps before checking context cancellation.
+	pullRateSleepStep = 5 * time.Second
+	// pullRateLockRetry is the interval between attempts to lock the counter file.
+	pullRateLockRetry = 10 * time.Millisecond
+	// pullRateLockStale is the age after which a counter file lock left by a crashed process is removed.
+	pullRateLockStale = 10 * time.Second
+)
+
+var (
+	// pullLimiter holds the pull rate limiter set using SetPullRateLimit, guarded by pullLimiterMu.
+	pullLimiter   *pullRateLimiter
+	pullLimiterMu sync.Mutex
+
+	// ErrRegistryRateLimited is returned if image registry rejects a pull because of its pull rate limit,
+	// e.g. Docker Hub `toomanyrequests` error. The error can be converted to [RegistryRateLimitedError]
+	// using errors.As to get the retry hint.
+	ErrRegistryRateLimited = errors.New("registry pull rate limit exceeded")
+
+	rateLimitedSignatures = []string{"toomanyrequests", "too many requests", "pull rate limit"}
+	retryAfterPattern     = regexp.MustCompile(`(?i)retry[- ]after[:= ]*(\d+)`)
+)
+
+// PullRateLimit holds client-side image pull rate limit configuration.
+type PullRateLimit struct {
+	// Pulls is the number of pulls allowed per Window. Zero disables rate limiting.
+	Pulls int
+	// Burst is the maximum number of pulls made without waiting. Defaults to Pulls. Lower values spread pulls
+	// evenly over the Window.
+	Burst int
+	// Window defaults to 6 hours, the Docker Hub pull limits window.
+	Window time.Duration
+	// CounterFile is the path to a file persisting the pull budget, so that it is shared by processes and test runs,
+	// e.g. parallel CI jobs on one host. If it is empty, the budget is kept in memory.
+	CounterFile string
+}
+
+// pullBucket holds token bucket state: the number of available pulls and the time it was updated.
+type pullBucket struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+}
+
+// pullRateLimiter is a token bucket limiting image pulls.
+type pullRateLimiter struct {
+	limit PullRateLimit
+	mu    sync.Mutex
+	// bucket holds the in-memory state used if no counter file is configured.
+	bucket pullBucket
+}
+
+// RegistryRateLimitedError holds the error of a pull rejected because of registry pull rate limit.
+// It matches [ErrRegistryRateLimited] with errors.Is.
+type RegistryRateLimitedError struct {
+	// RetryAfter is a hint on how long to wait before retrying the pull. It is zero if unknown.
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error returns error message with the retry hint.
+func (e *RegistryRateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s: %s; retry after %s", ErrRegistryRateLimited, e.Err, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s: %s", ErrRegistryRateLimited, e.Err)
+}
+
+// Is reports whether target is [ErrRegistryRateLimited].
+func (e *RegistryRateLimitedError) Is(target error) bool {
+	return target == ErrRegistryRateLimited
+}
+
+// Unwrap returns the original registry error.
+func (e *RegistryRateLimitedError) Unwrap() error {
+	return e.Err
+}
+
+// SetPullRateLimit enables client-side rate limiting of image pulls, e.g. to stay within Docker Hub anonymous pull
+// limits. Pulls exceeding the limit wait until the budget is replenished. Zero Pulls value disables rate limiting.
+func SetPullRateLimit(limit PullRateLimit) {
+	pullLimiterMu.Lock()
+	defer pullLimiterMu.Unlock()
+	if limit.Pulls <= 0 {
+		pullLimiter = nil
+		return
+	}
+	if limit.Window <= 0 {
+		limit.Window = defaultPullRateWindow
+	}
+	if limit.Burst <= 0 || limit.Burst > limit.Pulls {
+		limit.Burst = limit.Pulls
+	}
+	pullLimiter = &pullRateLimiter{limit: limit, bucket: pullBucket{Tokens: float64(limit.Burst), Updated: nowFn()}}
+}
+
+// getPullLimiter returns the pull rate limiter or nil if rate limiting is disabled.
+func getPullLimiter() *pullRateLimiter {
+	pullLimiterMu.Lock()
+	defer pullLimiterMu.Unlock()
+	return pullLimiter
+}
+
+// wait blocks until a pull is allowed by the limiter and consumes it.
+func (l *pullRateLimiter) wait(ctx context.Context) error {
+	for {
+		delay, err := l.take()
+		if err != nil || delay == 0 {
+			return err
+		}
+		for delay > 0 {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+			step := delay
+			if step > pullRateSleepStep {
+				step = pullRateSleepStep
+			}
+			sleepFn(step)
+			delay -= step
+		}
+	}
+}
+
+// take consumes a pull if it is available and returns zero, or returns the time until a pull becomes available.
+func (l *pullRateLimiter) take() (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.limit.CounterFile) == 0 {
+		return l.bucket.take(nowFn(), l.limit), nil
+	}
+	unlock, err := lockFile(l.limit.CounterFile + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	bucket := pullBucket{Tokens: float64(l.limit.Burst)}
+	data, err := os.ReadFile(l.limit.CounterFile)
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &bucket); err != nil {
+			return 0, errors.Wrapf(err, "invalid pull counter file %s", l.limit.CounterFile)
+		}
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+	delay := bucket.take(nowFn(), l.limit)
+	if data, err = json.Marshal(bucket); err != nil {
+		return 0, err
+	}
+	return delay, os.WriteFile(l.limit.CounterFile, data, 0o600)
+}
+
+// take replenishes the bucket according to the elapsed time and consumes a pull if it is available.
+// Otherwise, returns the time until a pull becomes available.
+func (b *pullBucket) take(now time.Time, limit PullRateLimit) time.Duration {
+	pulls, window := float64(limit.Pulls), float64(limit.Window)
+	if !b.Updated.IsZero() && now.After(b.Updated) {
+		b.Tokens = math.Min(float64(limit.Burst), b.Tokens+float64(now.Sub(b.Updated))*pulls/window)
+	}
+	if b.Updated.IsZero() || now.After(b.Updated) {
+		b.Updated = now
+	}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.Tokens) * window / pulls))
+}
+
+// lockFile creates the lock file, waiting while it exists. Stale lock files are removed.
+// Returns a function removing the lock file.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil // nolint: errcheck
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > pullRateLockStale {
+			os.Remove(path) // nolint: errcheck
+			continue
+		}
+		time.Sleep(pullRateLockRetry)
+	}
+}
+
+// isRateLimited reports whether the error is a registry pull rate limit error.
+func isRateLimited(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, signature := range rateLimitedSignatures {
+		if strings.Contains(message, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// registryRateLimitedError wraps a registry pull rate limit error into [RegistryRateLimitedError] or returns nil
+// if the error is not a rate limit one. The retry hint is parsed from the error message or, if it is missing,
+// estimated from the configured pull rate limit.
+func registryRateLimitedError(err error) error {
+	if err == nil || !isRateLimited(err) {
+		return nil
+	}
+	rateLimitedErr := &RegistryRateLimitedError{Err: err}
+	if match := retryAfterPattern.FindStringSubmatch(err.Error()); match != nil {
+		seconds, _ := strconv.Atoi(match[1])
+		rateLimitedErr.RetryAfter = time.Duration(seconds) * time.Second
+	} else if l := getPullLimiter(); l != nil {
+		rateLimitedErr.RetryAfter = l.limit.Window / time.Duration(l.limit.Pulls)
+	}
+	return rateLimitedErr
+}
@@ -0,0 +1,181 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pullRateLimiterBlocking(t *testing.T) {
+	defer useFakeClock()()
+	defer SetPullRateLimit(PullRateLimit{})
+	tests := []struct {
+		name           string
+		limit          PullRateLimit
+		pulls          int
+		expectedWaited time.Duration
+	}{
+		{"within_burst", PullRateLimit{Pulls: 6, Window: time.Hour}, 6, 0},
+		{"exceeding_budget", PullRateLimit{Pulls: 6, Window: time.Hour}, 8, 20 * time.Minute},
+		{"smoothed_burst", PullRateLimit{Pulls: 6, Burst: 1, Window: time.Hour}, 3, 20 * time.Minute},
+		{"default_window", PullRateLimit{Pulls: 100}, 101, 216 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetPullRateLimit(test.limit)
+			limiter := getPullLimiter()
+			start := nowFn()
+			for i := 0; i < test.pulls; i++ {
+				require.NoError(t, limiter.wait(context.Background()))
+			}
+			require.Equal(t, test.expectedWaited, nowFn().Sub(start))
+		})
+	}
+}
+
+func Test_pullRateLimiterCanceled(t *testing.T) {
+	defer useFakeClock()()
+	defer SetPullRateLimit(PullRateLimit{})
+	SetPullRateLimit(PullRateLimit{Pulls: 1, Window: time.Hour})
+	limiter := getPullLimiter()
+	require.NoError(t, limiter.wait(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, limiter.wait(ctx), context.Canceled)
+}
+
+func Test_pullRateLimiterCounterFile(t *testing.T) {
+	defer useFakeClock()()
+	defer SetPullRateLimit(PullRateLimit{})
+	limit := PullRateLimit{Pulls: 4, Window: time.Hour, CounterFile: filepath.Join(t.TempDir(), "pulls.json")}
+
+	// Limiters sharing the counter file, e.g. in different processes, share the budget.
+	SetPullRateLimit(limit)
+	first := getPullLimiter()
+	SetPullRateLimit(limit)
+	second := getPullLimiter()
+	for _, limiter := range []*pullRateLimiter{first, second, first, second} {
+		delay, err := limiter.take()
+		require.NoError(t, err)
+		require.Zero(t, delay)
+	}
+	delay, err := second.take()
+	require.NoError(t, err)
+	require.Equal(t, 15*time.Minute, delay)
+
+	// The budget persists and is replenished according to the elapsed time.
+	sleepFn(30 * time.Minute)
+	SetPullRateLimit(limit)
+	third := getPullLimiter()
+	for i := 0; i < 2; i++ {
+		delay, err = third.take()
+		require.NoError(t, err)
+		require.Zero(t, delay)
+	}
+	delay, err = third.take()
+	require.NoError(t, err)
+	require.Equal(t, 15*time.Minute, delay)
+}
+
+func Test_registryRateLimitedError(t *testing.T) {
+	defer SetPullRateLimit(PullRateLimit{})
+	tests := []struct {
+		name               string
+		message            string
+		limit              PullRateLimit
+		expectedLimited    bool
+		expectedRetryAfter time.Duration
+	}{
+		{
+			"docker_hub",
+			"toomanyrequests: You have reached your pull rate limit. You may increase the limit by authenticating and upgrading: " +
+				"https://www.docker.com/increase-rate-limit",
+			PullRateLimit{}, true, 0,
+		},
+		{
+			"docker_hub_with_limit",
+			"Error response from daemon: toomanyrequests: You have reached your pull rate limit.",
+			PullRateLimit{Pulls: 100}, true, 216 * time.Second,
+		},
+		{
+			"http_429_retry_after",
+			"unexpected status code 429 Too Many Requests, Retry-After: 120",
+			PullRateLimit{Pulls: 100}, true, 2 * time.Minute,
+		},
+		{"other_error", "manifest for postgres:99 not found: manifest unknown", PullRateLimit{}, false, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetPullRateLimit(test.limit)
+			err := registryRateLimitedError(errors.New(test.message))
+			if !test.expectedLimited {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrRegistryRateLimited)
+			var rateLimitedErr *RegistryRateLimitedError
+			require.ErrorAs(t, err, &rateLimitedErr)
+			require.Equal(t, test.expectedRetryAfter, rateLimitedErr.RetryAfter)
+			require.ErrorContains(t, err, test.message)
+		})
+	}
+}
+
+func Test_pullImageRateLimited(t *testing.T) {
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedImagePullStream = `{"status":"Pulling from library/postgres"}` + "\n" +
+		`{"errorDetail":{"message":"toomanyrequests: You have reached your pull rate limit."},` +
+		`"error":"toomanyrequests: You have reached your pull rate limit."}` + "\n"
+	err := c.pullImage(context.Background(), mockedImageName)
+	require.ErrorIs(t, err, ErrRegistryRateLimited)
+
+	resetMocks()
+	mockedImagePullError = errors.New("Error response from daemon: toomanyrequests: Too Many Requests. Retry-After: 60")
+	err = c.pullImage(context.Background(), mockedImageName)
+	var rateLimitedErr *RegistryRateLimitedError
+	require.ErrorAs(t, err, &rateLimitedErr)
+	require.Equal(t, time.Minute, rateLimitedErr.RetryAfter)
+}
+
+func Test_pullImageRateLimitedKeepsSlot(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	SetConcurrencyLimits(Limits{Pulls: 1})
+	defer SetConcurrencyLimits(defaultLimits)
+	SetPullRateLimit(PullRateLimit{Pulls: 1, Window: time.Hour})
+	defer SetPullRateLimit(PullRateLimit{})
+	require.NoError(t, getPullLimiter().wait(context.Background()))
+
+	// The pull below waits for a rate limit token until canceled.
+	sleeping, wake := make(chan struct{}, 1), make(chan struct{})
+	sleepFn = func(time.Duration) {
+		select {
+		case sleeping <- struct{}{}:
+		default:
+		}
+		<-wake
+	}
+	defer func() { sleepFn = time.Sleep }()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.pullImage(ctx, mockedImageName) }()
+	<-sleeping
+
+	acquireCtx, acquireCancel := context.WithTimeout(context.Background(), time.Second)
+	defer acquireCancel()
+	require.NoError(t, getLimiter().pulls.acquire(acquireCtx))
+	getLimiter().pulls.release()
+
+	cancel()
+	close(wake)
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Equal(t, 0, mockedImagePulls)
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"sync"
+
+	dockerClient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+var (
+	errClientClosed         = errors.New("client is closed")
+	errRawClientUnavailable = errors.New("raw Docker client is not available while recording, replaying, or transcribing")
+)
+
+// Client gives access to the Docker SDK client used by the package, e.g. to call an API the package does not wrap
+// without constructing a second SDK client with different options.
+type Client struct {
+	// mu guards closed. It is read-locked while a function passed to WithRaw runs, so that Close waits for it.
+	mu      sync.RWMutex
+	handler dockerClient.CommonAPIClient
+	closed  bool
+}
+
+// NewClient returns a Client sharing the Docker SDK client used by the package functions. Returns an error if
+// package calls are currently recorded, replayed, or transcribed, as the SDK client is not used directly then.
+func NewClient() (*Client, error) {
+	c, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := c.(*defaultClient)
+	if !ok {
+		return nil, errRawClientUnavailable
+	}
+	return &Client{handler: d.handler}, nil
+}
+
+// Raw returns the Docker SDK client. The client is borrowed: it is shared with the package, must not be closed,
+// and must not be used after [Client.Close]. Prefer [Client.WithRaw] which enforces the latter.
+func (c *Client) Raw() dockerClient.CommonAPIClient {
+	return c.handler
+}
+
+// WithRaw calls fn with the Docker SDK client and returns its error. Returns an error without calling fn if the
+// client is closed. Close waits for fn to return. The client passed to fn must not be retained after it returns.
+func (c *Client) WithRaw(fn func(api dockerClient.CommonAPIClient) error) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.closed {
+		return errClientClosed
+	}
+	return fn(c.handler)
+}
+
+// Close marks the client closed and closes its idle connections. The Docker SDK client stays usable by the package.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.handler.Close()
+}
@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	dockerClient "github.com/docker/docker/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ClientRaw(t *testing.T) {
+	handler := &mockedDockerClient{}
+	cli = &defaultClient{handler: handler}
+	c, err := NewClient()
+	require.NoError(t, err)
+	require.Same(t, handler, c.Raw())
+
+	errMock := errors.New("mockedRawError")
+	var passed dockerClient.CommonAPIClient
+	err = c.WithRaw(func(api dockerClient.CommonAPIClient) error {
+		passed = api
+		return errMock
+	})
+	require.ErrorIs(t, err, errMock)
+	require.Same(t, handler, passed)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	called := false
+	err = c.WithRaw(func(dockerClient.CommonAPIClient) error {
+		called = true
+		return nil
+	})
+	require.ErrorIs(t, err, errClientClosed)
+	require.False(t, called)
+}
+
+func Test_NewClientUnavailable(t *testing.T) {
+	cli = &transcriptClient{client: &defaultClient{handler: &mockedDockerClient{}}}
+	defer func() { cli = &defaultClient{handler: &mockedDockerClient{}} }()
+	_, err := NewClient()
+	require.ErrorIs(t, err, errRawClientUnavailable)
+}
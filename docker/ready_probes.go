@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Database wire protocol readiness probes which can be set in [Database.ReadyProbe].
+const (
+	ReadyProbePostgres = "postgres"
+	ReadyProbeRedis    = "redis"
+	ReadyProbeMySQL    = "mysql"
+)
+
+// readyProbes holds database wire protocol readiness probes by name. A probe returns nil if the database
+// on the other side of the given connection accepts client connections.
+var readyProbes = map[string]func(conn net.Conn) error{
+	ReadyProbePostgres: probePostgres,
+	ReadyProbeRedis:    probeRedis,
+	ReadyProbeMySQL:    probeMySQL,
+}
+
+var (
+	errReadyProbeNotSupported = errors.New("unsupported database ready probe")
+	errDatabaseNotReady       = errors.New("database is not ready")
+	errUnexpectedResponse     = errors.New("unexpected database response")
+)
+
+// WaitReadyTCP repeatedly probes the database over its wire protocol on the host port bound to Port until
+// the database accepts connections or the timeout expires. The protocol is selected with ReadyProbe.
+// It does not require database client binaries in the container image.
+func (dc *databaseContainer) WaitReadyTCP(ctx context.Context, timeout time.Duration) error {
+	probe, ok := readyProbes[dc.database.ReadyProbe]
+	if !ok {
+		return errors.Wrapf(errReadyProbeNotSupported, "%q", dc.database.ReadyProbe)
+	}
+	if len(dc.database.Port) == 0 {
+		return errPortNotSet
+	}
+	endpoint, release := dc.probeEndpoint(dc.database.Port)
+	defer release()
+	return dc.waitProbe(ctx, timeout, func(ctx context.Context) error {
+		address, err := endpoint(ctx)
+		if err != nil {
+			return err
+		}
+		conn, err := net.DialTimeout("tcp", address, readyDialTimeout)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if err = conn.SetDeadline(time.Now().Add(readyDialTimeout)); err != nil {
+			return err
+		}
+		return probe(conn)
+	})
+}
+
+// postgresCannotConnectNow is PostgreSQL error code returned while the server is starting up or shutting down.
+const postgresCannotConnectNow = "57P03"
+
+// probePostgres sends PostgreSQL startup message and reads the server response. Authentication request or any error
+// other than `cannot_connect_now`, e.g. a missing role, means that the server accepts connections.
+func probePostgres(conn net.Conn) error {
+	params := "user\x00testutils\x00database\x00testutils\x00\x00"
+	startup := make([]byte, 8, 8+len(params))
+	binary.BigEndian.PutUint32(startup[0:4], uint32(8+len(params)))
+	binary.BigEndian.PutUint32(startup[4:8], 3<<16) // protocol version 3.0
+	if _, err := conn.Write(append(startup, params...)); err != nil {
+		return err
+	}
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	switch header[0] {
+	case 'R':
+		return nil
+	case 'E':
+		length := binary.BigEndian.Uint32(header[1:5])
+		if length < 4 || length > 1<<16 {
+			return errors.Wrapf(errUnexpectedResponse, "error message length %d", length)
+		}
+		body := make([]byte, length-4)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return err
+		}
+		fields := postgresErrorFields(body)
+		if fields['C'] == postgresCannotConnectNow {
+			return errors.Wrap(errDatabaseNotReady, fields['M'])
+		}
+		return nil
+	default:
+		return errors.Wrapf(errUnexpectedResponse, "message type %q", header[0])
+	}
+}
+
+// postgresErrorFields parses PostgreSQL ErrorResponse message body into a map of field values by field type.
+func postgresErrorFields(body []byte) map[byte]string {
+	fields := map[byte]string{}
+	for len(body) > 1 && body[0] != 0 {
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			break
+		}
+		fields[body[0]] = string(body[1 : end+1])
+		body = body[end+2:]
+	}
+	return fields
+}
+
+// probeRedis sends Redis PING command and reads the reply. PONG or an authentication error means that the server
+// accepts connections.
+func probeRedis(conn net.Conn) error {
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSpace(line)
+	switch {
+	case line == "+PONG", strings.HasPrefix(line, "-NOAUTH"), strings.HasPrefix(line, "-WRONGPASS"):
+		return nil
+	case strings.HasPrefix(line, "-"):
+		return errors.Wrap(errDatabaseNotReady, strings.TrimPrefix(line, "-"))
+	default:
+		return errors.Wrapf(errUnexpectedResponse, "%q", line)
+	}
+}
+
+// mysqlHandshakeV10 is the protocol version of MySQL initial handshake packet.
+const mysqlHandshakeV10 = 0x0a
+
+// probeMySQL reads MySQL initial handshake packet sent by the server on connection.
+func probeMySQL(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 {
+		return errors.Wrap(errUnexpectedResponse, "empty packet")
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+	switch payload[0] {
+	case mysqlHandshakeV10:
+		return nil
+	case 0xff:
+		return errors.Wrap(errDatabaseNotReady, mysqlErrorMessage(payload))
+	default:
+		return errors.Wrapf(errUnexpectedResponse, "protocol version %d", payload[0])
+	}
+}
+
+// mysqlErrorMessage returns the message of MySQL error packet payload.
+func mysqlErrorMessage(payload []byte) string {
+	if len(payload) < 3 {
+		return ""
+	}
+	message := payload[3:]
+	if len(message) >= 6 && message[0] == '#' {
+		message = message[6:]
+	}
+	return string(message)
+}
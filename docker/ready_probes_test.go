@@ -0,0 +1,155 @@
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer starts a TCP listener serving each connection with the given handler. Returns the listener address.
+func fakeServer(t *testing.T, handler func(conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handler(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// postgresMessage returns PostgreSQL backend message of the given type with the given body.
+func postgresMessage(messageType byte, body string) []byte {
+	message := []byte{messageType, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(message[1:], uint32(4+len(body)))
+	return append(message, body...)
+}
+
+// fakePostgres returns a fake PostgreSQL server handler reading the startup message and replying with the given one.
+func fakePostgres(reply []byte) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		if binary.BigEndian.Uint32(header[4:]) != 3<<16 {
+			return
+		}
+		if _, err := io.ReadFull(conn, make([]byte, binary.BigEndian.Uint32(header[:4])-8)); err != nil {
+			return
+		}
+		conn.Write(reply) // nolint: errcheck
+	}
+}
+
+// mysqlPacket returns MySQL packet with the given payload.
+func mysqlPacket(payload string) []byte {
+	return append([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0}, payload...)
+}
+
+func Test_readyProbes(t *testing.T) {
+	tests := []struct {
+		name          string
+		probe         func(conn net.Conn) error
+		handler       func(conn net.Conn)
+		expectedError error
+		expectedText  string
+	}{
+		{"postgres_auth_request", probePostgres, fakePostgres(postgresMessage('R', "\x00\x00\x00\x05salt")), nil, ""},
+		{"postgres_role_missing", probePostgres, fakePostgres(postgresMessage('E',
+			"SFATAL\x00C28000\x00Mrole \"testutils\" does not exist\x00\x00")), nil, ""},
+		{"postgres_starting_up", probePostgres, fakePostgres(postgresMessage('E',
+			"SFATAL\x00C57P03\x00Mthe database system is starting up\x00\x00")), errDatabaseNotReady, "starting up"},
+		{"postgres_unexpected", probePostgres, fakePostgres([]byte("HTTP/1.1 400")), errUnexpectedResponse, ""},
+		{"redis_pong", probeRedis, func(conn net.Conn) {
+			readLine(conn)
+			conn.Write([]byte("+PONG\r\n")) // nolint: errcheck
+		}, nil, ""},
+		{"redis_noauth", probeRedis, func(conn net.Conn) {
+			readLine(conn)
+			conn.Write([]byte("-NOAUTH Authentication required.\r\n")) // nolint: errcheck
+		}, nil, ""},
+		{"redis_loading", probeRedis, func(conn net.Conn) {
+			readLine(conn)
+			conn.Write([]byte("-LOADING Redis is loading the dataset in memory\r\n")) // nolint: errcheck
+		}, errDatabaseNotReady, "LOADING"},
+		{"mysql_handshake", probeMySQL, func(conn net.Conn) {
+			conn.Write(mysqlPacket("\x0a8.0.32\x00")) // nolint: errcheck
+		}, nil, ""},
+		{"mysql_error", probeMySQL, func(conn net.Conn) {
+			conn.Write(mysqlPacket("\xff\x10\x04#08004Too many connections")) // nolint: errcheck
+		}, errDatabaseNotReady, "Too many connections"},
+		{"mysql_closed", probeMySQL, func(conn net.Conn) {}, io.EOF, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, err := net.DialTimeout("tcp", fakeServer(t, test.handler), time.Second)
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, conn.SetDeadline(time.Now().Add(time.Second)))
+			err = test.probe(conn)
+			require.ErrorIs(t, err, test.expectedError)
+			if err != nil {
+				require.Contains(t, err.Error(), test.expectedText)
+			}
+		})
+	}
+}
+
+// readLine reads one line sent by the client.
+func readLine(conn net.Conn) {
+	buffer := make([]byte, 1)
+	for {
+		if _, err := conn.Read(buffer); err != nil || buffer[0] == '\n' {
+			return
+		}
+	}
+}
+
+func Test_WaitReadyTCP(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	var attempts int32
+	address := fakeServer(t, func(conn net.Conn) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			fakePostgres(postgresMessage('E', "C57P03\x00Mthe database system is starting up\x00\x00"))(conn)
+			return
+		}
+		fakePostgres(postgresMessage('R', "\x00\x00\x00\x00"))(conn)
+	})
+	tests := []struct {
+		name          string
+		database      Database
+		expectedError error
+	}{
+		{"ready_after_start_up", Database{Port: "5432", ReadyProbe: ReadyProbePostgres}, nil},
+		{"unknown_probe", Database{Port: "5432", ReadyProbe: "oracle"}, errReadyProbeNotSupported},
+		{"port_not_set", Database{ReadyProbe: ReadyProbePostgres}, errPortNotSet},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerInspect = mockedPostgresInspect(address)
+			dc := NewDatabaseContainerWithOptions(mockedImageName, test.database, Options{Name: mockedContainerName})
+			require.ErrorIs(t, dc.WaitReadyTCP(context.Background(), time.Second*10), test.expectedError)
+		})
+	}
+	require.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
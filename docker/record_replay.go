@@ -0,0 +1,226 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// goldenFormatVersion is the version of the golden file format written by recording. Golden files with other
+// versions are rejected by replay.
+const goldenFormatVersion = 1
+
+var (
+	errGoldenFileVersion = errors.New("unsupported golden file version")
+	errReplayMismatch    = errors.New("replayed Docker client call mismatch")
+	errReplayExhausted   = errors.New("no more recorded Docker client calls")
+	errReplayIncomplete  = errors.New("recorded Docker client calls were not replayed")
+
+	// replayedErrors holds package errors which are restored from their messages on replay, so that the code
+	// comparing errors behaves as during recording.
+	replayedErrors = []error{errContainerNotFound, errEmptyContainerNameAndID, errEmptyImageName}
+
+	// looseDigits matches digit runs ignored in replayed call arguments comparison, e.g. in process ids and
+	// host ports which differ between runs.
+	looseDigits = regexp.MustCompile(`[0-9]+`)
+)
+
+// goldenFile holds recorded Docker client interactions. Fields are never removed or renamed, new ones are added
+// as optional, so that golden files stay readable by newer versions of the package.
+type goldenFile struct {
+	Version int `json:"version"`
+	// DaemonHost holds Docker daemon host address the recording client was connected to.
+	DaemonHost   string        `json:"daemonHost,omitempty"`
+	Interactions []interaction `json:"interactions"`
+}
+
+// interaction holds a recorded Docker client call: method name, arguments summary, JSON-encoded result,
+// and error message.
+type interaction struct {
+	Method string          `json:"method"`
+	Args   []string        `json:"args,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// String returns the interaction call summary, e.g. `createContainer("postgres", "db")`.
+func (i interaction) String() string {
+	args := make([]string, 0, len(i.Args))
+	for _, arg := range i.Args {
+		args = append(args, fmt.Sprintf("%q", arg))
+	}
+	return i.Method + "(" + strings.Join(args, ", ") + ")"
+}
+
+// matches reports whether the call matches the recorded one. Methods must be equal, arguments are compared
+// ignoring digits.
+func (i interaction) matches(call interaction) bool {
+	if i.Method != call.Method || len(i.Args) != len(call.Args) {
+		return false
+	}
+	for n := range i.Args {
+		if looseDigits.ReplaceAllString(i.Args[n], "#") != looseDigits.ReplaceAllString(call.Args[n], "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// replayedError returns an error with the recorded message. Package errors are restored as is.
+func replayedError(message string) error {
+	if len(message) == 0 {
+		return nil
+	}
+	for _, err := range replayedErrors {
+		if err.Error() == message {
+			return err
+		}
+	}
+	return errors.New(message)
+}
+
+// StartRecording makes the package record Docker client calls and their results, e.g. container ids, list
+// summaries, inspect payloads, and exec outputs, so that they can be served back by [StartReplay] without
+// Docker daemon. The returned function stops recording and writes the calls to the golden file at path.
+func StartRecording(path string) (func() error, error) {
+	c, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	recorder := &recordingClient{next: c}
+	cli = recorder
+	return func() error {
+		cli = recorder.next
+		return recorder.write(path)
+	}, nil
+}
+
+// StartReplay makes the package serve Docker client calls from the golden file at path written by
+// [StartRecording], without Docker daemon. Calls must be made in the recorded order with the recorded arguments,
+// digits in arguments are ignored, e.g. in generated names and host ports. A mismatching call fails with an error
+// describing the expected and the actual call. The returned function restores the previous client and returns
+// an error if some of the recorded calls have not been replayed.
+func StartReplay(path string) (func() error, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var golden goldenFile
+	if err = json.Unmarshal(data, &golden); err != nil {
+		return nil, errors.Wrap(err, path)
+	}
+	if golden.Version != goldenFormatVersion {
+		return nil, errors.Wrapf(errGoldenFileVersion, "%s: %d", path, golden.Version)
+	}
+	previous := cli
+	replayer := &replayClient{path: path, host: golden.DaemonHost, interactions: golden.Interactions}
+	cli = replayer
+	return func() error {
+		cli = previous
+		return replayer.remaining()
+	}, nil
+}
+
+// recordingClient passes calls to the next client and records them. Implements client interface.
+type recordingClient struct {
+	next         client
+	mu           sync.Mutex
+	interactions []interaction
+}
+
+// record appends a call with its result and error to the recorded ones.
+func (r *recordingClient) record(method string, result any, callErr error, args ...string) {
+	recorded := interaction{Method: method, Args: args}
+	if callErr != nil {
+		recorded.Error = callErr.Error()
+	} else if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			recorded.Error = err.Error()
+		}
+		recorded.Result = data
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, recorded)
+}
+
+// write writes the recorded calls to the golden file at path.
+func (r *recordingClient) write(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	golden := goldenFile{Version: goldenFormatVersion, DaemonHost: r.next.daemonHost(), Interactions: r.interactions}
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// recordCall records a call returning a result and passes the result through.
+func recordCall[T any](r *recordingClient, method string, result T, err error, args ...string) (T, error) {
+	r.record(method, result, err, args...)
+	return result, err
+}
+
+// replayClient serves recorded calls. Implements client interface.
+type replayClient struct {
+	path, host   string
+	mu           sync.Mutex
+	interactions []interaction
+	replayed     int
+}
+
+// next returns the next recorded call if it matches the given one.
+func (r *replayClient) next(method string, args ...string) (interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	call := interaction{Method: method, Args: args}
+	if r.replayed >= len(r.interactions) {
+		return interaction{}, errors.Wrapf(errReplayExhausted, "%s: call %d: %s", r.path, r.replayed+1, call)
+	}
+	recorded := r.interactions[r.replayed]
+	if !recorded.matches(call) {
+		return interaction{}, errors.Wrapf(errReplayMismatch, "%s: call %d: expected %s, got %s",
+			r.path, r.replayed+1, recorded, call)
+	}
+	r.replayed++
+	return recorded, nil
+}
+
+// remaining returns an error listing recorded calls which have not been replayed, if any.
+func (r *replayClient) remaining() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.replayed == len(r.interactions) {
+		return nil
+	}
+	calls := make([]string, 0, len(r.interactions)-r.replayed)
+	for _, recorded := range r.interactions[r.replayed:] {
+		calls = append(calls, recorded.String())
+	}
+	return errors.Wrapf(errReplayIncomplete, "%s: %s", r.path, strings.Join(calls, ", "))
+}
+
+// replayCall serves the next recorded call decoding its result.
+func replayCall[T any](r *replayClient, method string, args ...string) (T, error) {
+	var result T
+	recorded, err := r.next(method, args...)
+	if err != nil {
+		return result, err
+	}
+	if len(recorded.Error) > 0 {
+		return result, replayedError(recorded.Error)
+	}
+	if len(recorded.Result) > 0 {
+		if err = json.Unmarshal(recorded.Result, &result); err != nil {
+			return result, errors.Wrapf(err, "%s: %s", r.path, recorded)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,447 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+)
+
+// optionsName returns the container name set in options, if any.
+func optionsName(options *Options) string {
+	if options == nil {
+		return ""
+	}
+	return options.Name
+}
+
+// filtersSummary returns the JSON representation of list filters used as a recorded call argument.
+func filtersSummary(filters dockerContainerFilters.Args) string {
+	summary, err := dockerContainerFilters.ToJSON(filters)
+	if err != nil {
+		return ""
+	}
+	return summary
+}
+
+// recordedContainer holds the id and the name of a created container. The name is recorded, so that the one assigned
+// by Docker daemon to an unnamed container is written back into options on replay.
+type recordedContainer struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// restoreName sets the given recorded container name in options, unless the name is set already.
+func restoreName(options *Options, name string) {
+	if options != nil && len(options.Name) == 0 {
+		options.Name = name
+	}
+}
+
+// recordedContainerData holds container data set by fetchContainerData.
+type recordedContainerData struct {
+	ID     string `json:"id"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+}
+
+func (r *recordingClient) pullImage(ctx context.Context, name string) error {
+	err := r.next.pullImage(ctx, name)
+	r.record("pullImage", nil, err, name)
+	return err
+}
+
+func (r *recordingClient) ensureImage(ctx context.Context, name string, policy PullPolicy) error {
+	err := r.next.ensureImage(ctx, name, policy)
+	r.record("ensureImage", nil, err, name, string(policy))
+	return err
+}
+
+func (r *recordingClient) createContainer(ctx context.Context, image string, options *Options) (string, error) {
+	// The name is read before the call, as the one assigned by Docker daemon is saved in options.
+	name := optionsName(options)
+	id, err := r.next.createContainer(ctx, image, options)
+	r.record("createContainer", recordedContainer{ID: id, Name: optionsName(options)}, err, image, name)
+	return id, err
+}
+
+func (r *recordingClient) startContainer(ctx context.Context, id string) error {
+	err := r.next.startContainer(ctx, id)
+	r.record("startContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) createStartContainer(ctx context.Context, image string, options *Options) (string, error) {
+	name := optionsName(options)
+	id, err := r.next.createStartContainer(ctx, image, options)
+	r.record("createStartContainer", recordedContainer{ID: id, Name: optionsName(options)}, err, image, name)
+	return id, err
+}
+
+func (r *recordingClient) createStartContainerInfo(ctx context.Context, image string, options *Options) (ContainerInfo, error) {
+	name := optionsName(options)
+	info, err := r.next.createStartContainerInfo(ctx, image, options)
+	return recordCall(r, "createStartContainerInfo", info, err, image, name)
+}
+
+func (r *recordingClient) fetchContainerData(ctx context.Context, container *container) error {
+	name, id := container.options.Name, container.id
+	err := r.next.fetchContainerData(ctx, container)
+	data := recordedContainerData{ID: container.id, State: container.state, Status: container.status}
+	r.record("fetchContainerData", data, err, name, id)
+	return err
+}
+
+func (r *recordingClient) stopContainer(ctx context.Context, id string, timeout *int) error {
+	err := r.next.stopContainer(ctx, id, timeout)
+	r.record("stopContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) removeContainer(ctx context.Context, id string) error {
+	err := r.next.removeContainer(ctx, id)
+	r.record("removeContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) stopRemoveContainer(ctx context.Context, id string, timeout *int) error {
+	err := r.next.stopRemoveContainer(ctx, id, timeout)
+	r.record("stopRemoveContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) execCommand(ctx context.Context, id string, command string, buffer *bytes.Buffer) error {
+	written := buffer.Len()
+	err := r.next.execCommand(ctx, id, command, buffer)
+	r.record("execCommand", buffer.String()[written:], err, id, command)
+	return err
+}
+
+func (r *recordingClient) execWithResult(
+	ctx context.Context,
+	id string,
+	config types.ExecConfig,
+	stdin io.Reader,
+) (ExecResult, error) {
+	result, err := r.next.execWithResult(ctx, id, config, stdin)
+	return recordCall(r, "execWithResult", result, err, id, strings.Join(config.Cmd, " "))
+}
+
+func (r *recordingClient) inspectContainer(ctx context.Context, id string) (types.ContainerJSON, error) {
+	data, err := r.next.inspectContainer(ctx, id)
+	return recordCall(r, "inspectContainer", data, err, id)
+}
+
+func (r *recordingClient) updateContainer(ctx context.Context, id string, resources dockerContainer.Resources) error {
+	err := r.next.updateContainer(ctx, id, resources)
+	r.record("updateContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) containerLogs(ctx context.Context, id string, options types.ContainerLogsOptions) (string, error) {
+	logs, err := r.next.containerLogs(ctx, id, options)
+	return recordCall(r, "containerLogs", logs, err, id)
+}
+
+func (r *recordingClient) streamLogs(
+	ctx context.Context,
+	id string,
+	options types.ContainerLogsOptions,
+	dst io.Writer,
+) error {
+	streamed := bytes.Buffer{}
+	err := r.next.streamLogs(ctx, id, options, io.MultiWriter(dst, &streamed))
+	r.record("streamLogs", streamed.Bytes(), err, id)
+	return err
+}
+
+func (r *recordingClient) containerTop(ctx context.Context, id string, psArgs string) ([][]string, error) {
+	processes, err := r.next.containerTop(ctx, id, psArgs)
+	return recordCall(r, "containerTop", processes, err, id, psArgs)
+}
+
+func (r *recordingClient) pathExists(ctx context.Context, id, path string) (bool, error) {
+	exists, err := r.next.pathExists(ctx, id, path)
+	return recordCall(r, "pathExists", exists, err, id, path)
+}
+
+func (r *recordingClient) hostGateway(ctx context.Context) (string, error) {
+	address, err := r.next.hostGateway(ctx)
+	return recordCall(r, "hostGateway", address, err)
+}
+
+func (r *recordingClient) exportContainer(ctx context.Context, id string, dst io.Writer) error {
+	exported := bytes.Buffer{}
+	err := r.next.exportContainer(ctx, id, io.MultiWriter(dst, &exported))
+	r.record("exportContainer", exported.Bytes(), err, id)
+	return err
+}
+
+func (r *recordingClient) daemonHost() string {
+	return r.next.daemonHost()
+}
+
+func (r *recordingClient) listContainers(ctx context.Context, filters dockerContainerFilters.Args) ([]types.Container, error) {
+	containers, err := r.next.listContainers(ctx, filters)
+	return recordCall(r, "listContainers", containers, err, filtersSummary(filters))
+}
+
+func (r *recordingClient) listNetworks(ctx context.Context, filters dockerContainerFilters.Args) ([]types.NetworkResource, error) {
+	networks, err := r.next.listNetworks(ctx, filters)
+	return recordCall(r, "listNetworks", networks, err, filtersSummary(filters))
+}
+
+func (r *recordingClient) listVolumes(ctx context.Context, filters dockerContainerFilters.Args) ([]*volume.Volume, error) {
+	volumes, err := r.next.listVolumes(ctx, filters)
+	return recordCall(r, "listVolumes", volumes, err, filtersSummary(filters))
+}
+
+func (r *recordingClient) forceRemoveContainer(ctx context.Context, id string) error {
+	err := r.next.forceRemoveContainer(ctx, id)
+	r.record("forceRemoveContainer", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) createNetwork(ctx context.Context, name string, options NetworkOptions) (string, error) {
+	id, err := r.next.createNetwork(ctx, name, options)
+	return recordCall(r, "createNetwork", id, err, name)
+}
+
+func (r *recordingClient) removeNetwork(ctx context.Context, id string) error {
+	err := r.next.removeNetwork(ctx, id)
+	r.record("removeNetwork", nil, err, id)
+	return err
+}
+
+func (r *recordingClient) ensureVolume(ctx context.Context, name string, labels map[string]string) (bool, error) {
+	created, err := r.next.ensureVolume(ctx, name, labels)
+	return recordCall(r, "ensureVolume", created, err, name)
+}
+
+func (r *recordingClient) removeVolume(ctx context.Context, name string) error {
+	err := r.next.removeVolume(ctx, name)
+	r.record("removeVolume", nil, err, name)
+	return err
+}
+
+func (r *recordingClient) pruneVolumes(ctx context.Context) error {
+	err := r.next.pruneVolumes(ctx)
+	r.record("pruneVolumes", nil, err)
+	return err
+}
+
+func (r *recordingClient) connectNetwork(ctx context.Context, network, containerID string, aliases []string) error {
+	err := r.next.connectNetwork(ctx, network, containerID, aliases)
+	r.record("connectNetwork", nil, err, network, containerID)
+	return err
+}
+
+func (r *recordingClient) commitContainer(ctx context.Context, id string) (string, error) {
+	imageID, err := r.next.commitContainer(ctx, id)
+	return recordCall(r, "commitContainer", imageID, err, id)
+}
+
+func (r *recordingClient) tagImage(ctx context.Context, source, target string) error {
+	err := r.next.tagImage(ctx, source, target)
+	r.record("tagImage", nil, err, source, target)
+	return err
+}
+
+func (r *recordingClient) pushImage(ctx context.Context, ref string) error {
+	err := r.next.pushImage(ctx, ref)
+	r.record("pushImage", nil, err, ref)
+	return err
+}
+
+func (r *recordingClient) imageMetadata(ctx context.Context, ref string) (ImageInfo, error) {
+	info, err := r.next.imageMetadata(ctx, ref)
+	return recordCall(r, "imageMetadata", info, err, ref)
+}
+
+func (r *recordingClient) close() {
+	r.next.close()
+}
+
+func (r *replayClient) pullImage(_ context.Context, name string) error {
+	_, err := replayCall[struct{}](r, "pullImage", name)
+	return err
+}
+
+func (r *replayClient) ensureImage(_ context.Context, name string, policy PullPolicy) error {
+	_, err := replayCall[struct{}](r, "ensureImage", name, string(policy))
+	return err
+}
+
+func (r *replayClient) createContainer(_ context.Context, image string, options *Options) (string, error) {
+	created, err := replayCall[recordedContainer](r, "createContainer", image, optionsName(options))
+	restoreName(options, created.Name)
+	return created.ID, err
+}
+
+func (r *replayClient) startContainer(_ context.Context, id string) error {
+	_, err := replayCall[struct{}](r, "startContainer", id)
+	return err
+}
+
+func (r *replayClient) createStartContainer(_ context.Context, image string, options *Options) (string, error) {
+	created, err := replayCall[recordedContainer](r, "createStartContainer", image, optionsName(options))
+	restoreName(options, created.Name)
+	return created.ID, err
+}
+
+func (r *replayClient) createStartContainerInfo(_ context.Context, image string, options *Options) (ContainerInfo, error) {
+	info, err := replayCall[ContainerInfo](r, "createStartContainerInfo", image, optionsName(options))
+	restoreName(options, info.Name)
+	return info, err
+}
+
+func (r *replayClient) fetchContainerData(_ context.Context, container *container) error {
+	data, err := replayCall[recordedContainerData](r, "fetchContainerData", container.options.Name, container.id)
+	if err != nil {
+		return err
+	}
+	container.id, container.state, container.status = data.ID, data.State, data.Status
+	return nil
+}
+
+func (r *replayClient) stopContainer(_ context.Context, id string, _ *int) error {
+	_, err := replayCall[struct{}](r, "stopContainer", id)
+	return err
+}
+
+func (r *replayClient) removeContainer(_ context.Context, id string) error {
+	_, err := replayCall[struct{}](r, "removeContainer", id)
+	return err
+}
+
+func (r *replayClient) stopRemoveContainer(_ context.Context, id string, _ *int) error {
+	_, err := replayCall[struct{}](r, "stopRemoveContainer", id)
+	return err
+}
+
+func (r *replayClient) execCommand(_ context.Context, id string, command string, buffer *bytes.Buffer) error {
+	output, err := replayCall[string](r, "execCommand", id, command)
+	buffer.WriteString(output)
+	return err
+}
+
+func (r *replayClient) execWithResult(_ context.Context, id string, config types.ExecConfig, _ io.Reader) (ExecResult, error) {
+	return replayCall[ExecResult](r, "execWithResult", id, strings.Join(config.Cmd, " "))
+}
+
+func (r *replayClient) inspectContainer(_ context.Context, id string) (types.ContainerJSON, error) {
+	return replayCall[types.ContainerJSON](r, "inspectContainer", id)
+}
+
+func (r *replayClient) updateContainer(_ context.Context, id string, _ dockerContainer.Resources) error {
+	_, err := replayCall[struct{}](r, "updateContainer", id)
+	return err
+}
+
+func (r *replayClient) containerLogs(_ context.Context, id string, _ types.ContainerLogsOptions) (string, error) {
+	return replayCall[string](r, "containerLogs", id)
+}
+
+func (r *replayClient) containerTop(_ context.Context, id string, psArgs string) ([][]string, error) {
+	return replayCall[[][]string](r, "containerTop", id, psArgs)
+}
+
+func (r *replayClient) pathExists(_ context.Context, id, path string) (bool, error) {
+	return replayCall[bool](r, "pathExists", id, path)
+}
+
+func (r *replayClient) streamLogs(_ context.Context, id string, _ types.ContainerLogsOptions, dst io.Writer) error {
+	streamed, err := replayCall[[]byte](r, "streamLogs", id)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(streamed)
+	return err
+}
+
+func (r *replayClient) hostGateway(_ context.Context) (string, error) {
+	return replayCall[string](r, "hostGateway")
+}
+
+func (r *replayClient) exportContainer(_ context.Context, id string, dst io.Writer) error {
+	exported, err := replayCall[[]byte](r, "exportContainer", id)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(exported)
+	return err
+}
+
+func (r *replayClient) daemonHost() string {
+	return r.host
+}
+
+func (r *replayClient) listContainers(_ context.Context, filters dockerContainerFilters.Args) ([]types.Container, error) {
+	return replayCall[[]types.Container](r, "listContainers", filtersSummary(filters))
+}
+
+func (r *replayClient) listNetworks(_ context.Context, filters dockerContainerFilters.Args) ([]types.NetworkResource, error) {
+	return replayCall[[]types.NetworkResource](r, "listNetworks", filtersSummary(filters))
+}
+
+func (r *replayClient) listVolumes(_ context.Context, filters dockerContainerFilters.Args) ([]*volume.Volume, error) {
+	return replayCall[[]*volume.Volume](r, "listVolumes", filtersSummary(filters))
+}
+
+func (r *replayClient) forceRemoveContainer(_ context.Context, id string) error {
+	_, err := replayCall[struct{}](r, "forceRemoveContainer", id)
+	return err
+}
+
+func (r *replayClient) createNetwork(_ context.Context, name string, _ NetworkOptions) (string, error) {
+	return replayCall[string](r, "createNetwork", name)
+}
+
+func (r *replayClient) removeNetwork(_ context.Context, id string) error {
+	_, err := replayCall[struct{}](r, "removeNetwork", id)
+	return err
+}
+
+func (r *replayClient) ensureVolume(_ context.Context, name string, _ map[string]string) (bool, error) {
+	return replayCall[bool](r, "ensureVolume", name)
+}
+
+func (r *replayClient) removeVolume(_ context.Context, name string) error {
+	_, err := replayCall[struct{}](r, "removeVolume", name)
+	return err
+}
+
+func (r *replayClient) pruneVolumes(_ context.Context) error {
+	_, err := replayCall[struct{}](r, "pruneVolumes")
+	return err
+}
+
+func (r *replayClient) connectNetwork(_ context.Context, network, containerID string, _ []string) error {
+	_, err := replayCall[struct{}](r, "connectNetwork", network, containerID)
+	return err
+}
+
+func (r *replayClient) commitContainer(_ context.Context, id string) (string, error) {
+	return replayCall[string](r, "commitContainer", id)
+}
+
+func (r *replayClient) tagImage(_ context.Context, source, target string) error {
+	_, err := replayCall[struct{}](r, "tagImage", source, target)
+	return err
+}
+
+func (r *replayClient) pushImage(_ context.Context, ref string) error {
+	_, err := replayCall[struct{}](r, "pushImage", ref)
+	return err
+}
+
+func (r *replayClient) imageMetadata(_ context.Context, ref string) (ImageInfo, error) {
+	return replayCall[ImageInfo](r, "imageMetadata", ref)
+}
+
+func (r *replayClient) close() {}
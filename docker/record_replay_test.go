@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RecordReplay(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	path := filepath.Join(t.TempDir(), "golden.json")
+	processes := [][]string{{"root", "1", "0", "0", "10:00", "?", "00:00:01", "postgres"}}
+	mockedContainerTop = dockerContainer.ContainerTopOKBody{Processes: processes}
+	mockedContainerExport = []byte("archive")
+
+	stop, err := StartRecording(path)
+	require.NoError(t, err)
+	c := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	rows, err := c.Top(context.Background(), "aux")
+	require.NoError(t, err)
+	require.Equal(t, processes, rows)
+	exported := bytes.Buffer{}
+	require.NoError(t, ExportContainer(context.Background(), mockedContainerID, &exported))
+	require.Equal(t, "archive", exported.String())
+	mockedContainerListValues = mockedContainerListValuesEmpty
+	_, err = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName}).Top(context.Background(), "")
+	require.ErrorIs(t, err, errContainerNotFound)
+	require.NoError(t, stop())
+	require.IsType(t, &defaultClient{}, cli)
+
+	// Replay serves the recorded results without calling Docker daemon.
+	resetMocks()
+	previous := cli
+	stop, err = StartReplay(path)
+	require.NoError(t, err)
+	c = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+	rows, err = c.Top(context.Background(), "aux")
+	require.NoError(t, err)
+	require.Equal(t, processes, rows)
+	require.Equal(t, mockedContainerID, c.(*container).id)
+	require.Nil(t, mockedContainerTopArguments)
+	exported.Reset()
+	require.NoError(t, ExportContainer(context.Background(), mockedContainerID, &exported))
+	require.Equal(t, "archive", exported.String())
+	_, err = NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName}).Top(context.Background(), "")
+	require.ErrorIs(t, err, errContainerNotFound)
+	require.NoError(t, stop())
+	require.Same(t, previous, cli)
+}
+
+func Test_RecordReplayUnnamedContainer(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	path := filepath.Join(t.TempDir(), "golden.json")
+	mockedContainerInspect = types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{Name: "/quirky_euler"}}
+	processes := [][]string{{"root", "1", "postgres"}}
+	mockedContainerTop = dockerContainer.ContainerTopOKBody{Processes: processes}
+
+	stop, err := StartRecording(path)
+	require.NoError(t, err)
+	c := NewContainerWithOptions(mockedImageName, Options{})
+	require.NoError(t, c.Create(context.Background()))
+	_, err = c.Top(context.Background(), "")
+	require.NoError(t, err)
+	require.NoError(t, stop())
+
+	// The name assigned by Docker daemon is written back into options, so that the container is looked up by it.
+	resetMocks()
+	stop, err = StartReplay(path)
+	require.NoError(t, err)
+	c = NewContainerWithOptions(mockedImageName, Options{})
+	require.NoError(t, c.Create(context.Background()))
+	require.Equal(t, "quirky_euler", c.(*container).options.Name)
+	rows, err := c.Top(context.Background(), "")
+	require.NoError(t, err)
+	require.Equal(t, processes, rows)
+	require.NoError(t, stop())
+}
+
+func Test_Replay(t *testing.T) {
+	previous := cli
+	defer func() { cli = previous }()
+	path := filepath.Join(t.TempDir(), "golden.json")
+	golden := `{"version": 1, "interactions": [
+		{"method": "createContainer", "args": ["postgres", "db-1696342800"], "result": {"id": "id1"}},
+		{"method": "startContainer", "args": ["id1"]},
+		{"method": "stopRemoveContainer", "args": ["id1"]}
+	]}`
+	require.NoError(t, os.WriteFile(path, []byte(golden), 0o600))
+
+	t.Run("digits_ignored", func(t *testing.T) {
+		stop, err := StartReplay(path)
+		require.NoError(t, err)
+		id, err := CreateContainer(context.Background(), "postgres", &Options{Name: "db-1712000000"})
+		require.NoError(t, err)
+		require.Equal(t, "id1", id)
+		require.NoError(t, StartContainer(context.Background(), "id2"))
+		require.NoError(t, StopRemoveContainer(context.Background(), id))
+		require.NoError(t, stop())
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		stop, err := StartReplay(path)
+		require.NoError(t, err)
+		_, err = CreateContainer(context.Background(), "postgres", &Options{Name: "db-1712000000"})
+		require.NoError(t, err)
+		err = StopContainer(context.Background(), "id1")
+		require.ErrorIs(t, err, errReplayMismatch)
+		require.ErrorContains(t, err, `call 2: expected startContainer("id1"), got stopContainer("id1")`)
+		err = stop()
+		require.ErrorIs(t, err, errReplayIncomplete)
+		require.ErrorContains(t, err, `startContainer("id1"), stopRemoveContainer("id1")`)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		stop, err := StartReplay(path)
+		require.NoError(t, err)
+		_, err = CreateContainer(context.Background(), "postgres", &Options{Name: "db-1696342800"})
+		require.NoError(t, err)
+		require.NoError(t, StartContainer(context.Background(), "id1"))
+		require.NoError(t, StopRemoveContainer(context.Background(), "id1"))
+		require.ErrorIs(t, StopRemoveContainer(context.Background(), "id1"), errReplayExhausted)
+		require.NoError(t, stop())
+	})
+
+	t.Run("unsupported_version", func(t *testing.T) {
+		versionPath := filepath.Join(t.TempDir(), "golden.json")
+		require.NoError(t, os.WriteFile(versionPath, []byte(`{"version": 2}`), 0o600))
+		_, err := StartReplay(versionPath)
+		require.ErrorIs(t, err, errGoldenFileVersion)
+	})
+}
@@ -0,0 +1,63 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// registryMirrors holds image reference rewrite rules set using SetRegistryMirrors, longest prefix first,
+	// guarded by registryMirrorsMu.
+	registryMirrors   []registryMirror
+	registryMirrorsMu sync.Mutex
+)
+
+// registryMirror holds an image reference prefix and its replacement.
+type registryMirror struct {
+	prefix, replacement string
+}
+
+// SetRegistryMirrors sets image reference rewrite rules applied when images are pulled and containers are created,
+// e.g. `map[string]string{"docker.io/": "mirror.corp/docker.io/"}` to pull all Docker Hub images through a mirror.
+// Rules map reference prefixes to their replacements. Before matching, references are expanded to fully qualified
+// form, e.g. `postgres` to `docker.io/library/postgres`. If several prefixes match, the longest one is applied.
+// Tags and digests are preserved. The original references are kept in container options and reports.
+// Nil or empty rules disable rewriting.
+func SetRegistryMirrors(rules map[string]string) {
+	mirrors := make([]registryMirror, 0, len(rules))
+	for prefix, replacement := range rules {
+		mirrors = append(mirrors, registryMirror{prefix: prefix, replacement: replacement})
+	}
+	sort.Slice(mirrors, func(i, j int) bool {
+		if len(mirrors[i].prefix) != len(mirrors[j].prefix) {
+			return len(mirrors[i].prefix) > len(mirrors[j].prefix)
+		}
+		return mirrors[i].prefix < mirrors[j].prefix
+	})
+	registryMirrorsMu.Lock()
+	defer registryMirrorsMu.Unlock()
+	registryMirrors = mirrors
+}
+
+// mirroredImage returns the image reference rewritten according to the registry mirrors rules.
+// References which do not match any rule or cannot be parsed are returned as is.
+func mirroredImage(image string) string {
+	registryMirrorsMu.Lock()
+	mirrors := registryMirrors
+	registryMirrorsMu.Unlock()
+	if len(mirrors) == 0 {
+		return image
+	}
+	ref, err := parseImageRef(image)
+	if err != nil {
+		return image
+	}
+	qualified := ref.String()
+	for _, mirror := range mirrors {
+		if strings.HasPrefix(qualified, mirror.prefix) {
+			return mirror.replacement + strings.TrimPrefix(qualified, mirror.prefix)
+		}
+	}
+	return image
+}
@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mirroredImage(t *testing.T) {
+	defer SetRegistryMirrors(nil)
+	const digest = "sha256:4f9b0bd8e0a1e8d3a4f1b6c1e5b7a7d8c3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8"
+	rules := map[string]string{
+		"docker.io/":                 "mirror.corp/docker.io/",
+		"docker.io/library/postgres": "pg.mirror.corp/postgres",
+		"ghcr.io/":                   "mirror.corp/ghcr.io/",
+	}
+	tests := []struct {
+		name     string
+		rules    map[string]string
+		image    string
+		expected string
+	}{
+		{"no_rules", nil, "postgres", "postgres"},
+		{"bare_name", rules, "redis", "mirror.corp/docker.io/library/redis"},
+		{"tagged", rules, "redis:7-alpine", "mirror.corp/docker.io/library/redis:7-alpine"},
+		{"user_repository", rules, "bitnami/kafka:3.4", "mirror.corp/docker.io/bitnami/kafka:3.4"},
+		{"qualified", rules, "docker.io/library/redis", "mirror.corp/docker.io/library/redis"},
+		{"digest", rules, "redis@" + digest, "mirror.corp/docker.io/library/redis@" + digest},
+		{"tag_and_digest", rules, "redis:7@" + digest, "mirror.corp/docker.io/library/redis:7@" + digest},
+		{"longest_prefix", rules, "postgres:15", "pg.mirror.corp/postgres:15"},
+		{"not_matching", rules, "quay.io/coreos/etcd:v3.5.7", "quay.io/coreos/etcd:v3.5.7"},
+		{"invalid_reference", rules, "Invalid:Image", "Invalid:Image"},
+		// Preset images.
+		{"postgresql_preset", rules, "postgres", "pg.mirror.corp/postgres"},
+		{"registry_preset", rules, "registry:2", "mirror.corp/docker.io/library/registry:2"},
+		{"toxiproxy_preset", rules, toxiproxyImage, "mirror.corp/ghcr.io/shopify/toxiproxy:2.5.0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			SetRegistryMirrors(test.rules)
+			require.Equal(t, test.expected, mirroredImage(test.image))
+		})
+	}
+}
+
+func Test_createContainerRegistryMirrors(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetRegistryMirrors(nil)
+	SetRegistryMirrors(map[string]string{"docker.io/": "mirror.corp/docker.io/"})
+	resetMocks()
+	mockedContainerListValues = newContainerListMockValues(containerListMockValue{mockedEmptyContainerList, nil})
+
+	c := NewContainerWithOptions("postgres:15", Options{Name: mockedContainerName})
+	require.NoError(t, c.Create(context.Background()))
+	require.NotEmpty(t, mockedImagePullRefs)
+	for _, ref := range mockedImagePullRefs {
+		require.Equal(t, "mirror.corp/docker.io/library/postgres:15", ref)
+	}
+	require.Equal(t, "mirror.corp/docker.io/library/postgres:15", mockedContainerCreateConfig.Image)
+	// The original reference is kept in the container.
+	require.Equal(t, "postgres:15", c.(*container).image)
+}
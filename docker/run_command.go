@@ -0,0 +1,284 @@
+package docker
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	errInvalidRunCommand  = errors.New("invalid docker run command")
+	errUnsupportedRunFlag = errors.New("unsupported docker run flag")
+)
+
+// runFlags maps names of supported `docker run` flags taking a value to functions applying the value to options.
+var runFlags = map[string]func(options *Options, value string) error{
+	"env":     parseRunEnv,
+	"publish": parseRunPublish,
+	"name": func(options *Options, value string) error {
+		options.Name = value
+		return nil
+	},
+	"volume": parseRunVolume,
+	"health-cmd": func(options *Options, value string) error {
+		options.Healthcheck = value
+		return nil
+	},
+	"health-interval":     parseRunDuration(func(options *Options) *time.Duration { return &options.HealthcheckInterval }),
+	"health-timeout":      parseRunDuration(func(options *Options) *time.Duration { return &options.HealthcheckTimeout }),
+	"health-start-period": parseRunDuration(func(options *Options) *time.Duration { return &options.HealthcheckStartPeriod }),
+	"health-retries": func(options *Options, value string) error {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			return errors.Errorf("invalid health retries %q", value)
+		}
+		options.HealthcheckRetries = retries
+		return nil
+	},
+	"entrypoint": func(options *Options, value string) error {
+		options.Entrypoint = []string{value}
+		return nil
+	},
+	"label": func(options *Options, value string) error {
+		key, labelValue, _ := strings.Cut(value, "=")
+		if len(key) == 0 {
+			return errors.Errorf("invalid label %q", value)
+		}
+		if options.Labels == nil {
+			options.Labels = map[string]string{}
+		}
+		options.Labels[key] = labelValue
+		return nil
+	},
+	"network": func(options *Options, value string) error {
+		options.Networks = append(options.Networks, value)
+		return nil
+	},
+	"restart": func(options *Options, value string) error {
+		_, err := containerRestartPolicy(value)
+		options.RestartPolicy = value
+		return err
+	},
+	"user": func(options *Options, value string) error {
+		options.User = value
+		return nil
+	},
+}
+
+var (
+	// runShortFlags maps supported short `docker run` flags to their long names.
+	runShortFlags = map[string]string{"e": "env", "p": "publish", "v": "volume", "l": "label", "u": "user"}
+	// ignoredRunFlags holds `docker run` flags without a value which are accepted and ignored, as containers are
+	// always run detached and removed using the package.
+	ignoredRunFlags = map[string]bool{"d": true, "detach": true, "rm": true}
+)
+
+// ParseRunCommand returns the image and container options of the given `docker run` command, e.g.
+// `docker run -e X=1 -p 5432:5432 --name db postgres:16`, so that containers started by shell scripts can be
+// created with the package. The leading `docker run` or `docker container run` is optional. Arguments are split
+// the way a POSIX shell splits them, line continuations are supported. Supported flags are -e/--env, -p/--publish,
+// --name, -v/--volume, --health-cmd and other health timing flags, --entrypoint, -l/--label, --network, --restart,
+// and -u/--user. -d/--detach and --rm are ignored. Arguments following the image override the image command.
+func ParseRunCommand(cmd string) (string, *Options, error) {
+	args, err := splitCommandLine(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, prefix := range [][]string{{"docker", "container", "run"}, {"docker", "run"}} {
+		if len(args) >= len(prefix) && strings.Join(args[:len(prefix)], " ") == strings.Join(prefix, " ") {
+			args = args[len(prefix):]
+			break
+		}
+	}
+
+	options := &Options{}
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			args = append([]string{arg}, args...)
+			break
+		}
+
+		var name, value string
+		var hasValue bool
+		if strings.HasPrefix(arg, "--") {
+			name, value, hasValue = strings.Cut(arg[2:], "=")
+		} else {
+			name, value = arg[1:2], arg[2:]
+			hasValue = len(value) > 0
+			if long, found := runShortFlags[name]; found {
+				name = long
+			} else if !ignoredRunFlags[name] {
+				return "", nil, errors.Wrap(errUnsupportedRunFlag, arg)
+			}
+		}
+		if ignoredRunFlags[name] && !hasValue {
+			continue
+		}
+		apply, found := runFlags[name]
+		if !found {
+			return "", nil, errors.Wrap(errUnsupportedRunFlag, arg)
+		}
+		if !hasValue {
+			if len(args) == 0 {
+				return "", nil, errors.Wrapf(errInvalidRunCommand, "flag %s requires a value", arg)
+			}
+			value, args = args[0], args[1:]
+		}
+		if err := apply(options, value); err != nil {
+			return "", nil, errors.Wrapf(errInvalidRunCommand, "flag %s: %v", arg, err)
+		}
+	}
+
+	if len(args) == 0 {
+		return "", nil, errEmptyImageName
+	}
+	if len(args) > 1 {
+		options.Cmd = args[1:]
+	}
+	if len(options.Networks) == 1 {
+		options.Network, options.Networks = options.Networks[0], nil
+	}
+	return args[0], options, nil
+}
+
+// parseRunEnv adds the given environment variable to options. A variable without a value, e.g. `-e HOME`, takes
+// the value from the current environment and is skipped if it is not set, the same way as in `docker run`.
+func parseRunEnv(options *Options, value string) error {
+	if len(value) == 0 || value[0] == '=' {
+		return errors.Errorf("invalid environment variable %q", value)
+	}
+	if !strings.Contains(value, "=") {
+		v, found := os.LookupEnv(value)
+		if !found {
+			return nil
+		}
+		value += "=" + v
+	}
+	options.EnvironmentVariables = append(options.EnvironmentVariables, value)
+	return nil
+}
+
+// parseRunPublish adds the given port binding in `[hostPort:]containerPort[/protocol]` format to options.
+// A container port without a host port is bound to an ephemeral host port. Host IP addresses and port ranges
+// are not supported.
+func parseRunPublish(options *Options, value string) error {
+	hostPort, containerPort, found := strings.Cut(value, ":")
+	if !found {
+		hostPort, containerPort = "", value
+	}
+	if strings.Contains(containerPort, ":") || strings.Contains(value, "-") || len(containerPort) == 0 {
+		return errors.Errorf("port binding %q is not supported, expected format is: [hostPort:]containerPort[/protocol]", value)
+	}
+	options.ExposedPorts = append(options.ExposedPorts, hostPort+":"+containerPort)
+	return nil
+}
+
+// parseRunVolume adds the given bind mount in `source:target[:ro|rw]` format to options. Named and anonymous
+// volumes are not supported.
+func parseRunVolume(options *Options, value string) error {
+	// Windows paths, e.g. `C:\data`, contain a colon after the drive letter.
+	split := 0
+	if len(value) > 2 && value[1] == ':' && isDriveLetter(value[0]) {
+		split = 2
+	}
+	i := strings.Index(value[split:], ":")
+	if i < 0 {
+		return errors.Errorf("anonymous volume %q is not supported", value)
+	}
+	source, rest := value[:split+i], value[split+i+1:]
+	if !isAbsPath(source) {
+		return errors.Errorf("named volume %q is not supported, use an absolute host path", source)
+	}
+	m := Mount{Source: source, Target: rest}
+	if j := strings.LastIndex(rest, ":"); j > 1 {
+		switch mode := rest[j+1:]; mode {
+		case "ro", "rw":
+			m.Target, m.ReadOnly = rest[:j], mode == "ro"
+		default:
+			return errors.Errorf("volume mode %q is not supported", mode)
+		}
+	}
+	options.Mounts = append(options.Mounts, m)
+	return nil
+}
+
+// parseRunDuration returns a function setting the options duration returned by field to the given value,
+// e.g. `30s`.
+func parseRunDuration(field func(options *Options) *time.Duration) func(options *Options, value string) error {
+	return func(options *Options, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(options) = d
+		return nil
+	}
+}
+
+// splitCommandLine splits the given command line into arguments the way a POSIX shell does: arguments are
+// separated with whitespace, single quotes preserve enclosed characters, double quotes preserve them except for
+// backslash escapes of `"`, `\`, `$`, and backquote, and a backslash outside quotes escapes the next character.
+// A backslash followed by a newline is removed. Variables and other expansions are not supported.
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			if i+1 == len(line) {
+				return nil, errors.Wrap(errInvalidRunCommand, "trailing backslash")
+			}
+			i++
+			if line[i] != '\n' {
+				current.WriteByte(line[i])
+				inArg = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.Wrap(errInvalidRunCommand, "unterminated single quote")
+			}
+			current.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`\n", line[i+1]) >= 0 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(line[i])
+			}
+			if i == len(line) {
+				return nil, errors.Wrap(errInvalidRunCommand, "unterminated double quote")
+			}
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
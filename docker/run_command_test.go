@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	dockerContainer "github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseRunCommand(t *testing.T) {
+	t.Setenv("TESTUTILS_RUN_ENV", "from-host")
+	tests := []struct {
+		name            string
+		cmd             string
+		expectedImage   string
+		expectedOptions *Options
+		expectedError   error
+	}{
+		{
+			"documented", "docker run -e X=1 -p 5432:5432 --name db postgres:16", "postgres:16",
+			&Options{Name: "db", EnvironmentVariables: []string{"X=1"}, ExposedPorts: []string{"5432:5432"}}, nil,
+		},
+		{"container_run", "docker container run redis:7", "redis:7", &Options{}, nil},
+		{"without_prefix", "--name=cache redis:7", "redis:7", &Options{Name: "cache"}, nil},
+		{
+			"long_flags", "docker run --env=A=1 --env B=2 --publish 8080:80/tcp --publish=9000 nginx", "nginx",
+			&Options{EnvironmentVariables: []string{"A=1", "B=2"}, ExposedPorts: []string{"8080:80/tcp", ":9000"}}, nil,
+		},
+		{
+			"attached_short_value", "docker run -eA=1 -p6379:6379 redis", "redis",
+			&Options{EnvironmentVariables: []string{"A=1"}, ExposedPorts: []string{"6379:6379"}}, nil,
+		},
+		{
+			"host_env", "docker run -e TESTUTILS_RUN_ENV -e TESTUTILS_RUN_UNSET alpine", "alpine",
+			&Options{EnvironmentVariables: []string{"TESTUTILS_RUN_ENV=from-host"}}, nil,
+		},
+		{
+			"volumes", "docker run -v /srv/data:/data -v /etc/conf:/etc/app:ro alpine", "alpine",
+			&Options{Mounts: []Mount{{Source: "/srv/data", Target: "/data"}, {Source: "/etc/conf", Target: "/etc/app", ReadOnly: true}}}, nil,
+		},
+		{
+			"healthcheck", `docker run --health-cmd "pg_isready -U postgres" --health-interval 1s --health-retries=5 postgres`, "postgres",
+			&Options{Healthcheck: "pg_isready -U postgres", HealthcheckInterval: time.Second, HealthcheckRetries: 5}, nil,
+		},
+		{
+			"entrypoint_and_command", "docker run --entrypoint /bin/sh alpine -c 'echo hello world'", "alpine",
+			&Options{Entrypoint: []string{"/bin/sh"}, Cmd: []string{"-c", "echo hello world"}}, nil,
+		},
+		{
+			"labels", "docker run --label team=db -l scratch alpine", "alpine",
+			&Options{Labels: map[string]string{"team": "db", "scratch": ""}}, nil,
+		},
+		{"network", "docker run --network backend alpine", "alpine", &Options{Network: "backend"}, nil},
+		{"networks", "docker run --network frontend --network backend alpine", "alpine", &Options{Networks: []string{"frontend", "backend"}}, nil},
+		{"restart", "docker run --restart on-failure:3 alpine", "alpine", &Options{RestartPolicy: "on-failure:3"}, nil},
+		{"ignored", "docker run -d --rm --detach alpine", "alpine", &Options{}, nil},
+		{
+			"line_continuation", "docker run \\\n  -e \"GREETING=hello \\\"world\\\"\" \\\n  alpine", "alpine",
+			&Options{EnvironmentVariables: []string{`GREETING=hello "world"`}}, nil,
+		},
+		{"end_of_flags", "docker run -- -image", "-image", &Options{}, nil},
+		{"unsupported_flag", "docker run --privileged alpine", "", nil, errUnsupportedRunFlag},
+		{"unsupported_short_flag", "docker run -it alpine sh", "", nil, errUnsupportedRunFlag},
+		{"flags_after_image", "docker run alpine --name", "alpine", &Options{Cmd: []string{"--name"}}, nil},
+		{"missing_flag_value", "docker run --name", "", nil, errInvalidRunCommand},
+		{"host_ip", "docker run -p 127.0.0.1:5432:5432 postgres", "", nil, errInvalidRunCommand},
+		{"port_range", "docker run -p 8000-8010:8000-8010 nginx", "", nil, errInvalidRunCommand},
+		{"named_volume", "docker run -v data:/data alpine", "", nil, errInvalidRunCommand},
+		{"anonymous_volume", "docker run -v /data alpine", "", nil, errInvalidRunCommand},
+		{"volume_mode", "docker run -v /srv:/data:z alpine", "", nil, errInvalidRunCommand},
+		{"restart_policy", "docker run --restart sometimes alpine", "", nil, errInvalidRunCommand},
+		{"health_interval", "docker run --health-interval often alpine", "", nil, errInvalidRunCommand},
+		{"unterminated_quote", "docker run -e 'A=1 alpine", "", nil, errInvalidRunCommand},
+		{"no_image", "docker run --name db", "", nil, errEmptyImageName},
+		{"empty", "docker run -d", "", nil, errEmptyImageName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			image, options, err := ParseRunCommand(test.cmd)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedImage, image)
+			require.Empty(t, DiffOptions(test.expectedOptions, options))
+		})
+	}
+}
+
+func Test_containerRestartPolicy(t *testing.T) {
+	tests := []struct {
+		policy         string
+		expectedPolicy dockerContainer.RestartPolicy
+		expectedError  error
+	}{
+		{"", dockerContainer.RestartPolicy{}, nil},
+		{"no", dockerContainer.RestartPolicy{Name: "no"}, nil},
+		{"unless-stopped", dockerContainer.RestartPolicy{Name: "unless-stopped"}, nil},
+		{"on-failure", dockerContainer.RestartPolicy{Name: "on-failure"}, nil},
+		{"on-failure:3", dockerContainer.RestartPolicy{Name: "on-failure", MaximumRetryCount: 3}, nil},
+		{"on-failure:-1", dockerContainer.RestartPolicy{}, errInvalidRestartPolicy},
+		{"always:3", dockerContainer.RestartPolicy{}, errInvalidRestartPolicy},
+		{"sometimes", dockerContainer.RestartPolicy{}, errInvalidRestartPolicy},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			policy, err := containerRestartPolicy(test.policy)
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expectedPolicy, policy)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package docker
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// labelSession holds the id of the session which has created a Docker object.
+const labelSession = "testutils.session"
+
+var (
+	errSessionClose = errors.New("cannot remove some of the session resources")
+)
+
+// Session scopes containers, networks, and volumes created within it, e.g. in `TestMain`. Created objects are
+// labeled with `testutils.session=<id>` and removed together by [Session.Close].
+type Session struct {
+	id       string
+	defaults Options
+	client   client
+
+	// mu guards the registries of created objects.
+	mu         sync.Mutex
+	containers []Container
+	networks   []string
+	volumes    []string
+}
+
+// SessionOption sets [Session] optional attributes.
+type SessionOption func(s *Session)
+
+// WithSessionID sets the session id used as the session label value. By default, a random id is generated.
+func WithSessionID(id string) SessionOption {
+	return func(s *Session) {
+		s.id = id
+	}
+}
+
+// WithSessionDefaults sets default options of the session containers. Options set in [Session.NewContainer] call
+// take precedence, custom labels are merged.
+func WithSessionDefaults(options Options) SessionOption {
+	return func(s *Session) {
+		s.defaults = options
+	}
+}
+
+// NewSession creates a new session owning a Docker client.
+func NewSession(ctx context.Context, opts ...SessionOption) (*Session, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s := &Session{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if len(s.id) == 0 {
+		id := make([]byte, 6)
+		if _, err := rand.Read(id); err != nil {
+			return nil, err
+		}
+		s.id = hex.EncodeToString(id)
+	}
+	c, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	s.client = c
+	return s, nil
+}
+
+// ID returns the session id.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// labels returns the session label together with the given custom labels.
+func (s *Session) labels(custom ...map[string]string) map[string]string {
+	labels := map[string]string{}
+	for _, m := range custom {
+		for key, value := range m {
+			labels[key] = value
+		}
+	}
+	labels[labelSession] = s.id
+	return labels
+}
+
+// NewContainer returns a new [Container] object with the session default options overridden by the given ones,
+// see [MergeOptions]. Labels are merged key by key. The container is removed on session Close.
+func (s *Session) NewContainer(image string, options Options) Container {
+	merged := MergeOptions(s.defaults, options)
+	merged.Labels = s.labels(s.defaults.Labels, options.Labels)
+	c := NewContainerWithOptions(image, merged)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.containers = append(s.containers, c)
+	return c
+}
+
+// NewNetwork creates a new Docker network with the given name and returns its id. The network is removed on
+// session Close.
+func (s *Session) NewNetwork(ctx context.Context, name string, options *NetworkOptions) (string, error) {
+	if len(name) == 0 {
+		return "", errEmptyNetworkName
+	}
+	var o NetworkOptions
+	if options != nil {
+		o = *options
+	}
+	o.Labels = s.labels(o.Labels)
+	id, err := s.client.createNetwork(ctx, name, o)
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.networks = append(s.networks, name)
+	return id, nil
+}
+
+// NewVolume creates a Docker volume with the given name, if it does not exist. The volume is removed on session
+// Close, unless it has existed before.
+func (s *Session) NewVolume(ctx context.Context, name string) error {
+	if len(name) == 0 {
+		return errEmptyVolumeName
+	}
+	created, err := s.client.ensureVolume(ctx, name, s.labels())
+	if err != nil || !created {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volumes = append(s.volumes, name)
+	return nil
+}
+
+// Close removes the session containers, networks, and volumes, in this order, so that networks and volumes are
+// no longer in use when they are removed. Containers which have not been created are skipped. Removal continues
+// past individual failures, the returned error lists all of them. Objects are removed once, so Close can be
+// called several times, e.g. both deferred and explicitly.
+func (s *Session) Close(ctx context.Context) error {
+	s.mu.Lock()
+	containers, networks, volumes := s.containers, s.networks, s.volumes
+	s.containers, s.networks, s.volumes = nil, nil, nil
+	s.mu.Unlock()
+
+	var failed []string
+	for i := len(containers) - 1; i >= 0; i-- {
+		if err := containers[i].StopRemove(ctx); err != nil && !errors.Is(err, errContainerNotFound) {
+			failed = append(failed, "container "+containers[i].Name()+": "+err.Error())
+		}
+	}
+	for i := len(networks) - 1; i >= 0; i-- {
+		if err := s.client.removeNetwork(ctx, networks[i]); err != nil {
+			failed = append(failed, "network "+networks[i]+": "+err.Error())
+		}
+	}
+	for i := len(volumes) - 1; i >= 0; i-- {
+		if err := s.client.removeVolume(ctx, volumes[i]); err != nil {
+			failed = append(failed, "volume "+volumes[i]+": "+err.Error())
+		}
+	}
+	s.client.close()
+	if len(failed) > 0 {
+		return errors.Wrapf(errSessionClose, "%v", failed)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SessionClose(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	errRemove := errors.New("resource is in use")
+	tests := []struct {
+		name           string
+		networkErrors  map[string]error
+		volumeErrors   map[string]error
+		expectedError  error
+		expectedDetail string
+	}{
+		{"all_removed", nil, nil, nil, ""},
+		{
+			"partial_failure",
+			map[string]error{"backend": errRemove},
+			map[string]error{"pgdata": errRemove},
+			errSessionClose,
+			"[network backend: resource is in use volume pgdata: resource is in use]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedDaemonContainers = map[string]string{}
+			mockedNetworkRemoveErrors = test.networkErrors
+			mockedVolumeRemoveErrors = test.volumeErrors
+			mockedVolumes = map[string]bool{"shared": true}
+			ctx := context.Background()
+			s, err := NewSession(ctx, WithSessionID("ci-42"))
+			require.NoError(t, err)
+			require.NoError(t, s.NewVolume(ctx, "pgdata"))
+			require.NoError(t, s.NewVolume(ctx, "shared"))
+			_, err = s.NewNetwork(ctx, "backend", nil)
+			require.NoError(t, err)
+			require.NoError(t, s.NewContainer(mockedImageName, Options{Name: "db"}).Create(ctx))
+			require.NoError(t, s.NewContainer(mockedImageName, Options{Name: "api"}).Create(ctx))
+			s.NewContainer(mockedImageName, Options{Name: "never-created"})
+			mockedDaemonCalls = nil
+
+			err = s.Close(ctx)
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError != nil {
+				require.Contains(t, err.Error(), test.expectedDetail)
+			}
+			// Containers are removed before networks and volumes, in reverse creation order.
+			require.Equal(t, []string{
+				"stop api", "remove api", "stop db", "remove db", "network remove backend", "volume remove pgdata",
+			}, mockedDaemonCalls)
+
+			mockedDaemonCalls = nil
+			require.NoError(t, s.Close(ctx))
+			require.Empty(t, mockedDaemonCalls)
+		})
+	}
+}
+
+func Test_SessionLabels(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	ctx := context.Background()
+	s, err := NewSession(ctx, WithSessionDefaults(Options{
+		User:         "postgres",
+		StartTimeout: 5,
+		Labels:       map[string]string{"team": "payments", "tier": "db"},
+	}))
+	require.NoError(t, err)
+	require.Len(t, s.ID(), 12)
+
+	c := s.NewContainer(mockedImageName, Options{Name: mockedContainerName, StartTimeout: 30, Labels: map[string]string{"tier": "cache"}})
+	require.NoError(t, c.Create(ctx))
+	require.Equal(t, map[string]string{
+		labelManaged: "true", labelSession: s.ID(), "team": "payments", "tier": "cache",
+	}, mockedContainerCreateConfig.Labels)
+	require.Equal(t, "postgres", mockedContainerCreateConfig.User)
+	require.Equal(t, 30, c.(*container).options.StartTimeout)
+	// Options are merged according to MergeOptions rules, e.g. negative numbers do not override defaults.
+	require.Equal(t, 5, s.NewContainer(mockedImageName, Options{StartTimeout: -1}).(*container).options.StartTimeout)
+
+	_, err = s.NewNetwork(ctx, "backend", &NetworkOptions{Labels: map[string]string{"team": "payments"}})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{labelManaged: "true", labelSession: s.ID(), "team": "payments"},
+		mockedNetworkCreateOptions.Labels)
+
+	require.NoError(t, s.NewVolume(ctx, "pgdata"))
+	require.Equal(t, []volume.CreateOptions{{
+		Name:   "pgdata",
+		Labels: map[string]string{labelManaged: "true", labelSession: s.ID()},
+	}}, mockedVolumeCreateOptions)
+
+	_, err = s.NewNetwork(ctx, "", nil)
+	require.ErrorIs(t, err, errEmptyNetworkName)
+	require.ErrorIs(t, s.NewVolume(ctx, ""), errEmptyVolumeName)
+}
@@ -0,0 +1,173 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// sidecarNameFormat is the format of generated sidecar container names: primary container name and sidecar index.
+	sidecarNameFormat = "%s-sidecar-%d"
+	// sidecarNetworkSuffix is appended to primary container name to get the name of the network shared with sidecars.
+	sidecarNetworkSuffix = "-net"
+	// primaryNameFormat is the format of generated names of unnamed containers with sidecars: process id and sequence.
+	primaryNameFormat = "testutils-%d-%d"
+)
+
+var (
+	// primaryNameSeq is used to generate unique names of unnamed containers with sidecars.
+	primaryNameSeq uint64
+
+	errSidecars = errors.New("sidecar containers failure")
+)
+
+// SidecarSpec describes a sidecar container, e.g. a proxy or a log shipper, attached to a primary container lifecycle.
+type SidecarSpec struct {
+	Image string
+	// Options holds sidecar container optional attributes values. If the name is not set, it is generated
+	// from the primary container name, e.g. `db-sidecar-0`. Network and nested sidecars are ignored.
+	Options *Options
+}
+
+// createSidecars creates a network shared by the container and its sidecars, unless [Options.Network] is set
+// and [Options.IsolatedNetwork] is not, and creates sidecar containers attached to it. The created network name
+// is kept apart from the container options, so that it is created again after Remove. Unnamed primary container
+// gets a generated name, so that sidecar and network names can be derived from it. If any sidecar fails to be
+// created, the sidecars which have been created and the network are removed.
+func (c *container) createSidecars(ctx context.Context) error {
+	if c.options.IsolatedNetwork && (len(c.options.Network) > 0 || len(c.options.Networks) > 0) {
+		return errIsolatedNetworkConflict
+	}
+	if len(c.options.Name) == 0 {
+		c.options.Name = fmt.Sprintf(primaryNameFormat, os.Getpid(), atomic.AddUint64(&primaryNameSeq, 1))
+	}
+	if len(c.options.Network) == 0 && len(c.sidecarsNetwork) == 0 {
+		name := c.options.Name + sidecarNetworkSuffix
+		if _, err := CreateNetwork(ctx, name, nil); err != nil {
+			return err
+		}
+		c.sidecarsNetwork = name
+	}
+	network := c.network()
+	var failed []string
+	c.sidecars = make([]*container, 0, len(c.options.Sidecars))
+	for i, spec := range c.options.Sidecars {
+		var options Options
+		if spec.Options != nil {
+			options = *spec.Options
+		}
+		if len(options.Name) == 0 {
+			options.Name = fmt.Sprintf(sidecarNameFormat, c.options.Name, i)
+		}
+		options.Network = network
+		options.Sidecars = nil
+		sidecar := NewContainerWithOptions(spec.Image, options).(*container)
+		if err := sidecar.Create(ctx); err != nil {
+			failed = append(failed, options.Name+": "+err.Error())
+			continue
+		}
+		c.sidecars = append(c.sidecars, sidecar)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if err := c.teardownSidecars(ctx, (*container).Remove, true); err != nil {
+		failed = append(failed, "rollback: "+err.Error())
+	}
+	return sidecarsError(failed)
+}
+
+// network returns the network the container is created in: the one created for its sidecars, if any,
+// or [Options.Network].
+func (c *container) network() string {
+	if len(c.sidecarsNetwork) > 0 {
+		return c.sidecarsNetwork
+	}
+	return c.options.Network
+}
+
+// creationOptions returns a copy of the container options with the network created for sidecars, if any, set.
+func (c *container) creationOptions() *Options {
+	options := c.options
+	options.Network = c.network()
+	return &options
+}
+
+// startSidecars starts sidecar containers. All sidecars are started even if some of them fail.
+func (c *container) startSidecars(ctx context.Context) error {
+	var failed []string
+	for _, sidecar := range c.sidecars {
+		if err := sidecar.Start(ctx); err != nil {
+			failed = append(failed, sidecar.options.Name+": "+err.Error())
+		}
+	}
+	return sidecarsError(failed)
+}
+
+// withSidecarsTeardown tears down sidecar containers after the primary container operation has finished with err.
+// Returns err or, if it is nil, the sidecars teardown error.
+func (c *container) withSidecarsTeardown(
+	ctx context.Context,
+	err error,
+	operation func(sidecar *container, ctx context.Context) error,
+	remove bool,
+) error {
+	if len(c.sidecars) == 0 && len(c.sidecarsNetwork) == 0 {
+		return err
+	}
+	if sidecarsErr := c.teardownSidecars(ctx, operation, remove); err == nil {
+		return sidecarsErr
+	}
+	return err
+}
+
+// teardownSidecars applies the given operation to sidecar containers in reverse order and, if remove is true,
+// removes the network created for them, so that the container can be created again. Teardown continues past individual failures.
+func (c *container) teardownSidecars(
+	ctx context.Context,
+	operation func(sidecar *container, ctx context.Context) error,
+	remove bool,
+) error {
+	var failed []string
+	for i := len(c.sidecars) - 1; i >= 0; i-- {
+		if err := operation(c.sidecars[i], ctx); err != nil {
+			failed = append(failed, c.sidecars[i].options.Name+": "+err.Error())
+		}
+	}
+	if remove {
+		c.sidecars = nil
+		if len(c.sidecarsNetwork) > 0 {
+			if err := RemoveNetwork(ctx, c.sidecarsNetwork); err != nil {
+				failed = append(failed, c.sidecarsNetwork+": "+err.Error())
+			}
+			c.sidecarsNetwork = ""
+		}
+	}
+	return sidecarsError(failed)
+}
+
+// teardownIsolatedNetwork removes sidecar containers and the network created for the container after Create
+// has failed with err, if [Options.IsolatedNetwork] is set. Returns err with teardown failures, if any.
+func (c *container) teardownIsolatedNetwork(ctx context.Context, err error) error {
+	if !c.options.IsolatedNetwork || len(c.sidecarsNetwork) == 0 {
+		return err
+	}
+	network := c.sidecarsNetwork
+	if teardownErr := c.teardownSidecars(ctx, (*container).StopRemove, true); teardownErr != nil {
+		return errors.Wrapf(err, "isolated network %s teardown: %v", network, teardownErr)
+	}
+	return err
+}
+
+// sidecarsError returns an error aggregating the given sidecar failures or nil if there are none.
+func sidecarsError(failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.Wrap(errSidecars, strings.Join(failed, "; "))
+}
@@ -0,0 +1,296 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_sidecarsLifecycle(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	sidecars := []SidecarSpec{
+		{Image: "shipper"},
+		{Image: "toxiproxy", Options: &Options{Name: "proxy"}},
+	}
+	tests := []struct {
+		name          string
+		options       Options
+		operation     func(c Container, ctx context.Context) error
+		expectedCalls []string
+	}{
+		{
+			"createStart_stopRemove",
+			Options{Name: "db", Sidecars: sidecars},
+			Container.StopRemove,
+			[]string{
+				"network create db-net",
+				"create db-sidecar-0 db-net",
+				"create proxy db-net",
+				"create db db-net",
+				"start db-sidecar-0",
+				"start proxy",
+				"start db",
+				"stop db", "remove db",
+				"stop proxy", "remove proxy",
+				"stop db-sidecar-0", "remove db-sidecar-0",
+				"network remove db-net",
+			},
+		},
+		{
+			"createStart_stop",
+			Options{Name: "db", Sidecars: sidecars},
+			Container.Stop,
+			[]string{
+				"network create db-net",
+				"create db-sidecar-0 db-net",
+				"create proxy db-net",
+				"create db db-net",
+				"start db-sidecar-0",
+				"start proxy",
+				"start db",
+				"stop db",
+				"stop proxy",
+				"stop db-sidecar-0",
+			},
+		},
+		{
+			"user_network",
+			Options{Name: "db", Network: "stack", Sidecars: sidecars[1:]},
+			Container.StopRemove,
+			[]string{
+				"create proxy stack",
+				"create db stack",
+				"start proxy",
+				"start db",
+				"stop db", "remove db",
+				"stop proxy", "remove proxy",
+			},
+		},
+		{
+			"no_sidecars",
+			Options{Name: "db"},
+			Container.StopRemove,
+			[]string{"create db ", "start db", "stop db", "remove db"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedDaemonContainers = map[string]string{}
+			c := NewContainerWithOptions(mockedImageName, test.options)
+			require.NoError(t, c.CreateStart(context.Background()))
+			require.NoError(t, test.operation(c, context.Background()))
+			require.Equal(t, test.expectedCalls, mockedDaemonCalls)
+		})
+	}
+}
+
+func Test_sidecarsGeneratedNames(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	c := NewContainerWithOptions(mockedImageName, Options{Sidecars: []SidecarSpec{{Image: "shipper"}}})
+	require.NoError(t, c.Create(context.Background()))
+
+	primary := fmt.Sprintf(primaryNameFormat, os.Getpid(), primaryNameSeq)
+	require.Equal(t, []string{
+		"network create " + primary + "-net",
+		"create " + primary + "-sidecar-0 " + primary + "-net",
+		"create " + primary + " " + primary + "-net",
+	}, mockedDaemonCalls)
+}
+
+func Test_sidecarsStartFailure(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	mockedDaemonStartErrors = map[string]error{
+		"db-sidecar-0": errors.New("port is already allocated"),
+		"db-sidecar-2": errors.New("no such file or directory"),
+	}
+	c := NewContainerWithOptions(mockedImageName, Options{
+		Name:     "db",
+		Sidecars: []SidecarSpec{{Image: "shipper"}, {Image: "toxiproxy"}, {Image: "exporter"}},
+	})
+	err := c.CreateStart(context.Background())
+	require.ErrorIs(t, err, errSidecars)
+	require.ErrorContains(t, err, "db-sidecar-0: port is already allocated; db-sidecar-2: no such file or directory")
+	// All sidecars are attempted to start, the primary container is not started.
+	require.Equal(t, []string{
+		"network create db-net",
+		"create db-sidecar-0 db-net",
+		"create db-sidecar-1 db-net",
+		"create db-sidecar-2 db-net",
+		"create db db-net",
+		"start db-sidecar-0",
+		"start db-sidecar-1",
+		"start db-sidecar-2",
+	}, mockedDaemonCalls)
+
+	mockedDaemonCalls = nil
+	require.NoError(t, c.StopRemove(context.Background()))
+	require.Equal(t, []string{
+		"stop db", "remove db",
+		"stop db-sidecar-2", "remove db-sidecar-2",
+		"stop db-sidecar-1", "remove db-sidecar-1",
+		"stop db-sidecar-0", "remove db-sidecar-0",
+		"network remove db-net",
+	}, mockedDaemonCalls)
+}
+
+func Test_isolatedNetwork(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	errCreate := errors.New("invalid reference format")
+	tests := []struct {
+		name          string
+		options       Options
+		createErrors  map[string]error
+		expectedError error
+		expectedCalls []string
+	}{
+		{
+			"create_remove",
+			Options{Name: "db", IsolatedNetwork: true},
+			nil, nil,
+			[]string{"network create db-net", "create db db-net", "stop db", "remove db", "network remove db-net"},
+		},
+		{
+			"with_sidecars",
+			Options{Name: "db", IsolatedNetwork: true, Sidecars: []SidecarSpec{{Image: "shipper"}}},
+			nil, nil,
+			[]string{
+				"network create db-net",
+				"create db-sidecar-0 db-net",
+				"create db db-net",
+				"stop db", "remove db",
+				"stop db-sidecar-0", "remove db-sidecar-0",
+				"network remove db-net",
+			},
+		},
+		{
+			"create_failure",
+			Options{Name: "db", IsolatedNetwork: true, Sidecars: []SidecarSpec{{Image: "shipper"}}},
+			map[string]error{"db": errCreate}, errCreate,
+			[]string{
+				"network create db-net",
+				"create db-sidecar-0 db-net",
+				"create db db-net",
+				"stop db-sidecar-0", "remove db-sidecar-0",
+				"network remove db-net",
+			},
+		},
+		{
+			"sidecar_failure",
+			Options{Name: "db", IsolatedNetwork: true, Sidecars: []SidecarSpec{{Image: "shipper"}}},
+			map[string]error{"db-sidecar-0": errors.New("no such image")}, errSidecars,
+			[]string{"network create db-net", "create db-sidecar-0 db-net", "network remove db-net"},
+		},
+		{
+			"network_conflict",
+			Options{Name: "db", IsolatedNetwork: true, Network: "shared"},
+			nil, errIsolatedNetworkConflict, nil,
+		},
+		{
+			"networks_conflict",
+			Options{Name: "db", IsolatedNetwork: true, Networks: []string{"frontend"}},
+			nil, errIsolatedNetworkConflict, nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedDaemonContainers = map[string]string{}
+			mockedDaemonCreateErrors = test.createErrors
+			c := NewContainerWithOptions(mockedImageName, test.options)
+			err := c.Create(context.Background())
+			require.ErrorIs(t, err, test.expectedError)
+			if test.expectedError == nil {
+				require.NoError(t, c.StopRemove(context.Background()))
+			}
+			require.Equal(t, test.expectedCalls, mockedDaemonCalls)
+		})
+	}
+}
+
+func Test_isolatedNetworkRecreate(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: "db", IsolatedNetwork: true})
+	for i := 0; i < 2; i++ {
+		mockedDaemonCalls = nil
+		require.NoError(t, c.Create(context.Background()))
+		require.NoError(t, c.Remove(context.Background()))
+		require.Equal(t, []string{"network create db-net", "create db db-net", "remove db", "network remove db-net"}, mockedDaemonCalls)
+	}
+
+	// Unhealthy container re-creation stops and removes it and creates it again.
+	mockedDaemonCalls = nil
+	require.NoError(t, c.CreateStart(context.Background()))
+	require.NoError(t, c.StopRemove(context.Background()))
+	require.NoError(t, c.CreateStart(context.Background()))
+	require.Equal(t, []string{
+		"network create db-net", "create db db-net", "start db",
+		"stop db", "remove db", "network remove db-net",
+		"network create db-net", "create db db-net", "start db",
+	}, mockedDaemonCalls)
+}
+
+func Test_sidecarsRecreate(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	c := NewContainerWithOptions(mockedImageName, Options{Name: "db", Sidecars: []SidecarSpec{{Image: "shipper"}}})
+
+	for i := 0; i < 2; i++ {
+		mockedDaemonCalls = nil
+		require.NoError(t, c.Create(context.Background()))
+		require.Empty(t, c.(*container).options.Network)
+		require.NoError(t, c.Remove(context.Background()))
+		require.Equal(t, []string{
+			"network create db-net",
+			"create db-sidecar-0 db-net",
+			"create db db-net",
+			"remove db",
+			"remove db-sidecar-0",
+			"network remove db-net",
+		}, mockedDaemonCalls)
+	}
+}
+
+func Test_sidecarsCreateFailure(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	resetMocks()
+	mockedDaemonContainers = map[string]string{}
+	mockedDaemonCreateErrors = map[string]error{"db-sidecar-1": errors.New("no such image")}
+	c := NewContainerWithOptions(mockedImageName, Options{
+		Name:     "db",
+		Sidecars: []SidecarSpec{{Image: "shipper"}, {Image: "toxiproxy"}},
+	})
+
+	err := c.Create(context.Background())
+	require.ErrorIs(t, err, errSidecars)
+	require.ErrorContains(t, err, "db-sidecar-1: no such image")
+	// The created sidecar and the network are removed, the primary container is not created.
+	require.Equal(t, []string{
+		"network create db-net",
+		"create db-sidecar-0 db-net",
+		"create db-sidecar-1 db-net",
+		"remove db-sidecar-0",
+		"network remove db-net",
+	}, mockedDaemonCalls)
+	require.Empty(t, mockedDaemonContainers)
+}
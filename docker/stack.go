@@ -0,0 +1,92 @@
+package docker
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	dockerContainerFilters "github.com/docker/docker/api/types/filters"
+	"github.com/pkg/errors"
+)
+
+// stackPollInterval is the interval between checks of containers health in WaitStackHealthy.
+const stackPollInterval = 500 * time.Millisecond
+
+var (
+	errWaitStackHealthyTimeout = errors.New("stack containers healthy wait timeout")
+	errEmptyStack              = errors.New("no containers match the label")
+)
+
+// WaitStackHealthy waits until all containers labeled with `labelKey=labelValue`, e.g. the run id label,
+// are running and healthy. Containers without healthcheck are considered healthy once they are running.
+// Containers are listed on each check, so that containers created while waiting are taken into account.
+// On timeout, the returned error lists containers which are still not healthy together with their statuses.
+func WaitStackHealthy(ctx context.Context, labelKey, labelValue string, timeout time.Duration) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	filters := dockerContainerFilters.NewArgs(dockerContainerFilters.Arg("label", labelKey+"="+labelValue))
+	deadline := nowFn().Add(timeout)
+	for {
+		pending, err := pendingStackContainers(ctx, c, filters)
+		switch {
+		case err != nil:
+			return errors.Wrapf(err, "%s=%s", labelKey, labelValue)
+		case len(pending) == 0:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !nowFn().Before(deadline):
+			return errors.Wrapf(errWaitStackHealthyTimeout, "not healthy: %s", strings.Join(pending, ", "))
+		}
+		sleepFn(stackPollInterval)
+	}
+}
+
+// pendingStackContainers returns names and statuses of the listed containers which are not healthy yet.
+func pendingStackContainers(ctx context.Context, c client, filters dockerContainerFilters.Args) ([]string, error) {
+	containers, err := c.listContainers(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(containers) == 0 {
+		return nil, errEmptyStack
+	}
+	var pending []string
+	for _, listed := range containers {
+		data, err := c.inspectContainer(ctx, listed.ID)
+		if err != nil {
+			return nil, err
+		}
+		if status := stackContainerStatus(data); status != types.Healthy {
+			pending = append(pending, stackContainerName(listed)+" ("+status+")")
+		}
+	}
+	return pending, nil
+}
+
+// stackContainerStatus returns container health status, its state if it is not running, or [types.Healthy]
+// if it is running without healthcheck.
+func stackContainerStatus(data types.ContainerJSON) string {
+	if data.ContainerJSONBase == nil || data.State == nil {
+		return "unknown"
+	}
+	if !data.State.Running {
+		return data.State.Status
+	}
+	if data.State.Health == nil || data.State.Health.Status == types.NoHealthcheck || len(data.State.Health.Status) == 0 {
+		return types.Healthy
+	}
+	return data.State.Health.Status
+}
+
+// stackContainerName returns the listed container name or its id if the container has no name.
+func stackContainerName(listed types.Container) string {
+	if len(listed.Names) > 0 {
+		return strings.TrimPrefix(listed.Names[0], "/")
+	}
+	return listed.ID
+}
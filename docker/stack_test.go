@@ -0,0 +1,96 @@
+package docker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockedHealthInspect returns mocked container low-level information with the given state and health status.
+// Empty health status means that the container has no healthcheck.
+func mockedHealthInspect(status, health string) types.ContainerJSON {
+	state := &types.ContainerState{Status: status, Running: status == "running"}
+	if len(health) > 0 {
+		state.Health = &types.Health{Status: health}
+	}
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: state}}
+}
+
+func Test_WaitStackHealthy(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer useFakeClock()()
+	stack := []types.Container{
+		{ID: "db", Names: []string{"/db"}},
+		{ID: "cache", Names: []string{"/cache"}},
+		{ID: "api", Names: []string{"/api"}},
+	}
+	tests := []struct {
+		name          string
+		listed        []types.Container
+		inspects      map[string][]types.ContainerJSON
+		expectedError error
+		expectedText  string
+	}{
+		{
+			"all_healthy",
+			stack,
+			map[string][]types.ContainerJSON{
+				"db":    {mockedHealthInspect("running", types.Healthy)},
+				"cache": {mockedHealthInspect("running", types.Healthy)},
+				"api":   {mockedHealthInspect("running", "")},
+			},
+			nil,
+			"",
+		},
+		{
+			"healthy_after_starting",
+			stack,
+			map[string][]types.ContainerJSON{
+				"db": {
+					mockedHealthInspect("running", types.Starting),
+					mockedHealthInspect("running", types.Starting),
+					mockedHealthInspect("running", types.Healthy),
+				},
+				"cache": {mockedHealthInspect("created", ""), mockedHealthInspect("running", types.Healthy)},
+				"api":   {mockedHealthInspect("running", "")},
+			},
+			nil,
+			"",
+		},
+		{
+			"timeout",
+			stack,
+			map[string][]types.ContainerJSON{
+				"db":    {mockedHealthInspect("running", types.Healthy)},
+				"cache": {mockedHealthInspect("running", types.Starting)},
+				"api":   {mockedHealthInspect("running", types.Unhealthy)},
+			},
+			errWaitStackHealthyTimeout,
+			"not healthy: cache (starting), api (unhealthy)",
+		},
+		{
+			"no_containers",
+			[]types.Container{},
+			nil,
+			errEmptyStack,
+			"testutils.run=run-1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			mockedContainerListValues = newContainerListMockValues(containerListMockValue{test.listed, nil})
+			mockedContainerInspectSequences = test.inspects
+			err := WaitStackHealthy(context.Background(), labelRunID, "run-1", 10*time.Second)
+			require.ErrorIs(t, err, test.expectedError)
+			if len(test.expectedText) > 0 {
+				require.ErrorContains(t, err, test.expectedText)
+			}
+			require.Equal(t, []string{labelRunID + "=run-1"}, mockedContainerListOptions.Filters.Get("label"))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"text/template"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/pkg/errors"
+)
+
+var (
+	errHostPortNotResolved = errors.New("host port cannot be resolved before container start")
+	errEnvNotSet           = errors.New("environment variable is not set")
+)
+
+// templateData is passed to Go templates in container string options. It allows referencing container host ports
+// and environment variables, e.g. `{{ .HostPort 9092 }}`, `{{ .Endpoint 5432 }}`, or `{{ .Env "POSTGRES_USER" }}`.
+type templateData struct {
+	options *Options
+	// ports holds host ports bound to container ports. It is nil before container start.
+	ports nat.PortMap
+}
+
+// HostPort returns the host port bound to the given container port. Before container start, only ports with
+// explicitly specified host ports in [Options.ExposedPorts] can be resolved.
+func (d templateData) HostPort(containerPort any) (string, error) {
+	port := portWithProtocol(containerPort)
+	if d.ports != nil {
+		if bindings := d.ports[nat.Port(port)]; len(bindings) > 0 {
+			return bindings[0].HostPort, nil
+		}
+		return "", errors.Wrap(errPortNotMapped, port)
+	}
+	for _, exposedPort := range d.options.ExposedPorts {
+		hostPort, mappedPort, ok := strings.Cut(exposedPort, ":")
+		if !ok {
+			continue
+		}
+		if !strings.Contains(mappedPort, "/") {
+			mappedPort += "/tcp"
+		}
+		if mappedPort == port && len(hostPort) > 0 {
+			return hostPort, nil
+		}
+	}
+	return "", errors.Wrap(errHostPortNotResolved, port)
+}
+
+// Endpoint returns `host:port` address of the given container port on host, e.g. for connection strings.
+func (d templateData) Endpoint(containerPort any) (string, error) {
+	port, err := d.HostPort(containerPort)
+	if err != nil {
+		return "", err
+	}
+	hostIP := ""
+	if bindings := d.ports[nat.Port(portWithProtocol(containerPort))]; len(bindings) > 0 {
+		hostIP = bindings[0].HostIP
+	}
+	host, err := endpointHost(hostIP)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// portWithProtocol returns the given container port with the protocol, `tcp` by default, e.g. `5432/tcp`.
+func portWithProtocol(containerPort any) string {
+	port := fmt.Sprint(containerPort)
+	if !strings.Contains(port, "/") {
+		port += "/tcp"
+	}
+	return port
+}
+
+// Env returns the value of the given container environment variable. If the variable is set several times,
+// the last value is returned, the same way as by [NormalizeEnv].
+func (d templateData) Env(name string) (string, error) {
+	variables := d.options.EnvironmentVariables
+	for i := len(variables) - 1; i >= 0; i-- {
+		if key, value, _ := strings.Cut(variables[i], "="); key == name {
+			return value, nil
+		}
+	}
+	return "", errors.Wrap(errEnvNotSet, name)
+}
+
+// renderTemplate renders Go template placeholders in the given text. Text without placeholders is returned as is.
+func renderTemplate(text string, data any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	t, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err = t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// renderOptions renders template placeholders in container options which are used on container creation:
+// healthcheck command, environment variables, command, and entrypoint.
+func renderOptions(options *Options) (Options, error) {
+	rendered := *options
+	data := templateData{options: options}
+	var err error
+	if rendered.Healthcheck, err = renderTemplate(options.Healthcheck, data); err != nil {
+		return Options{}, err
+	}
+	for _, field := range []*[]string{&rendered.EnvironmentVariables, &rendered.Cmd, &rendered.Entrypoint} {
+		if *field, err = renderTemplates(*field, data); err != nil {
+			return Options{}, err
+		}
+	}
+	return rendered, nil
+}
+
+// renderTemplates renders template placeholders in the given values into a new slice, so that the original values
+// can be rendered again, e.g. on container re-creation. Nil values are returned as is.
+func renderTemplates(values []string, data any) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	rendered := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if rendered[i], err = renderTemplate(value, data); err != nil {
+			return nil, err
+		}
+	}
+	return rendered, nil
+}
+
+// render renders template placeholders in the given text using data of the started container.
+func (c *container) render(ctx context.Context, text string) (string, error) {
+	return c.renderWith(ctx, text, func(data templateData) any { return data })
+}
+
+// renderWith renders template placeholders in the given text using data of the started container extended
+// with the given function, e.g. with command arguments.
+func (c *container) renderWith(ctx context.Context, text string, extend func(data templateData) any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	data, err := c.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+	ports := nat.PortMap{}
+	if data.NetworkSettings != nil && data.NetworkSettings.Ports != nil {
+		ports = data.NetworkSettings.Ports
+	}
+	return renderTemplate(text, extend(templateData{options: &c.options, ports: ports}))
+}
@@ -0,0 +1,94 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_renderTemplate(t *testing.T) {
+	options := Options{
+		EnvironmentVariables: []string{"POSTGRES_USER=postgres", "EMPTY=", "DSN=a=b", "LEVEL=info", "LEVEL=debug"},
+		ExposedPorts:         []string{"19092:9092", ":8080", "5353:53/udp"},
+	}
+	startedPorts := nat.PortMap{"9092/tcp": []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: "32768"}}}
+	tests := []struct {
+		name          string
+		text          string
+		ports         nat.PortMap
+		expected      string
+		expectedError error
+	}{
+		{"no_placeholders", "pg_isready -U postgres", nil, "pg_isready -U postgres", nil},
+		{"env", `pg_isready -U {{ .Env "POSTGRES_USER" }}`, nil, "pg_isready -U postgres", nil},
+		{"env_empty_value", `[{{ .Env "EMPTY" }}]`, nil, "[]", nil},
+		{"env_value_with_equal_sign", `{{ .Env "DSN" }}`, nil, "a=b", nil},
+		{"env_duplicate_last_wins", `{{ .Env "LEVEL" }}`, nil, "debug", nil},
+		{"env_not_set", `{{ .Env "UNKNOWN" }}`, nil, "", errEnvNotSet},
+		{"host_port_static", "PLAINTEXT://localhost:{{ .HostPort 9092 }}", nil, "PLAINTEXT://localhost:19092", nil},
+		{"host_port_static_udp", `{{ .HostPort "53/udp" }}`, nil, "5353", nil},
+		{"host_port_ephemeral_before_start", "{{ .HostPort 8080 }}", nil, "", errHostPortNotResolved},
+		{"host_port_not_exposed_before_start", "{{ .HostPort 5432 }}", nil, "", errHostPortNotResolved},
+		{"host_port_started", "{{ .HostPort 9092 }}", startedPorts, "32768", nil},
+		{"host_port_not_mapped_started", "{{ .HostPort 8080 }}", startedPorts, "", errPortNotMapped},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rendered, err := renderTemplate(test.text, templateData{options: &options, ports: test.ports})
+			require.ErrorIs(t, err, test.expectedError)
+			require.Equal(t, test.expected, rendered)
+		})
+	}
+}
+
+func Test_renderTemplateSyntaxError(t *testing.T) {
+	_, err := renderTemplate("{{ .HostPort ", templateData{options: &Options{}})
+	require.Error(t, err)
+}
+
+func Test_createContainerRendersOptions(t *testing.T) {
+	resetMocks()
+	c := &defaultClient{handler: &mockedDockerClient{}}
+	options := Options{
+		Healthcheck:          `pg_isready -U {{ .Env "POSTGRES_USER" }}`,
+		EnvironmentVariables: []string{"POSTGRES_USER=postgres", "ADVERTISED=localhost:{{ .HostPort 5432 }}"},
+		ExposedPorts:         []string{"5433:5432"},
+		Cmd:                  []string{"postgres", "-c", `application_name={{ .Env "POSTGRES_USER" }}`},
+		Entrypoint:           []string{"docker-entrypoint.sh", "--port={{ .HostPort 5432 }}"},
+	}
+	_, err := c.createContainer(context.Background(), mockedImageName, &options)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ADVERTISED=localhost:5433", "POSTGRES_USER=postgres"}, mockedContainerCreateConfig.Env)
+	require.Equal(t, []string{"CMD-SHELL", "pg_isready -U postgres"}, mockedContainerCreateConfig.Healthcheck.Test)
+	require.Equal(t, strslice.StrSlice{"postgres", "-c", "application_name=postgres"}, mockedContainerCreateConfig.Cmd)
+	require.Equal(t, strslice.StrSlice{"docker-entrypoint.sh", "--port=5433"}, mockedContainerCreateConfig.Entrypoint)
+	// Options are not modified, so that placeholders can be rendered again on re-creation.
+	require.Equal(t, "ADVERTISED=localhost:{{ .HostPort 5432 }}", options.EnvironmentVariables[1])
+	require.Equal(t, "--port={{ .HostPort 5432 }}", options.Entrypoint[1])
+
+	options.EnvironmentVariables = []string{"POSTGRES_USER=postgres", "ADVERTISED=localhost:{{ .HostPort 9092 }}"}
+	_, err = c.createContainer(context.Background(), mockedImageName, &options)
+	require.ErrorIs(t, err, errHostPortNotResolved)
+}
+
+func Test_ResetDatabaseRendersCommand(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	mockedContainerInspect = types.ContainerJSON{
+		NetworkSettings: &types.NetworkSettings{NetworkSettingsBase: types.NetworkSettingsBase{Ports: nat.PortMap{
+			"5432/tcp": []nat.PortBinding{{HostPort: "32770"}},
+		}}},
+	}
+	dc := NewDatabaseContainerWithOptions(
+		mockedImageName,
+		Database{Name: "postgres", ResetCommand: "reset --port={{ .HostPort 5432 }}"},
+		Options{Name: mockedContainerName},
+	)
+	require.NoError(t, dc.ResetDatabase(context.Background()))
+	require.Equal(t, []string{"bash", "-c", "reset --port=32770"}, mockedExecConfigs[0].Cmd)
+}
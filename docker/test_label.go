@@ -0,0 +1,40 @@
+package docker
+
+import (
+	"context"
+	"testing"
+)
+
+// labelTest holds the name of the test which has created the container.
+const labelTest = "org.testutils/test"
+
+// testNameKey is the context key of the test name set using WithTestName.
+type testNameKey struct{}
+
+// WithTestName returns a copy of ctx carrying the given test name. Containers created with the returned context
+// are labeled with `org.testutils/test=<name>`, so that a leaked container can be attributed to the test which
+// has created it, e.g. when containers are created in helpers shared by many tests. If [Options.TB] is set,
+// the test name is taken from it, unless set in the context.
+func WithTestName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, testNameKey{}, name)
+}
+
+// testNameLabel returns the test name label value: the name set in ctx using WithTestName, or the given test name.
+// Subtest separators, spaces, and other characters unsafe in labels are replaced with `_`, e.g. `TestA/case one`
+// is replaced with `TestA_case_one`. Only the label is derived from the test name, container names are not: use
+// SanitizeName to set [Options.Name] from it.
+func testNameLabel(ctx context.Context, tb testing.TB) string {
+	name, _ := ctx.Value(testNameKey{}).(string)
+	if len(name) == 0 && tb != nil {
+		name = tb.Name()
+	}
+	return unsafeNameChars.ReplaceAllString(name, "_")
+}
+
+// describeContainer returns the given container id followed by the test name label, if set.
+func describeContainer(id string, labels map[string]string) string {
+	if name := labels[labelTest]; len(name) > 0 {
+		return id + " (test " + name + ")"
+	}
+	return id
+}
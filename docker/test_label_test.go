@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_createContainerTestLabel(t *testing.T) {
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		tb            testing.TB
+		expectedLabel string
+	}{
+		{"not_set", context.Background(), nil, ""},
+		{"context", WithTestName(context.Background(), "TestOrders"), nil, "TestOrders"},
+		{"subtest", WithTestName(context.Background(), "TestOrders/create order #1"), nil, "TestOrders_create_order_1"},
+		// Test names are not turned into container names, e.g. short and leading `_` names are kept.
+		{"label_only", WithTestName(context.Background(), "_x"), nil, "_x"},
+		{"tb", context.Background(), t, "Test_createContainerTestLabel"},
+		{"context_overrides_tb", WithTestName(context.Background(), "TestHelper"), t, "TestHelper"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			c := &defaultClient{handler: &mockedDockerClient{}}
+			_, err := c.createContainer(test.ctx, mockedImageName, &Options{Name: mockedContainerName, TB: test.tb})
+			require.NoError(t, err)
+			label, labeled := mockedContainerCreateConfig.Labels[labelTest]
+			require.Equal(t, len(test.expectedLabel) > 0, labeled)
+			require.Equal(t, test.expectedLabel, label)
+			require.Equal(t, "true", mockedContainerCreateConfig.Labels[labelManaged])
+		})
+	}
+}
+
+func Test_CleanupAllTestLabel(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	resetMocks()
+	errRemoveMock := errors.New("mockedContainerRemoveError")
+	mockedContainerListValues = newContainerListMockValues(containerListMockValue{[]types.Container{
+		{ID: "first", Labels: map[string]string{labelTest: "TestOrders_create"}},
+		{ID: "second"},
+	}, nil})
+	mockedContainerRemoveErrors = map[string]error{"first": errRemoveMock, "second": errRemoveMock}
+	err := CleanupAll(context.Background())
+	require.ErrorIs(t, err, errCleanup)
+	require.ErrorContains(t, err, "[first (test TestOrders_create): mockedContainerRemoveError second: mockedContainerRemoveError]")
+}
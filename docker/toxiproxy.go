@@ -0,0 +1,267 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
+)
+
+const (
+	// toxiproxyImage is the image of the toxiproxy container started by ProxiedPort.
+	toxiproxyImage = "ghcr.io/shopify/toxiproxy:2.5.0"
+	// toxiproxyAPIPort is toxiproxy HTTP API container port.
+	toxiproxyAPIPort = "8474"
+	// toxiproxyFirstPort is the first of toxiproxyPorts container ports proxies listen on.
+	toxiproxyFirstPort = 20000
+	toxiproxyPorts     = 16
+	// toxiproxyNameFormat is the format of the toxiproxy container name: process id.
+	toxiproxyNameFormat = "testutils-toxiproxy-%d"
+	// toxiproxyStartTimeout is the time to wait for toxiproxy API to become available after the container start.
+	toxiproxyStartTimeout = 30 * time.Second
+)
+
+var (
+	// toxiproxy holds the toxiproxy server shared by proxies created with ProxiedPort, guarded by toxiproxyMu.
+	toxiproxy   *toxiproxyServer
+	toxiproxyMu sync.Mutex
+
+	errNoFreeProxyPorts = errors.New("all toxiproxy ports are in use")
+	errNoTargetNetwork  = errors.New("target container is not connected to any network")
+)
+
+// Proxy is a controllable TCP proxy between the test and a container port, created with [ProxiedPort].
+type Proxy interface {
+	// Endpoint returns the proxy address on host, e.g. `localhost:49160`, to be used instead of the container port.
+	Endpoint() string
+	// AddLatency delays all data sent from the container through the proxy by the given duration.
+	AddLatency(ctx context.Context, d time.Duration) error
+	// Cut closes all proxy connections and makes the proxy refuse new ones.
+	Cut(ctx context.Context) error
+	// Restore removes all added latencies and makes the proxy accept connections again.
+	Restore(ctx context.Context) error
+	// Close deletes the proxy and releases its port.
+	Close(ctx context.Context) error
+}
+
+// toxiproxyServer holds a started toxiproxy container and the bookkeeping of its proxy ports.
+type toxiproxyServer struct {
+	container *container
+	api       *toxiproxyAPI
+	mu        sync.Mutex
+	// used marks proxy ports in use, indexed by the port offset from toxiproxyFirstPort.
+	used [toxiproxyPorts]bool
+}
+
+// proxy holds a toxiproxy proxy data. Implements [Proxy] interface.
+type proxy struct {
+	server   *toxiproxyServer
+	name     string
+	endpoint string
+	slot     int
+	mu       sync.Mutex
+	// toxics holds names of the added toxics.
+	toxics []string
+}
+
+// ProxiedPort creates a proxy forwarding a new host port to the given container port of the target container,
+// e.g. to test timeouts and retries by adding latency or cutting connections. Proxies are served by a toxiproxy
+// container which is started on the first call and shared by all proxies. It is removed by [StopToxiproxy].
+// Container port protocol defaults to `tcp`, only tcp ports can be proxied.
+func ProxiedPort(ctx context.Context, target Container, containerPort string) (Proxy, error) {
+	server, err := getToxiproxy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	port := strings.TrimSuffix(containerPort, "/tcp")
+	upstream, err := server.upstream(ctx, target, port)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := server.acquire()
+	if err != nil {
+		return nil, err
+	}
+	listenPort := strconv.Itoa(toxiproxyFirstPort + slot)
+	p := &proxy{server: server, name: "proxy_" + listenPort, slot: slot}
+	if p.endpoint, err = server.container.Endpoint(ctx, listenPort); err != nil {
+		server.release(slot)
+		return nil, err
+	}
+	if err = server.api.createProxy(ctx, p.name, "0.0.0.0:"+listenPort, upstream); err != nil {
+		server.release(slot)
+		return nil, err
+	}
+	return p, nil
+}
+
+// StopToxiproxy stops and removes the toxiproxy container started by [ProxiedPort], if any.
+func StopToxiproxy(ctx context.Context) error {
+	toxiproxyMu.Lock()
+	defer toxiproxyMu.Unlock()
+	if toxiproxy == nil {
+		return nil
+	}
+	if err := toxiproxy.container.StopRemove(ctx); err != nil {
+		return err
+	}
+	toxiproxy = nil
+	return nil
+}
+
+// getToxiproxy returns the shared toxiproxy server, starting its container if needed.
+func getToxiproxy(ctx context.Context) (*toxiproxyServer, error) {
+	toxiproxyMu.Lock()
+	defer toxiproxyMu.Unlock()
+	if toxiproxy != nil {
+		return toxiproxy, nil
+	}
+	ports := []string{":" + toxiproxyAPIPort}
+	for i := 0; i < toxiproxyPorts; i++ {
+		ports = append(ports, ":"+strconv.Itoa(toxiproxyFirstPort+i))
+	}
+	c := NewContainerWithOptions(toxiproxyImage, Options{
+		Name:         fmt.Sprintf(toxiproxyNameFormat, os.Getpid()),
+		ExposedPorts: ports,
+	}).(*container)
+	if err := c.EnsureStarted(ctx); err != nil {
+		return nil, err
+	}
+	apiURL, err := c.HTTPEndpoint(ctx, toxiproxyAPIPort)
+	if err != nil {
+		return nil, err
+	}
+	api := newToxiproxyAPI(apiURL)
+	if err = api.wait(ctx, toxiproxyStartTimeout); err != nil {
+		return nil, err
+	}
+	toxiproxy = &toxiproxyServer{container: c, api: api}
+	return toxiproxy, nil
+}
+
+// upstream returns the target container port address reachable from the toxiproxy container. If the containers
+// do not share a network, the toxiproxy container is connected to the target container network.
+func (s *toxiproxyServer) upstream(ctx context.Context, target Container, port string) (string, error) {
+	targetData, err := target.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+	proxyData, err := s.container.Inspect(ctx)
+	if err != nil {
+		return "", err
+	}
+	targetNetworks := containerNetworks(targetData)
+	if len(targetNetworks) == 0 {
+		return "", errNoTargetNetwork
+	}
+	proxyNetworks := map[string]bool{}
+	for _, name := range containerNetworks(proxyData) {
+		proxyNetworks[name] = true
+	}
+	network := targetNetworks[0]
+	for _, name := range targetNetworks {
+		if proxyNetworks[name] {
+			network = name
+			break
+		}
+	}
+	if !proxyNetworks[network] {
+		if err = ConnectNetwork(ctx, network, s.container.id); err != nil {
+			return "", err
+		}
+	}
+	return net.JoinHostPort(targetData.NetworkSettings.Networks[network].IPAddress, port), nil
+}
+
+// containerNetworks returns sorted names of the networks the container is connected to with an IP address.
+func containerNetworks(data types.ContainerJSON) []string {
+	if data.NetworkSettings == nil {
+		return nil
+	}
+	var names []string
+	for name, settings := range data.NetworkSettings.Networks {
+		if settings != nil && len(settings.IPAddress) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// acquire reserves a free proxy port and returns its offset from toxiproxyFirstPort.
+func (s *toxiproxyServer) acquire() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for slot, used := range s.used {
+		if !used {
+			s.used[slot] = true
+			return slot, nil
+		}
+	}
+	return 0, errNoFreeProxyPorts
+}
+
+// release frees the proxy port with the given offset from toxiproxyFirstPort.
+func (s *toxiproxyServer) release(slot int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used[slot] = false
+}
+
+// Endpoint returns the proxy address on host.
+func (p *proxy) Endpoint() string {
+	return p.endpoint
+}
+
+// AddLatency adds a latency toxic to the proxy downstream.
+func (p *proxy) AddLatency(ctx context.Context, d time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	toxic := toxiproxyToxic{
+		Name:       "latency_" + strconv.Itoa(len(p.toxics)),
+		Type:       "latency",
+		Stream:     "downstream",
+		Toxicity:   1,
+		Attributes: map[string]any{"latency": d.Milliseconds()},
+	}
+	if err := p.server.api.addToxic(ctx, p.name, toxic); err != nil {
+		return err
+	}
+	p.toxics = append(p.toxics, toxic.Name)
+	return nil
+}
+
+// Cut disables the proxy.
+func (p *proxy) Cut(ctx context.Context) error {
+	return p.server.api.setProxyEnabled(ctx, p.name, false)
+}
+
+// Restore removes the added toxics and enables the proxy.
+func (p *proxy) Restore(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.toxics) > 0 {
+		if err := p.server.api.removeToxic(ctx, p.name, p.toxics[0]); err != nil {
+			return err
+		}
+		p.toxics = p.toxics[1:]
+	}
+	return p.server.api.setProxyEnabled(ctx, p.name, true)
+}
+
+// Close deletes the proxy and releases its port.
+func (p *proxy) Close(ctx context.Context) error {
+	if err := p.server.api.deleteProxy(ctx, p.name); err != nil {
+		return err
+	}
+	p.server.release(p.slot)
+	return nil
+}
@@ -0,0 +1,124 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// toxiproxyAPIPollInterval is the interval between toxiproxy API availability checks.
+	toxiproxyAPIPollInterval = 200 * time.Millisecond
+	// toxiproxyAPIErrorLimit limits the size of toxiproxy API error response body included in errors.
+	toxiproxyAPIErrorLimit = 1024
+)
+
+var (
+	errToxiproxyAPI            = errors.New("toxiproxy API request failed")
+	errToxiproxyAPIWaitTimeout = errors.New("toxiproxy API wait timeout")
+)
+
+// toxiproxyAPI is a minimal client of toxiproxy HTTP API, see https://github.com/Shopify/toxiproxy#http-api.
+type toxiproxyAPI struct {
+	// baseURL holds toxiproxy API address, e.g. `http://localhost:8474`.
+	baseURL    string
+	httpClient *http.Client
+}
+
+// toxiproxyProxy holds toxiproxy proxy attributes.
+type toxiproxyProxy struct {
+	Name     string `json:"name,omitempty"`
+	Listen   string `json:"listen,omitempty"`
+	Upstream string `json:"upstream,omitempty"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// toxiproxyToxic holds toxiproxy toxic attributes.
+type toxiproxyToxic struct {
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Stream     string         `json:"stream"`
+	Toxicity   float64        `json:"toxicity"`
+	Attributes map[string]any `json:"attributes"`
+}
+
+// newToxiproxyAPI returns a toxiproxy API client for the given API address.
+func newToxiproxyAPI(baseURL string) *toxiproxyAPI {
+	return &toxiproxyAPI{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// do sends a request with the given body encoded as JSON and returns an error if the response status is not 2xx.
+func (a *toxiproxyAPI) do(ctx context.Context, method, path string, body any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, toxiproxyAPIErrorLimit))
+		return errors.Wrapf(errToxiproxyAPI, "%s %s: %d %s", method, path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body) // nolint: errcheck
+	return nil
+}
+
+// wait waits until toxiproxy API responds or the timeout expires.
+func (a *toxiproxyAPI) wait(ctx context.Context, timeout time.Duration) error {
+	deadline := nowFn().Add(timeout)
+	for {
+		err := a.do(ctx, http.MethodGet, "/version", nil)
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !nowFn().Before(deadline):
+			return errors.Wrapf(errToxiproxyAPIWaitTimeout, "last error: %v", err)
+		}
+		sleepFn(toxiproxyAPIPollInterval)
+	}
+}
+
+// createProxy creates a new enabled proxy.
+func (a *toxiproxyAPI) createProxy(ctx context.Context, name, listen, upstream string) error {
+	return a.do(ctx, http.MethodPost, "/proxies", toxiproxyProxy{Name: name, Listen: listen, Upstream: upstream, Enabled: true})
+}
+
+// setProxyEnabled enables or disables the proxy. A disabled proxy closes all connections and stops listening.
+func (a *toxiproxyAPI) setProxyEnabled(ctx context.Context, name string, enabled bool) error {
+	return a.do(ctx, http.MethodPost, "/proxies/"+name, toxiproxyProxy{Enabled: enabled})
+}
+
+// deleteProxy deletes the proxy.
+func (a *toxiproxyAPI) deleteProxy(ctx context.Context, name string) error {
+	return a.do(ctx, http.MethodDelete, "/proxies/"+name, nil)
+}
+
+// addToxic adds the toxic to the proxy.
+func (a *toxiproxyAPI) addToxic(ctx context.Context, proxy string, toxic toxiproxyToxic) error {
+	return a.do(ctx, http.MethodPost, "/proxies/"+proxy+"/toxics", toxic)
+}
+
+// removeToxic removes the toxic from the proxy.
+func (a *toxiproxyAPI) removeToxic(ctx context.Context, proxy, toxic string) error {
+	return a.do(ctx, http.MethodDelete, "/proxies/"+proxy+"/toxics/"+toxic, nil)
+}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeToxiproxy is an in-memory fake of toxiproxy HTTP API.
+type fakeToxiproxy struct {
+	mu      sync.Mutex
+	proxies map[string]*toxiproxyProxy
+	toxics  map[string][]toxiproxyToxic
+	// unavailable is the number of /version requests answered with 503 status.
+	unavailable int
+}
+
+// newFakeToxiproxy starts a new fake toxiproxy HTTP API server.
+func newFakeToxiproxy(t *testing.T) (*fakeToxiproxy, *httptest.Server) {
+	f := &fakeToxiproxy{proxies: map[string]*toxiproxyProxy{}, toxics: map[string][]toxiproxyToxic{}}
+	server := httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeToxiproxy) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/version":
+		if f.unavailable > 0 {
+			f.unavailable--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("2.5.0")) // nolint: errcheck
+	case r.Method == http.MethodPost && len(parts) == 1:
+		var p toxiproxyProxy
+		json.NewDecoder(r.Body).Decode(&p) // nolint: errcheck
+		if _, found := f.proxies[p.Name]; found {
+			http.Error(w, `{"error":"proxy already exists"}`, http.StatusConflict)
+			return
+		}
+		f.proxies[p.Name] = &p
+		w.WriteHeader(http.StatusCreated)
+	case len(parts) >= 2 && f.proxies[parts[1]] == nil:
+		http.Error(w, `{"error":"proxy not found"}`, http.StatusNotFound)
+	case r.Method == http.MethodPost && len(parts) == 2:
+		var p toxiproxyProxy
+		json.NewDecoder(r.Body).Decode(&p) // nolint: errcheck
+		f.proxies[parts[1]].Enabled = p.Enabled
+	case r.Method == http.MethodDelete && len(parts) == 2:
+		delete(f.proxies, parts[1])
+		delete(f.toxics, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && len(parts) == 3:
+		var toxic toxiproxyToxic
+		json.NewDecoder(r.Body).Decode(&toxic) // nolint: errcheck
+		f.toxics[parts[1]] = append(f.toxics[parts[1]], toxic)
+	case r.Method == http.MethodDelete && len(parts) == 4:
+		toxics := f.toxics[parts[1]][:0]
+		for _, toxic := range f.toxics[parts[1]] {
+			if toxic.Name != parts[3] {
+				toxics = append(toxics, toxic)
+			}
+		}
+		f.toxics[parts[1]] = toxics
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// mockedNetworksInspect returns mocked container low-level information with the given network IP addresses
+// and port bindings.
+func mockedNetworksInspect(id string, networks map[string]string, ports nat.PortMap) types.ContainerJSON {
+	settings := &types.NetworkSettings{
+		NetworkSettingsBase: types.NetworkSettingsBase{Ports: ports},
+		Networks:            map[string]*network.EndpointSettings{},
+	}
+	for name, ip := range networks {
+		settings.Networks[name] = &network.EndpointSettings{IPAddress: ip}
+	}
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{ID: id}, NetworkSettings: settings}
+}
+
+func Test_toxiproxyAPI(t *testing.T) {
+	defer useFakeClock()()
+	fake, server := newFakeToxiproxy(t)
+	api := newToxiproxyAPI(server.URL + "/")
+	ctx := context.Background()
+
+	fake.unavailable = 2
+	require.NoError(t, api.wait(ctx, time.Second))
+	fake.unavailable = 100
+	require.ErrorIs(t, api.wait(ctx, time.Second), errToxiproxyAPIWaitTimeout)
+	fake.unavailable = 0
+
+	require.NoError(t, api.createProxy(ctx, "db", "0.0.0.0:20000", "172.17.0.3:5432"))
+	require.Equal(t, &toxiproxyProxy{Name: "db", Listen: "0.0.0.0:20000", Upstream: "172.17.0.3:5432", Enabled: true}, fake.proxies["db"])
+	err := api.createProxy(ctx, "db", "0.0.0.0:20000", "172.17.0.3:5432")
+	require.ErrorIs(t, err, errToxiproxyAPI)
+	require.ErrorContains(t, err, `POST /proxies: 409 {"error":"proxy already exists"}`)
+
+	require.NoError(t, api.setProxyEnabled(ctx, "db", false))
+	require.False(t, fake.proxies["db"].Enabled)
+	require.NoError(t, api.addToxic(ctx, "db", toxiproxyToxic{Name: "latency_0", Type: "latency"}))
+	require.Len(t, fake.toxics["db"], 1)
+	require.NoError(t, api.removeToxic(ctx, "db", "latency_0"))
+	require.Empty(t, fake.toxics["db"])
+	require.NoError(t, api.deleteProxy(ctx, "db"))
+	require.Empty(t, fake.proxies)
+	require.ErrorIs(t, api.deleteProxy(ctx, "db"), errToxiproxyAPI)
+}
+
+func Test_ProxiedPort(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer func() { toxiproxy = nil }()
+	proxyPorts := nat.PortMap{
+		"20000/tcp": {{HostIP: "0.0.0.0", HostPort: "49160"}},
+		"20001/tcp": {{HostIP: "0.0.0.0", HostPort: "49161"}},
+	}
+	tests := []struct {
+		name                string
+		targetNetworks      map[string]string
+		proxyNetworks       map[string]string
+		expectedUpstream    string
+		expectedConnects    []string
+		expectedCreateError error
+	}{
+		{"shared_network", map[string]string{"bridge": "172.17.0.3"}, map[string]string{"bridge": "172.17.0.2"},
+			"172.17.0.3:5432", nil, nil},
+		{"second_shared_network", map[string]string{"app": "172.20.0.3", "bridge": "172.17.0.3"},
+			map[string]string{"bridge": "172.17.0.2"}, "172.17.0.3:5432", nil, nil},
+		{"not_shared_network", map[string]string{"app": "172.20.0.3"}, map[string]string{"bridge": "172.17.0.2"},
+			"172.20.0.3:5432", []string{"app toxiproxy"}, nil},
+		{"no_target_network", nil, map[string]string{"bridge": "172.17.0.2"}, "", nil, errNoTargetNetwork},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			fake, server := newFakeToxiproxy(t)
+			toxiproxy = &toxiproxyServer{
+				container: &container{id: "toxiproxy", options: Options{Name: "toxiproxy"}},
+				api:       newToxiproxyAPI(server.URL),
+			}
+			mockedContainerInspectSequences = map[string][]types.ContainerJSON{
+				mockedContainerID: {mockedNetworksInspect(mockedContainerID, test.targetNetworks, nil)},
+				"toxiproxy":       {mockedNetworksInspect("toxiproxy", test.proxyNetworks, proxyPorts)},
+			}
+			target := NewContainerWithOptions(mockedImageName, Options{Name: mockedContainerName})
+			ctx := context.Background()
+
+			p, err := ProxiedPort(ctx, target, "5432")
+			require.ErrorIs(t, err, test.expectedCreateError)
+			if err != nil {
+				return
+			}
+			require.Equal(t, "localhost:49160", p.Endpoint())
+			require.Equal(t, test.expectedConnects, mockedNetworkConnects)
+			require.Equal(t, &toxiproxyProxy{Name: "proxy_20000", Listen: "0.0.0.0:20000", Upstream: test.expectedUpstream, Enabled: true},
+				fake.proxies["proxy_20000"])
+
+			require.NoError(t, p.AddLatency(ctx, 250*time.Millisecond))
+			require.NoError(t, p.AddLatency(ctx, time.Second))
+			require.Equal(t, []toxiproxyToxic{
+				{Name: "latency_0", Type: "latency", Stream: "downstream", Toxicity: 1, Attributes: map[string]any{"latency": float64(250)}},
+				{Name: "latency_1", Type: "latency", Stream: "downstream", Toxicity: 1, Attributes: map[string]any{"latency": float64(1000)}},
+			}, fake.toxics["proxy_20000"])
+			require.NoError(t, p.Cut(ctx))
+			require.False(t, fake.proxies["proxy_20000"].Enabled)
+			require.NoError(t, p.Restore(ctx))
+			require.True(t, fake.proxies["proxy_20000"].Enabled)
+			require.Empty(t, fake.toxics["proxy_20000"])
+
+			// The second proxy gets the next port, the port of the closed proxy is reused.
+			second, err := ProxiedPort(ctx, target, "5432/tcp")
+			require.NoError(t, err)
+			require.Equal(t, "localhost:49161", second.Endpoint())
+			require.NoError(t, p.Close(ctx))
+			require.NotContains(t, fake.proxies, "proxy_20000")
+			third, err := ProxiedPort(ctx, target, "5432")
+			require.NoError(t, err)
+			require.Equal(t, "localhost:49160", third.Endpoint())
+		})
+	}
+}
+
+func Test_toxiproxyServerPorts(t *testing.T) {
+	s := &toxiproxyServer{}
+	for i := 0; i < toxiproxyPorts; i++ {
+		slot, err := s.acquire()
+		require.NoError(t, err)
+		require.Equal(t, i, slot)
+	}
+	_, err := s.acquire()
+	require.ErrorIs(t, err, errNoFreeProxyPorts)
+	s.release(3)
+	slot, err := s.acquire()
+	require.NoError(t, err)
+	require.Equal(t, 3, slot)
+}
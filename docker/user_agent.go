@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// modulePath is the path of the module the package belongs to.
+const modulePath = "github.com/ygrebnov/testutils"
+
+var (
+	// userAgent holds the User-Agent set using SetUserAgent, guarded by userAgentMu.
+	userAgent   string
+	userAgentMu sync.RWMutex
+)
+
+// SetUserAgent sets the User-Agent header sent to Docker daemon by clients created afterwards, so that operators
+// of a shared daemon can identify the tool which has created containers. Empty value restores the default one,
+// `testutils/<version>`.
+func SetUserAgent(ua string) {
+	userAgentMu.Lock()
+	defer userAgentMu.Unlock()
+	userAgent = ua
+}
+
+// getUserAgent returns the User-Agent set using SetUserAgent or the default one.
+func getUserAgent() string {
+	userAgentMu.RLock()
+	defer userAgentMu.RUnlock()
+	if len(userAgent) > 0 {
+		return userAgent
+	}
+	return "testutils/" + moduleVersion()
+}
+
+// moduleVersion returns the version of the module the package belongs to, as recorded in the binary build
+// information, or `devel` if it is not known, e.g. in the module own tests.
+func moduleVersion() string {
+	info, found := debug.ReadBuildInfo()
+	if !found {
+		return "devel"
+	}
+	var version string
+	if info.Main.Path == modulePath {
+		version = info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			version = dep.Version
+		}
+	}
+	if len(version) == 0 || version == "(devel)" {
+		return "devel"
+	}
+	return version
+}
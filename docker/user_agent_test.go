@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dockerClient "github.com/docker/docker/client"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newClientUserAgent(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Header().Set("Api-Version", "1.42")
+		_, _ = w.Write([]byte("OK"))
+	}))
+	defer server.Close()
+	previous := newClientFn
+	defer func() { newClientFn = previous }()
+	// Options passed by newClient are applied to a client connected to the test server.
+	newClientFn = func(ops ...dockerClient.Opt) (*dockerClient.Client, error) {
+		return dockerClient.NewClientWithOpts(append(ops, dockerClient.WithHost("tcp://"+server.Listener.Addr().String()))...)
+	}
+	defer SetUserAgent("")
+	tests := []struct {
+		name, userAgent, expected string
+	}{
+		{"default", "", "testutils/devel"},
+		{"custom", "ci-runner/1.2 (job 42)", "ci-runner/1.2 (job 42)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			userAgents = nil
+			SetUserAgent(test.userAgent)
+			c, err := newClient()
+			require.NoError(t, err)
+			defer c.close()
+			_, err = c.(*defaultClient).handler.Ping(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, []string{test.expected}, userAgents)
+		})
+	}
+	cli = nil
+}
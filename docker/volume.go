@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/api/types/volume"
+	dockerClient "github.com/docker/docker/client"
+	"github.com/pkg/errors"
+)
+
+// pruneAllVolumesAPIVersion is the Docker API version since which only anonymous volumes are pruned, unless
+// `all` filter is set.
+const pruneAllVolumesAPIVersion = "1.42"
+
+var errEmptyVolumeName = errors.New("empty volume name")
+
+// ensureVolume creates a Docker volume with the given name and custom labels, labeled as created by the package,
+// if it does not exist. Returns true if the volume has been created.
+func (c *defaultClient) ensureVolume(ctx context.Context, name string, labels map[string]string) (bool, error) {
+	_, err := c.handler.VolumeInspect(ctx, name)
+	if !dockerClient.IsErrNotFound(err) {
+		return false, err
+	}
+	if _, err = c.handler.VolumeCreate(ctx, volume.CreateOptions{Name: name, Labels: containerLabels(labels)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// removeVolume calls Docker client VolumeRemove method.
+func (c *defaultClient) removeVolume(ctx context.Context, name string) error {
+	return c.handler.VolumeRemove(ctx, name, false)
+}
+
+// pruneVolumes removes unused Docker volumes created by the package within the current run.
+func (c *defaultClient) pruneVolumes(ctx context.Context) error {
+	filters := managedFilters()
+	apiVersion, err := c.serverAPIVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if !versions.LessThan(apiVersion, pruneAllVolumesAPIVersion) {
+		filters.Add("all", "true")
+	}
+	_, err = c.handler.VolumesPrune(ctx, filters)
+	return err
+}
+
+// EnsureVolume creates a Docker volume with the given name, if it does not exist. Created volumes are labeled,
+// so that they can be removed with PruneVolumes.
+func EnsureVolume(ctx context.Context, name string) error {
+	if len(name) == 0 {
+		return errEmptyVolumeName
+	}
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	_, err = c.ensureVolume(ctx, name, nil)
+	return err
+}
+
+// PruneVolumes removes unused Docker volumes created by the package with EnsureVolume, e.g. in `TestMain` after
+// all tests have finished. If run id has been set using SetRunID, only volumes created within the run are removed.
+// Volumes created by other tools are left intact.
+func PruneVolumes(ctx context.Context) error {
+	c, err := getClient()
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	return c.pruneVolumes(ctx)
+}
@@ -0,0 +1,65 @@
+package docker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EnsureVolume(t *testing.T) {
+	cli = &defaultClient{handler: &mockedDockerClient{}}
+	defer SetRunID("")
+	tests := []struct {
+		name            string
+		volumeName      string
+		existing        map[string]bool
+		expectedCreates []volume.CreateOptions
+		expectedError   error
+	}{
+		{"created", "pgdata", nil, []volume.CreateOptions{{
+			Name:   "pgdata",
+			Labels: map[string]string{labelManaged: "true", labelRunID: "ci-job-42"},
+		}}, nil},
+		{"existing", "pgdata", map[string]bool{"pgdata": true}, nil, nil},
+		{"empty_name", "", nil, nil, errEmptyVolumeName},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			SetRunID("ci-job-42")
+			mockedVolumes = test.existing
+			require.ErrorIs(t, EnsureVolume(context.Background(), test.volumeName), test.expectedError)
+			require.Equal(t, test.expectedCreates, mockedVolumeCreateOptions)
+		})
+	}
+}
+
+func Test_PruneVolumes(t *testing.T) {
+	defer SetRunID("")
+	tests := []struct {
+		name           string
+		apiVersion     string
+		runID          string
+		expectedLabels []string
+		expectedAll    []string
+	}{
+		{"all_volumes", "1.42", "", []string{labelManaged + "=true"}, []string{"true"}},
+		{"run_scoped", "1.43", "ci-job-42", []string{labelManaged + "=true", labelRunID + "=ci-job-42"}, []string{"true"}},
+		{"old_daemon", "1.41", "", []string{labelManaged + "=true"}, []string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetMocks()
+			cli = &defaultClient{handler: &mockedDockerClient{}}
+			mockedServerAPIVersion = test.apiVersion
+			SetRunID(test.runID)
+			require.NoError(t, PruneVolumes(context.Background()))
+			require.ElementsMatch(t, test.expectedLabels, mockedVolumesPruneFilters.Get("label"))
+			require.Equal(t, test.expectedAll, mockedVolumesPruneFilters.Get("all"))
+		})
+	}
+}
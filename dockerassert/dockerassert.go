@@ -0,0 +1,138 @@
+// Package dockerassert is a collection of test assertions on the state of
+// [github.com/ygrebnov/testutils/docker.Container] objects.
+//
+// Each assertion reports a failure using [testing.TB.Errorf] and returns false if the assertion does not hold.
+// Failure messages include container name, state, and recent container logs.
+package dockerassert // import "github.com/ygrebnov/testutils/dockerassert"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+const (
+	// recentLogLines is the number of container log lines included into failure messages.
+	recentLogLines = 20
+	// errorScanLines is the number of the last container log lines scanned for error-level lines.
+	errorScanLines = 1000
+	dialTimeout    = time.Second
+)
+
+// errorLinePattern matches error-level container log lines.
+var errorLinePattern = regexp.MustCompile(`(?i)\b(error|fatal|panic)\b`)
+
+// Running asserts that container is running.
+// nolint: revive
+func Running(t testing.TB, ctx context.Context, c docker.Container) bool {
+	t.Helper()
+	data, err := c.Inspect(ctx)
+	if err != nil {
+		t.Errorf("cannot inspect container: %v", err)
+		return false
+	}
+	if data.ContainerJSONBase == nil || data.State == nil || !data.State.Running {
+		t.Errorf("container is not running\n%s", describe(ctx, c, data))
+		return false
+	}
+	return true
+}
+
+// Healthy asserts that container healthcheck status is `healthy`.
+// nolint: revive
+func Healthy(t testing.TB, ctx context.Context, c docker.Container) bool {
+	t.Helper()
+	data, err := c.Inspect(ctx)
+	if err != nil {
+		t.Errorf("cannot inspect container: %v", err)
+		return false
+	}
+	switch {
+	case data.ContainerJSONBase == nil || data.State == nil || data.State.Health == nil:
+		t.Errorf("container has no healthcheck\n%s", describe(ctx, c, data))
+		return false
+	case data.State.Health.Status != types.Healthy:
+		t.Errorf("container is not healthy, health status: %s\n%s", data.State.Health.Status, describe(ctx, c, data))
+		return false
+	}
+	return true
+}
+
+// LogContains asserts that container logs contain the given substring.
+// nolint: revive
+func LogContains(t testing.TB, ctx context.Context, c docker.Container, substr string) bool {
+	t.Helper()
+	logs, err := c.Logs(ctx)
+	if err != nil {
+		t.Errorf("cannot get container logs: %v", err)
+		return false
+	}
+	if !strings.Contains(logs, substr) {
+		data, _ := c.Inspect(ctx)
+		t.Errorf("container logs do not contain %q\n%s", substr, describe(ctx, c, data))
+		return false
+	}
+	return true
+}
+
+// PortOpen asserts that the host port bound to the given container port accepts TCP connections.
+// nolint: revive
+func PortOpen(t testing.TB, ctx context.Context, c docker.Container, containerPort string) bool {
+	t.Helper()
+	address, err := c.Endpoint(ctx, containerPort)
+	if err != nil {
+		data, _ := c.Inspect(ctx)
+		t.Errorf("cannot get container port %s address: %v\n%s", containerPort, err, describe(ctx, c, data))
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		data, _ := c.Inspect(ctx)
+		t.Errorf("container port %s (%s) is not open: %v\n%s", containerPort, address, err, describe(ctx, c, data))
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// describe returns container name, state, and recent logs formatted to be included into a failure message.
+func describe(ctx context.Context, c docker.Container, data types.ContainerJSON) string {
+	name, state := "<unknown>", "<unknown>"
+	if data.ContainerJSONBase != nil {
+		name = strings.TrimPrefix(data.Name, "/")
+		if data.State != nil {
+			state = data.State.Status
+		}
+	}
+	title, logs := recentLogs(ctx, c)
+	return fmt.Sprintf("container: %s\nstate: %s\n%s:\n%s", name, state, title, logs)
+}
+
+// recentLogs returns the last error-level container log lines or, if there are none, the last log lines.
+func recentLogs(ctx context.Context, c docker.Container) (string, string) {
+	logs, err := c.LogsWithOptions(ctx, docker.LogsOptions{Tail: errorScanLines, Grep: errorLinePattern})
+	if err == nil && len(strings.TrimSpace(logs)) > 0 {
+		return "error logs", tail(logs, recentLogLines)
+	}
+	if logs, err = c.LogsWithOptions(ctx, docker.LogsOptions{Tail: recentLogLines}); err != nil {
+		return "recent logs", fmt.Sprintf("<cannot get logs: %v>", err)
+	}
+	return "recent logs", tail(logs, recentLogLines)
+}
+
+// tail returns the last n lines of the given text.
+func tail(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,176 @@
+package dockerassert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// stubContainer implements [docker.Container] interface methods used by assertions.
+type stubContainer struct {
+	docker.Container
+	inspect     types.ContainerJSON
+	logs        string
+	endpoint    string
+	endpointErr error
+}
+
+// Inspect is a stubbed [docker.Container] method.
+func (c *stubContainer) Inspect(_ context.Context) (types.ContainerJSON, error) {
+	return c.inspect, nil
+}
+
+// Logs is a stubbed [docker.Container] method.
+func (c *stubContainer) Logs(_ context.Context) (string, error) {
+	return c.logs, nil
+}
+
+// LogsWithOptions is a stubbed [docker.Container] method. Applies Grep and Tail options to the stubbed logs.
+func (c *stubContainer) LogsWithOptions(_ context.Context, options docker.LogsOptions) (string, error) {
+	logs := c.logs
+	if options.Tail > 0 {
+		logs = tail(logs, options.Tail) + "\n"
+	}
+	if options.Grep == nil {
+		return logs, nil
+	}
+	var matched string
+	for _, line := range strings.SplitAfter(logs, "\n") {
+		if options.Grep.MatchString(line) {
+			matched += line
+		}
+	}
+	return matched, nil
+}
+
+// Endpoint is a stubbed [docker.Container] method.
+func (c *stubContainer) Endpoint(_ context.Context, _ string) (string, error) {
+	return c.endpoint, c.endpointErr
+}
+
+// stubTB captures assertion failures.
+type stubTB struct {
+	testing.TB
+	failures []string
+}
+
+// Helper is a stubbed [testing.TB] method.
+func (tb *stubTB) Helper() {}
+
+// Errorf is a stubbed [testing.TB] method.
+func (tb *stubTB) Errorf(format string, args ...any) {
+	tb.failures = append(tb.failures, fmt.Sprintf(format, args...))
+}
+
+// newInspect returns a container inspect result with the given state.
+func newInspect(state *types.ContainerState) types.ContainerJSON {
+	return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{Name: "/test-container", State: state}}
+}
+
+func TestAssertions(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closedListener.Addr().String()
+	closedListener.Close()
+
+	running := newInspect(&types.ContainerState{Status: "running", Running: true})
+	exited := newInspect(&types.ContainerState{Status: "exited"})
+	healthy := newInspect(&types.ContainerState{Status: "running", Running: true, Health: &types.Health{Status: types.Healthy}})
+	starting := newInspect(&types.ContainerState{Status: "running", Running: true, Health: &types.Health{Status: types.Starting}})
+	logs := "line 1\nFATAL: cannot start\n"
+
+	tests := []struct {
+		name            string
+		container       *stubContainer
+		assertion       func(t testing.TB, ctx context.Context, c docker.Container) bool
+		expectedFailure string
+	}{
+		{"running", &stubContainer{inspect: running}, Running, ""},
+		{"running_fail", &stubContainer{inspect: exited, logs: logs}, Running, "container is not running"},
+		{"healthy", &stubContainer{inspect: healthy}, Healthy, ""},
+		{"healthy_fail_starting", &stubContainer{inspect: starting, logs: logs}, Healthy, "health status: starting"},
+		{"healthy_fail_no_healthcheck", &stubContainer{inspect: running, logs: logs}, Healthy, "container has no healthcheck"},
+		{"logContains", &stubContainer{inspect: running, logs: logs}, func(t testing.TB, ctx context.Context, c docker.Container) bool {
+			return LogContains(t, ctx, c, "cannot start")
+		}, ""},
+		{"logContains_fail", &stubContainer{inspect: running, logs: logs}, func(t testing.TB, ctx context.Context, c docker.Container) bool {
+			return LogContains(t, ctx, c, "ready to accept connections")
+		}, `container logs do not contain "ready to accept connections"`},
+		{"portOpen", &stubContainer{inspect: running, endpoint: listener.Addr().String()}, func(t testing.TB, ctx context.Context, c docker.Container) bool {
+			return PortOpen(t, ctx, c, "5432")
+		}, ""},
+		{"portOpen_fail_closed", &stubContainer{inspect: running, logs: logs, endpoint: closedAddress}, func(t testing.TB, ctx context.Context, c docker.Container) bool {
+			return PortOpen(t, ctx, c, "5432")
+		}, "container port 5432 (" + closedAddress + ") is not open"},
+		{"portOpen_fail_not_mapped", &stubContainer{inspect: running, logs: logs, endpointErr: errors.New("not mapped")}, func(t testing.TB, ctx context.Context, c docker.Container) bool {
+			return PortOpen(t, ctx, c, "5432")
+		}, "cannot get container port 5432 address: not mapped"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tb := &stubTB{}
+			result := test.assertion(tb, context.Background(), test.container)
+			if len(test.expectedFailure) == 0 {
+				require.True(t, result)
+				require.Empty(t, tb.failures)
+				return
+			}
+			require.False(t, result)
+			require.Len(t, tb.failures, 1)
+			require.Contains(t, tb.failures[0], test.expectedFailure)
+			require.Contains(t, tb.failures[0], "container: test-container")
+			require.Contains(t, tb.failures[0], "state: "+test.container.inspect.State.Status)
+			require.Contains(t, tb.failures[0], "FATAL: cannot start")
+		})
+	}
+}
+
+func Test_tail(t *testing.T) {
+	lines := make([]string, 30)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i)
+	}
+	require.Equal(t, strings.Join(lines[10:], "\n"), tail(strings.Join(lines, "\n")+"\n", 20))
+	require.Equal(t, "a\nb", tail("a\nb\n", 20))
+}
+
+func Test_describe(t *testing.T) {
+	data := newInspect(&types.ContainerState{Status: "running", Running: true})
+	tests := []struct {
+		name            string
+		logs            string
+		expectedSection string
+		expectedLines   []string
+		unexpectedLines []string
+	}{
+		{"error_lines", "starting\nERROR: no config\nlistening\npanic: nil map\nready\n",
+			"error logs:\n", []string{"ERROR: no config", "panic: nil map"}, []string{"starting", "ready"}},
+		{"no_error_lines", "starting\nlistening\nready\n",
+			"recent logs:\n", []string{"starting", "listening", "ready"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			description := describe(context.Background(), &stubContainer{inspect: data, logs: test.logs}, data)
+			require.Contains(t, description, test.expectedSection)
+			for _, line := range test.expectedLines {
+				require.Contains(t, description, line)
+			}
+			for _, line := range test.unexpectedLines {
+				require.NotContains(t, description, line)
+			}
+		})
+	}
+}
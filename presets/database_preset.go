@@ -2,7 +2,12 @@ package presets
 
 import "github.com/ygrebnov/testutils/docker"
 
-type databaseContainerPreset = preset[docker.DatabaseContainer]
+// databaseContainerPreset represents a type capable of producing preset and customizable [docker.DatabaseContainer]
+// objects, including ones with customized database attributes.
+type databaseContainerPreset interface {
+	preset[docker.DatabaseContainer]
+	asCustomizedDatabaseContainer(options docker.Options, db docker.Database) docker.DatabaseContainer
+}
 
 type defaultDatabaseContainerPreset struct {
 	defaultContainerPreset `yaml:",inline"`
@@ -11,8 +16,20 @@ type defaultDatabaseContainerPreset struct {
 
 // presetDatabase holds database preset inner database data.
 type presetDatabase struct {
-	Name         string `yaml:"name"`
-	ResetCommand string `yaml:"reset_command"`
+	Name          string            `yaml:"name"`
+	Names         []string          `yaml:"names,omitempty"`
+	ResetCommand  string            `yaml:"reset_command"`
+	QueryCommand  string            `yaml:"query_command,omitempty"`
+	Commands      map[string]string `yaml:"commands,omitempty"`
+	ReadyCommand  string            `yaml:"ready_command,omitempty"`
+	Port          string            `yaml:"port,omitempty"`
+	ReadyProbe    string            `yaml:"ready_probe,omitempty"`
+	CreateCommand string            `yaml:"create_command,omitempty"`
+	DropCommand   string            `yaml:"drop_command,omitempty"`
+	// ConnectionString is the connection string template, see [docker.Database.ConnectionString].
+	ConnectionString string `yaml:"connection_string,omitempty"`
+	// Driver is the [database/sql] driver name, see [docker.Database.Driver].
+	Driver string `yaml:"driver,omitempty"`
 }
 
 // asContainer returns a [docker.Container] object with preset attribute values.
@@ -27,9 +44,81 @@ func (p *defaultDatabaseContainerPreset) asCustomizedContainer(options docker.Op
 	return docker.NewDatabaseContainerWithOptions(p.Image.Name, p.getPresetDatabase(), p.combineContainerOptions(options))
 }
 
+// asCustomizedDatabaseContainer returns a [docker.DatabaseContainer] with preset attribute values overwritten by
+// customized options and database attributes.
+func (p *defaultDatabaseContainerPreset) asCustomizedDatabaseContainer(
+	options docker.Options,
+	db docker.Database,
+) docker.DatabaseContainer {
+	return docker.NewDatabaseContainerWithOptions(p.Image.Name, p.combineDatabase(db), p.combineContainerOptions(options))
+}
+
 // nolint: unused
 func (p *defaultDatabaseContainerPreset) getPresetDatabase() docker.Database {
-	return docker.Database{Name: p.Database.Name, ResetCommand: p.Database.ResetCommand}
+	return docker.Database{
+		Name:             p.Database.Name,
+		Names:            p.Database.Names,
+		ResetCommand:     p.Database.ResetCommand,
+		QueryCommand:     p.Database.QueryCommand,
+		Commands:         p.Database.Commands,
+		ReadyCommand:     p.Database.ReadyCommand,
+		Port:             p.Database.Port,
+		ReadyProbe:       p.Database.ReadyProbe,
+		CreateCommand:    p.Database.CreateCommand,
+		DropCommand:      p.Database.DropCommand,
+		ConnectionString: p.Database.ConnectionString,
+		Driver:           p.Database.Driver,
+	}
+}
+
+// combineDatabase returns preset database attributes overwritten by non-empty customized ones. Customized named
+// commands are added to the preset ones, replacing preset commands with the same names.
+func (p *defaultDatabaseContainerPreset) combineDatabase(db docker.Database) docker.Database {
+	combined := p.getPresetDatabase()
+	if len(db.Name) > 0 {
+		combined.Name = db.Name
+	}
+	if len(db.Names) > 0 {
+		combined.Names = db.Names
+	}
+	if len(db.ResetCommand) > 0 {
+		combined.ResetCommand = db.ResetCommand
+	}
+	if len(db.QueryCommand) > 0 {
+		combined.QueryCommand = db.QueryCommand
+	}
+	if len(db.Commands) > 0 {
+		commands := make(map[string]string, len(combined.Commands)+len(db.Commands))
+		for name, command := range combined.Commands {
+			commands[name] = command
+		}
+		for name, command := range db.Commands {
+			commands[name] = command
+		}
+		combined.Commands = commands
+	}
+	if len(db.ReadyCommand) > 0 {
+		combined.ReadyCommand = db.ReadyCommand
+	}
+	if len(db.Port) > 0 {
+		combined.Port = db.Port
+	}
+	if len(db.ReadyProbe) > 0 {
+		combined.ReadyProbe = db.ReadyProbe
+	}
+	if len(db.CreateCommand) > 0 {
+		combined.CreateCommand = db.CreateCommand
+	}
+	if len(db.DropCommand) > 0 {
+		combined.DropCommand = db.DropCommand
+	}
+	if len(db.ConnectionString) > 0 {
+		combined.ConnectionString = db.ConnectionString
+	}
+	if len(db.Driver) > 0 {
+		combined.Driver = db.Driver
+	}
+	return combined
 }
 
 // newDatabaseContainerPreset creates a new `databaseContainerPreset` object.
@@ -38,3 +127,12 @@ func newDatabaseContainerPreset(valuesFile string) databaseContainerPreset {
 	parsePresetValues(valuesFile, p)
 	return p
 }
+
+// newDatabaseContainerPresetWithImage creates a new `databaseContainerPreset` object from the given values file
+// with the image replaced, so that compatible images, e.g. MySQL and Percona Server, share one values file.
+func newDatabaseContainerPresetWithImage(valuesFile, image string) databaseContainerPreset {
+	p := new(defaultDatabaseContainerPreset)
+	parsePresetValues(valuesFile, p)
+	p.Image.Name = image
+	return p
+}
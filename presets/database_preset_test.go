@@ -0,0 +1,37 @@
+package presets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestDatabasePresetNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "postgresql.yaml")
+	content := `container:
+  ports:
+    - "5432:5432"
+image:
+  name: "postgres"
+database:
+  names:
+    - "orders"
+    - "billing"
+  reset_command: "dropdb -f {{ .Database }}; createdb {{ .Database }}"
+  connection_string: "postgres://postgres@{{ .Endpoint 5432 }}/{{ .Database }}"
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	p := new(defaultDatabaseContainerPreset)
+	require.NoError(t, readPresetValues(path, p))
+
+	expectedDatabase := docker.Database{
+		Names:            []string{"orders", "billing"},
+		ResetCommand:     "dropdb -f {{ .Database }}; createdb {{ .Database }}",
+		ConnectionString: "postgres://postgres@{{ .Endpoint 5432 }}/{{ .Database }}",
+	}
+	require.Equal(t, expectedDatabase, p.getPresetDatabase())
+}
@@ -0,0 +1,17 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var etcdPreset = newKeyValueContainerPreset("etcd.yaml")
+
+// NewCustomizedEtcdContainer returns a preset etcd [github.com/ygrebnov/testutils/docker.KeyValueContainer]
+// object with customized options values.
+func NewCustomizedEtcdContainer(options docker.Options) docker.KeyValueContainer {
+	return etcdPreset.asCustomizedContainer(options)
+}
+
+// NewEtcdContainer returns a preset etcd [github.com/ygrebnov/testutils/docker.KeyValueContainer] object
+// listening on port 2379 without authentication. Keys are flushed, set, and read using `etcdctl`.
+func NewEtcdContainer() docker.KeyValueContainer {
+	return etcdPreset.asContainer()
+}
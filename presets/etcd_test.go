@@ -0,0 +1,26 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestEtcdPreset(t *testing.T) {
+	expectedContainer := docker.NewKeyValueContainerWithOptions(
+		"bitnami/etcd:3.5",
+		docker.KeyValueStore{
+			FlushCommand: "etcdctl del --prefix ''",
+			SetCommand:   "etcdctl put -- {{ .Key }} {{ .Value }}",
+			GetCommand:   "etcdctl get --print-value-only -- {{ .Key }}",
+		},
+		docker.Options{
+			Healthcheck:          "etcdctl endpoint health",
+			EnvironmentVariables: []string{"ALLOW_NONE_AUTHENTICATION=yes", "ETCD_ADVERTISE_CLIENT_URLS=http://127.0.0.1:2379"},
+			ExposedPorts:         []string{"2379:2379"},
+		})
+
+	require.Equal(t, expectedContainer, NewEtcdContainer())
+}
@@ -0,0 +1,44 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+type defaultKeyValueContainerPreset struct {
+	defaultContainerPreset `yaml:",inline"`
+	KeyValue               presetKeyValue `yaml:"key_value"`
+}
+
+// presetKeyValue holds key-value store preset client command templates.
+type presetKeyValue struct {
+	FlushCommand string `yaml:"flush_command"`
+	SetCommand   string `yaml:"set_command"`
+	GetCommand   string `yaml:"get_command"`
+}
+
+// asContainer returns a [docker.KeyValueContainer] object with preset attribute values.
+// nolint: unused
+func (p *defaultKeyValueContainerPreset) asContainer() docker.KeyValueContainer {
+	return docker.NewKeyValueContainerWithOptions(p.Image.Name, p.getPresetKeyValueStore(), p.getPresetContainerOptions())
+}
+
+// asCustomizedContainer returns a [docker.KeyValueContainer] with preset attribute values overwritten
+// by customized ones.
+// nolint: unused
+func (p *defaultKeyValueContainerPreset) asCustomizedContainer(options docker.Options) docker.KeyValueContainer {
+	return docker.NewKeyValueContainerWithOptions(p.Image.Name, p.getPresetKeyValueStore(), p.combineContainerOptions(options))
+}
+
+// nolint: unused
+func (p *defaultKeyValueContainerPreset) getPresetKeyValueStore() docker.KeyValueStore {
+	return docker.KeyValueStore{
+		FlushCommand: p.KeyValue.FlushCommand,
+		SetCommand:   p.KeyValue.SetCommand,
+		GetCommand:   p.KeyValue.GetCommand,
+	}
+}
+
+// newKeyValueContainerPreset creates a new key-value store container preset object.
+func newKeyValueContainerPreset(valuesFile string) preset[docker.KeyValueContainer] {
+	p := new(defaultKeyValueContainerPreset)
+	parsePresetValues(valuesFile, p)
+	return p
+}
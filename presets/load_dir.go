@@ -0,0 +1,57 @@
+package presets
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// LoadDirError is returned by [LoadDir] if some of the preset files could not be loaded.
+type LoadDirError struct {
+	// Files maps names of the files which could not be loaded to the corresponding errors.
+	Files map[string]error
+}
+
+// Error returns a message listing the files which could not be loaded.
+func (e *LoadDirError) Error() string {
+	names := make([]string, 0, len(e.Files))
+	for name := range e.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, name+": "+e.Files[name].Error())
+	}
+	return "cannot load presets: " + strings.Join(messages, "; ")
+}
+
+// LoadDir loads container presets from all `*.yaml` files in the given directory. Returned map is keyed by
+// the files base names without extension, e.g. `redis` for `redis.yaml`.
+// Malformed files are skipped and reported in a [LoadDirError], containers loaded from the other files are
+// returned in any case.
+func LoadDir(dir string) (map[string]docker.Container, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	containers := make(map[string]docker.Container, len(paths))
+	loadErr := &LoadDirError{Files: map[string]error{}}
+	for _, path := range paths {
+		p := new(defaultContainerPreset)
+		if err = readPresetValues(path, p); err == nil {
+			err = p.validate()
+		}
+		if err != nil {
+			loadErr.Files[filepath.Base(path)] = err
+			continue
+		}
+		containers[strings.TrimSuffix(filepath.Base(path), ".yaml")] = p.asContainer()
+	}
+	if len(loadErr.Files) > 0 {
+		return containers, loadErr
+	}
+	return containers, nil
+}
@@ -0,0 +1,72 @@
+package presets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"nginx.yaml": "container:\n  ports:\n    - \"8080:80\"\n  dns_search:\n    - \"corp.example.com\"\n" +
+			"  dns_options:\n    - \"ndots:2\"\n  mac_address: \"02:42:ac:11:00:02\"\nimage:\n  name: \"nginx\"\n",
+		"redis.yaml":  "container:\n  env:\n    - name: \"REDIS_PORT\"\n      value: 6379\n  healthcheck: \"redis-cli ping\"\nimage:\n  name: \"redis\"\n",
+		"broken.yaml": "container: [\nimage:\n",
+		"notes.txt":   "not a preset",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	containers, err := LoadDir(dir)
+
+	var loadErr *LoadDirError
+	require.ErrorAs(t, err, &loadErr)
+	require.Len(t, loadErr.Files, 1)
+	require.Contains(t, loadErr.Files, "broken.yaml")
+	require.Equal(t, map[string]docker.Container{
+		"nginx": docker.NewContainerWithOptions("nginx", docker.Options{
+			EnvironmentVariables: []string{},
+			ExposedPorts:         []string{"8080:80"},
+			DNSSearch:            []string{"corp.example.com"},
+			DNSOptions:           []string{"ndots:2"},
+			MacAddress:           "02:42:ac:11:00:02",
+		}),
+		"redis": docker.NewContainerWithOptions("redis", docker.Options{
+			Healthcheck:          "redis-cli ping",
+			EnvironmentVariables: []string{"REDIS_PORT=6379"},
+		}),
+	}, containers)
+}
+
+func TestLoadDirValidation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"no_image.yaml": "container:\n  healthcheck: \"true\"\n",
+		"bad_env.yaml":  "container:\n  env:\n    - name: \"FLAG\"\n      value: true\nimage:\n  name: \"alpine\"\n",
+		"complete.yaml": "image:\n  name: \"alpine\"\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	containers, err := LoadDir(dir)
+
+	var loadErr *LoadDirError
+	require.ErrorAs(t, err, &loadErr)
+	require.ErrorIs(t, loadErr.Files["no_image.yaml"], errEmptyPresetImageName)
+	require.ErrorIs(t, loadErr.Files["bad_env.yaml"], errUnhandledEnvValueType)
+	require.Len(t, containers, 1)
+	require.Contains(t, containers, "complete")
+}
+
+func TestLoadDirEmpty(t *testing.T) {
+	containers, err := LoadDir(t.TempDir())
+	require.NoError(t, err)
+	require.Empty(t, containers)
+}
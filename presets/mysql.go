@@ -0,0 +1,23 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var mysqlPreset = newDatabaseContainerPreset("mysql.yaml")
+
+// NewCustomizedMySQLContainer returns a preset MySQL [github.com/ygrebnov/testutils/docker.DatabaseContainer] object
+// with customized options values.
+func NewCustomizedMySQLContainer(options docker.Options) docker.DatabaseContainer {
+	return mysqlPreset.asCustomizedContainer(options)
+}
+
+// NewCustomizedMySQLContainerWithDatabase returns a preset MySQL [github.com/ygrebnov/testutils/docker.DatabaseContainer]
+// object with customized options and database attributes values. Empty database attributes keep preset values.
+func NewCustomizedMySQLContainerWithDatabase(options docker.Options, db docker.Database) docker.DatabaseContainer {
+	return mysqlPreset.asCustomizedDatabaseContainer(options, db)
+}
+
+// NewMySQLContainer returns a preset MySQL [github.com/ygrebnov/testutils/docker.DatabaseContainer] object
+// listening on port 3306 with `test` database and `root` user password set to `mysql`.
+func NewMySQLContainer() docker.DatabaseContainer {
+	return mysqlPreset.asContainer()
+}
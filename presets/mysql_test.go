@@ -0,0 +1,48 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+var (
+	expectedMySQLDatabase = docker.Database{
+		Name:         "test",
+		ResetCommand: "mysql --user=root --password=mysql --execute='DROP DATABASE IF EXISTS `{{ .Database }}`; CREATE DATABASE `{{ .Database }}`'",
+		QueryCommand: "mysql --user=root --password=mysql --database=test --batch --skip-column-names --execute",
+		Commands: map[string]string{
+			"mysql": "mysql --user=root --password=mysql --database=test --batch --skip-column-names --execute",
+		},
+		ReadyCommand:     "mysqladmin ping --host=127.0.0.1 --user=root --password=mysql --silent",
+		Port:             "3306",
+		ReadyProbe:       "mysql",
+		CreateCommand:    "mysqladmin --user=root --password=mysql create",
+		DropCommand:      "mysqladmin --user=root --password=mysql --force drop",
+		ConnectionString: "root:mysql@tcp({{ .Endpoint 3306 }})/{{ .Database }}",
+		Driver:           "mysql",
+	}
+	expectedMySQLOptions = docker.Options{
+		Healthcheck:          "mysqladmin ping --host=127.0.0.1 --user=root --password=mysql --silent",
+		EnvironmentVariables: []string{"MYSQL_ROOT_PASSWORD=mysql", "MYSQL_DATABASE=test"},
+		ExposedPorts:         []string{"3306:3306"},
+	}
+)
+
+func TestMySQLPreset(t *testing.T) {
+	expectedContainer := docker.NewDatabaseContainerWithOptions("mysql", expectedMySQLDatabase, expectedMySQLOptions)
+
+	require.Equal(t, expectedContainer, NewMySQLContainer())
+}
+
+func TestCustomizedMySQLPresetWithDatabase(t *testing.T) {
+	database := expectedMySQLDatabase
+	database.Name = "app"
+	options := expectedMySQLOptions
+	options.Name = "db"
+	expectedContainer := docker.NewDatabaseContainerWithOptions("mysql", database, options)
+
+	require.Equal(t, expectedContainer, NewCustomizedMySQLContainerWithDatabase(docker.Options{Name: "db"}, docker.Database{Name: "app"}))
+}
@@ -0,0 +1,26 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+// perconaPreset shares MySQL preset values, Percona Server being a drop-in replacement with the same environment
+// variables, client binaries, and port. Only the image differs, e.g. in the default `sql_mode`.
+var perconaPreset = newDatabaseContainerPresetWithImage("mysql.yaml", "percona:8")
+
+// NewCustomizedPerconaContainer returns a preset Percona Server [github.com/ygrebnov/testutils/docker.DatabaseContainer]
+// object with customized options values.
+func NewCustomizedPerconaContainer(options docker.Options) docker.DatabaseContainer {
+	return perconaPreset.asCustomizedContainer(options)
+}
+
+// NewCustomizedPerconaContainerWithDatabase returns a preset Percona Server
+// [github.com/ygrebnov/testutils/docker.DatabaseContainer] object with customized options and database attributes
+// values. Empty database attributes keep preset values.
+func NewCustomizedPerconaContainerWithDatabase(options docker.Options, db docker.Database) docker.DatabaseContainer {
+	return perconaPreset.asCustomizedDatabaseContainer(options, db)
+}
+
+// NewPerconaContainer returns a preset Percona Server [github.com/ygrebnov/testutils/docker.DatabaseContainer] object
+// using `percona:8` image, configured the same way as [NewMySQLContainer] one.
+func NewPerconaContainer() docker.DatabaseContainer {
+	return perconaPreset.asContainer()
+}
@@ -0,0 +1,25 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestPerconaPreset(t *testing.T) {
+	expectedContainer := docker.NewDatabaseContainerWithOptions("percona:8", expectedMySQLDatabase, expectedMySQLOptions)
+
+	require.Equal(t, expectedContainer, NewPerconaContainer())
+	// Sharing values file does not change MySQL preset image.
+	require.Equal(t, docker.NewDatabaseContainerWithOptions("mysql", expectedMySQLDatabase, expectedMySQLOptions), NewMySQLContainer())
+}
+
+func TestCustomizedPerconaPreset(t *testing.T) {
+	options := expectedMySQLOptions
+	options.PullPolicy = docker.PullNever
+	expectedContainer := docker.NewDatabaseContainerWithOptions("percona:8", expectedMySQLDatabase, options)
+
+	require.Equal(t, expectedContainer, NewCustomizedPerconaContainer(docker.Options{PullPolicy: docker.PullNever}))
+}
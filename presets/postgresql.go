@@ -10,6 +10,12 @@ func NewCustomizedPostgresqlContainer(options docker.Options) docker.DatabaseCon
 	return postgresqlPreset.asCustomizedContainer(options)
 }
 
+// NewCustomizedPostgresqlContainerWithDatabase returns a preset [github.com/ygrebnov/testutils/docker.DatabaseContainer]
+// object with customized options and database attributes values. Empty database attributes keep preset values.
+func NewCustomizedPostgresqlContainerWithDatabase(options docker.Options, db docker.Database) docker.DatabaseContainer {
+	return postgresqlPreset.asCustomizedDatabaseContainer(options, db)
+}
+
 // NewPostgresqlContainer returns a preset [github.com/ygrebnov/testutils/docker.DatabaseContainer] object.
 func NewPostgresqlContainer() docker.DatabaseContainer {
 	return postgresqlPreset.asContainer()
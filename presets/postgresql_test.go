@@ -8,18 +8,95 @@ import (
 	"github.com/ygrebnov/testutils/docker"
 )
 
-func TestPostgresqlPreset(t *testing.T) {
-	expectedContainer := docker.NewDatabaseContainerWithOptions(
-		"postgres",
-		docker.Database{
-			Name:         "postgres",
-			ResetCommand: "dropdb -f --username=postgres -e postgres; createdb --username=postgres -e postgres",
+var (
+	expectedPostgresqlDatabase = docker.Database{
+		Name:         "postgres",
+		ResetCommand: "dropdb -f --username=postgres -e {{ .Database }}; createdb --username=postgres -e {{ .Database }}",
+		QueryCommand: "psql --username=postgres --dbname=postgres --tuples-only --no-align --command",
+		Commands: map[string]string{
+			"vacuum":  "vacuumdb --username=postgres --all",
+			"analyze": "vacuumdb --username=postgres --all --analyze-only",
+			"psql":    "psql --username=postgres --dbname=postgres --tuples-only --no-align --command",
 		},
-		docker.Options{
-			Healthcheck:          "pg_isready",
-			EnvironmentVariables: []string{"POSTGRES_USER=postgres", "POSTGRES_PASSWORD=postgres", "PGPORT=5432"},
-			ExposedPorts:         []string{"5432:5432"},
-		})
+		ReadyCommand:     "pg_isready --username=postgres",
+		Port:             "5432",
+		ReadyProbe:       "postgres",
+		CreateCommand:    "createdb --username=postgres",
+		DropCommand:      "dropdb --force --username=postgres",
+		ConnectionString: "postgres://postgres:postgres@{{ .Endpoint 5432 }}/{{ .Database }}?sslmode=disable",
+		Driver:           "pgx",
+	}
+	expectedPostgresqlOptions = docker.Options{
+		Healthcheck:          "pg_isready",
+		EnvironmentVariables: []string{"POSTGRES_USER=postgres", "POSTGRES_PASSWORD=postgres", "PGPORT=5432"},
+		ExposedPorts:         []string{"5432:5432"},
+	}
+)
+
+func TestPostgresqlPreset(t *testing.T) {
+	expectedContainer := docker.NewDatabaseContainerWithOptions("postgres", expectedPostgresqlDatabase, expectedPostgresqlOptions)
 
 	require.Equal(t, expectedContainer, NewPostgresqlContainer())
 }
+
+func TestCustomizedPostgresqlPresetPullPolicy(t *testing.T) {
+	options := expectedPostgresqlOptions
+	options.PullPolicy = docker.PullNever
+	expectedContainer := docker.NewDatabaseContainerWithOptions("postgres", expectedPostgresqlDatabase, options)
+
+	require.Equal(t, expectedContainer, NewCustomizedPostgresqlContainer(docker.Options{PullPolicy: docker.PullNever}))
+}
+
+func TestCustomizedPostgresqlPresetMounts(t *testing.T) {
+	mounts := []docker.Mount{{Source: "/srv/initdb", Target: "/docker-entrypoint-initdb.d", ReadOnly: true}}
+	options := expectedPostgresqlOptions
+	options.Mounts, options.Network = mounts, "backend"
+	expectedContainer := docker.NewDatabaseContainerWithOptions("postgres", expectedPostgresqlDatabase, options)
+
+	require.Equal(t, expectedContainer, NewCustomizedPostgresqlContainer(docker.Options{Mounts: mounts, Network: "backend"}))
+}
+
+func TestCustomizedPostgresqlPresetWithDatabase(t *testing.T) {
+	tests := []struct {
+		name             string
+		database         docker.Database
+		expectedDatabase func(db *docker.Database)
+	}{
+		{"empty", docker.Database{}, func(db *docker.Database) {}},
+		{"name_and_reset", docker.Database{Name: "app", ResetCommand: "dropdb -f app; createdb app"}, func(db *docker.Database) {
+			db.Name = "app"
+			db.ResetCommand = "dropdb -f app; createdb app"
+		}},
+		{"commands", docker.Database{Commands: map[string]string{"psql": "psql --dbname=app --command", "reindex": "reindexdb"}},
+			func(db *docker.Database) {
+				db.Commands = map[string]string{
+					"vacuum":  "vacuumdb --username=postgres --all",
+					"analyze": "vacuumdb --username=postgres --all --analyze-only",
+					"psql":    "psql --dbname=app --command",
+					"reindex": "reindexdb",
+				}
+			}},
+		{"names", docker.Database{Names: []string{"orders", "billing"}}, func(db *docker.Database) {
+			db.Names = []string{"orders", "billing"}
+		}},
+		{"port_and_probe", docker.Database{Port: "5433", ReadyCommand: "true"}, func(db *docker.Database) {
+			db.Port = "5433"
+			db.ReadyCommand = "true"
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			database := expectedPostgresqlDatabase
+			test.expectedDatabase(&database)
+			options := expectedPostgresqlOptions
+			options.Name = "db"
+			expectedContainer := docker.NewDatabaseContainerWithOptions("postgres", database, options)
+
+			require.Equal(t, expectedContainer, NewCustomizedPostgresqlContainerWithDatabase(docker.Options{Name: "db"}, test.database))
+		})
+	}
+	// Preset commands are not modified by customizations.
+	require.Equal(t, docker.NewDatabaseContainerWithOptions("postgres", expectedPostgresqlDatabase, expectedPostgresqlOptions),
+		NewPostgresqlContainer())
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
@@ -32,10 +33,22 @@ type defaultContainerPreset struct {
 
 // presetContainer holds preset container data.
 type presetContainer struct {
-	Name        string               `yaml:"name"`
-	Env         []presetContainerEnv `yaml:"env,omitempty"`
-	Ports       []string             `yaml:"ports,omitempty"`
-	Healthcheck string               `yaml:"healthcheck"`
+	Name         string               `yaml:"name"`
+	Env          []presetContainerEnv `yaml:"env,omitempty"`
+	Ports        []string             `yaml:"ports,omitempty"`
+	Healthcheck  string               `yaml:"healthcheck"`
+	Command      []string             `yaml:"command,omitempty"`
+	DNSSearch    []string             `yaml:"dns_search,omitempty"`
+	DNSOptions   []string             `yaml:"dns_options,omitempty"`
+	MacAddress   string               `yaml:"mac_address,omitempty"`
+	User         string               `yaml:"user,omitempty"`
+	CgroupParent string               `yaml:"cgroup_parent,omitempty"`
+	Runtime      string               `yaml:"runtime,omitempty"`
+	// Healthcheck timing values, e.g. `5s`, override the default probe timing of slow-starting services.
+	HealthcheckInterval    time.Duration `yaml:"healthcheck_interval,omitempty"`
+	HealthcheckTimeout     time.Duration `yaml:"healthcheck_timeout,omitempty"`
+	HealthcheckRetries     int           `yaml:"healthcheck_retries,omitempty"`
+	HealthcheckStartPeriod time.Duration `yaml:"healthcheck_start_period,omitempty"`
 }
 
 // presetContainerEnv holds preset container environment variables data.
@@ -57,20 +70,53 @@ func newContainerPreset(valuesFile string) containerPreset {
 	return p
 }
 
+var (
+	errEmptyPresetImageName  = errors.New("empty preset image name")
+	errUnhandledEnvValueType = errors.New("unhandled preset.env value type")
+)
+
 // parsePresetValues sets given `preset` object attributes with values from the given yaml file.
 func parsePresetValues(valuesFile string, preset any) {
+	dir, err := presetsDir()
+	if err != nil {
+		panic(err)
+	}
+	if err = readPresetValues(filepath.Join(dir, valuesFile), preset); err != nil {
+		panic(err)
+	}
+}
+
+// presetsDir returns the path of the directory preset values files are located in.
+func presetsDir() (string, error) {
 	_, currFile, _, ok := runtime.Caller(0)
 	if !ok {
-		panic(errors.New("cannot locate preset values file"))
+		return "", errors.New("cannot locate preset values file")
 	}
-	valuesFilePath := filepath.Join(filepath.Dir(currFile), valuesFile)
-	valuesData, err := os.ReadFile(valuesFilePath)
+	return filepath.Dir(currFile), nil
+}
+
+// readPresetValues sets given `preset` object attributes with values from the yaml file located at the given path.
+func readPresetValues(path string, preset any) error {
+	valuesData, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	if err := yaml.Unmarshal(valuesData, preset); err != nil {
-		panic(err)
+	return yaml.Unmarshal(valuesData, preset)
+}
+
+// validate checks that preset values can be converted into a [docker.Container] object.
+func (p *defaultContainerPreset) validate() error {
+	if len(p.Image.Name) == 0 {
+		return errEmptyPresetImageName
+	}
+	for _, el := range p.Container.Env {
+		switch el.Value.(type) {
+		case int, string:
+		default:
+			return errors.Wrap(errUnhandledEnvValueType, el.Name)
+		}
 	}
+	return nil
 }
 
 // asContainer returns a [docker.Container] object with preset attribute values.
@@ -97,35 +143,31 @@ func (p *defaultContainerPreset) getPresetContainerOptions() docker.Options {
 		case string:
 			stringVal = typedVal
 		default:
-			panic(errors.New("unhandled preset.env value type"))
+			panic(errors.Wrap(errUnhandledEnvValueType, el.Name))
 		}
 		env = append(env, fmt.Sprintf("%s=%s", el.Name, stringVal))
 	}
 	return docker.Options{
-		Name:                 p.Container.Name,
-		Healthcheck:          p.Container.Healthcheck,
-		EnvironmentVariables: env,
-		ExposedPorts:         p.Container.Ports,
+		Name:                   p.Container.Name,
+		Healthcheck:            p.Container.Healthcheck,
+		EnvironmentVariables:   env,
+		ExposedPorts:           p.Container.Ports,
+		Cmd:                    p.Container.Command,
+		DNSSearch:              p.Container.DNSSearch,
+		DNSOptions:             p.Container.DNSOptions,
+		MacAddress:             p.Container.MacAddress,
+		User:                   p.Container.User,
+		CgroupParent:           p.Container.CgroupParent,
+		Runtime:                p.Container.Runtime,
+		HealthcheckInterval:    p.Container.HealthcheckInterval,
+		HealthcheckTimeout:     p.Container.HealthcheckTimeout,
+		HealthcheckRetries:     p.Container.HealthcheckRetries,
+		HealthcheckStartPeriod: p.Container.HealthcheckStartPeriod,
 	}
 }
 
-// nolint: unused
+// combineContainerOptions returns preset container options overwritten by the customized ones set,
+// see [docker.MergeOptions].
 func (p *defaultContainerPreset) combineContainerOptions(options docker.Options) docker.Options {
-	combinedOptions := p.getPresetContainerOptions()
-	if len(options.Name) > 0 {
-		combinedOptions.Name = options.Name
-	}
-	if len(options.EnvironmentVariables) > 0 {
-		combinedOptions.EnvironmentVariables = options.EnvironmentVariables
-	}
-	if len(options.ExposedPorts) > 0 {
-		combinedOptions.ExposedPorts = options.ExposedPorts
-	}
-	if len(options.Healthcheck) > 0 {
-		combinedOptions.Healthcheck = options.Healthcheck
-	}
-	if options.StartTimeout > 0 {
-		combinedOptions.StartTimeout = options.StartTimeout
-	}
-	return combinedOptions
+	return docker.MergeOptions(p.getPresetContainerOptions(), options)
 }
@@ -0,0 +1,72 @@
+package presets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestReadPresetValues(t *testing.T) {
+	tests := []struct {
+		name             string
+		container        string
+		expectedOptions  docker.Options
+		override         docker.Options
+		expectedCombined docker.Options
+	}{
+		{
+			"user",
+			"  user: \"1000:1000\"\n",
+			docker.Options{EnvironmentVariables: []string{}, User: "1000:1000"},
+			docker.Options{User: "root"},
+			docker.Options{EnvironmentVariables: []string{}, User: "root"},
+		},
+		{
+			"cgroup_parent_and_runtime",
+			"  cgroup_parent: \"testutils.slice\"\n  runtime: \"runsc\"\n",
+			docker.Options{EnvironmentVariables: []string{}, CgroupParent: "testutils.slice", Runtime: "runsc"},
+			docker.Options{Runtime: "runc"},
+			docker.Options{EnvironmentVariables: []string{}, CgroupParent: "testutils.slice", Runtime: "runc"},
+		},
+		{
+			"healthcheck_timing",
+			"  healthcheck: \"curl -f http://localhost:9200\"\n  healthcheck_interval: 5s\n  healthcheck_timeout: 30s\n" +
+				"  healthcheck_retries: 60\n  healthcheck_start_period: 1m\n",
+			docker.Options{
+				Healthcheck:            "curl -f http://localhost:9200",
+				EnvironmentVariables:   []string{},
+				HealthcheckInterval:    5 * time.Second,
+				HealthcheckTimeout:     30 * time.Second,
+				HealthcheckRetries:     60,
+				HealthcheckStartPeriod: time.Minute,
+			},
+			docker.Options{HealthcheckRetries: 10},
+			docker.Options{
+				Healthcheck:            "curl -f http://localhost:9200",
+				EnvironmentVariables:   []string{},
+				HealthcheckInterval:    5 * time.Second,
+				HealthcheckTimeout:     30 * time.Second,
+				HealthcheckRetries:     10,
+				HealthcheckStartPeriod: time.Minute,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "alpine.yaml")
+			content := "container:\n" + test.container + "image:\n  name: \"alpine\"\n"
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+			p := &defaultContainerPreset{}
+			require.NoError(t, readPresetValues(path, p))
+			require.NoError(t, p.validate())
+			require.Equal(t, test.expectedOptions, p.getPresetContainerOptions())
+			require.Equal(t, test.expectedCombined, p.combineContainerOptions(test.override))
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var redisPreset = newKeyValueContainerPreset("redis.yaml")
+
+// NewCustomizedRedisContainer returns a preset Redis [github.com/ygrebnov/testutils/docker.KeyValueContainer]
+// object with customized options values.
+func NewCustomizedRedisContainer(options docker.Options) docker.KeyValueContainer {
+	return redisPreset.asCustomizedContainer(options)
+}
+
+// NewRedisContainer returns a preset Redis [github.com/ygrebnov/testutils/docker.KeyValueContainer] object
+// listening on port 6379. Keys are flushed, set, and read using `redis-cli`.
+func NewRedisContainer() docker.KeyValueContainer {
+	return redisPreset.asContainer()
+}
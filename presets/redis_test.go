@@ -0,0 +1,39 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+var expectedRedisStore = docker.KeyValueStore{
+	FlushCommand: "redis-cli FLUSHALL",
+	SetCommand:   "redis-cli SET {{ .Key }} {{ .Value }}",
+	GetCommand:   "redis-cli --raw GET {{ .Key }}",
+}
+
+func TestRedisPreset(t *testing.T) {
+	expectedContainer := docker.NewKeyValueContainerWithOptions("redis:7", expectedRedisStore, docker.Options{
+		Healthcheck:          "redis-cli ping",
+		EnvironmentVariables: []string{},
+		ExposedPorts:         []string{"6379:6379"},
+	})
+
+	require.Equal(t, expectedContainer, NewRedisContainer())
+}
+
+func TestCustomizedRedisPreset(t *testing.T) {
+	expectedContainer := docker.NewKeyValueContainerWithOptions("redis:7", expectedRedisStore, docker.Options{
+		Name:                 "cache",
+		Healthcheck:          "redis-cli ping",
+		EnvironmentVariables: []string{},
+		ExposedPorts:         []string{"16379:6379"},
+	})
+
+	require.Equal(t, expectedContainer, NewCustomizedRedisContainer(docker.Options{
+		Name:         "cache",
+		ExposedPorts: []string{"16379:6379"},
+	}))
+}
@@ -0,0 +1,17 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var registryPreset = newContainerPreset("registry.yaml")
+
+// NewCustomizedRegistryContainer returns a preset Docker registry [github.com/ygrebnov/testutils/docker.Container]
+// object with customized options values.
+func NewCustomizedRegistryContainer(options docker.Options) docker.Container {
+	return registryPreset.asCustomizedContainer(options)
+}
+
+// NewRegistryContainer returns a preset Docker registry [github.com/ygrebnov/testutils/docker.Container] object.
+// Images can be pushed to the started registry using [github.com/ygrebnov/testutils/docker.PushToRegistry].
+func NewRegistryContainer() docker.Container {
+	return registryPreset.asContainer()
+}
@@ -0,0 +1,28 @@
+//go:build e2e
+
+package presets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// TestRegistryRoundTrip pushes an image to a registry container and pulls it back.
+// It requires a running Docker daemon and is run with `go test -tags e2e ./presets/`.
+func TestRegistryRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	registry := NewCustomizedRegistryContainer(docker.Options{Name: "testutils-e2e-registry", ExposedPorts: []string{":5000"}})
+	require.NoError(t, registry.CreateStart(ctx))
+	defer func() { require.NoError(t, registry.StopRemove(ctx)) }()
+
+	address, err := docker.LocalRegistryAddress(ctx, registry, "5000")
+	require.NoError(t, err)
+	require.NoError(t, docker.PullImage(ctx, "alpine:3.17"))
+	ref, err := docker.PushToRegistry(ctx, "alpine:3.17", address)
+	require.NoError(t, err)
+	require.NoError(t, docker.PullImage(ctx, ref))
+}
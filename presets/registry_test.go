@@ -0,0 +1,21 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestRegistryPreset(t *testing.T) {
+	expectedContainer := docker.NewContainerWithOptions(
+		"registry:2",
+		docker.Options{
+			Healthcheck:          "wget -q --spider http://localhost:5000/v2/",
+			EnvironmentVariables: []string{},
+			ExposedPorts:         []string{"5000:5000"},
+		})
+
+	require.Equal(t, expectedContainer, NewRegistryContainer())
+}
@@ -0,0 +1,56 @@
+package presets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// searchEngineAPIErrorLimit limits the size of search engine API error response body included in errors.
+const searchEngineAPIErrorLimit = 1024
+
+var errSearchEngineAPI = errors.New("search engine API request failed")
+
+// searchEngineHTTPClient is used to send requests to search engine admin APIs.
+var searchEngineHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON sends a POST request with the given body encoded as JSON to path of the HTTP API served on the given
+// port of the started container, e.g. to create a collection after the container start. Returns an error if
+// the response status is not 2xx.
+func postJSON(ctx context.Context, c docker.Container, containerPort, path string, header http.Header, body any) error {
+	endpoint, err := c.HTTPEndpoint(ctx, containerPort)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := searchEngineHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, searchEngineAPIErrorLimit))
+		return errors.Wrapf(errSearchEngineAPI, "POST %s: %d %s", path, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	io.Copy(io.Discard, resp.Body) // nolint: errcheck
+	return nil
+}
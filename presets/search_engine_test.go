@@ -0,0 +1,69 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// stubContainer is a started [docker.Container] stub with HTTP API served at endpoint, the given environment,
+// and exec results. Other methods are not implemented.
+type stubContainer struct {
+	docker.Container
+	endpoint string
+	env      map[string]string
+	result   docker.ExecResult
+	scripts  []string
+}
+
+func (c *stubContainer) HTTPEndpoint(context.Context, string) (string, error) {
+	return c.endpoint, nil
+}
+
+func (c *stubContainer) Env(context.Context) (map[string]string, error) {
+	return c.env, nil
+}
+
+func (c *stubContainer) ExecScript(_ context.Context, script string) (docker.ExecResult, error) {
+	c.scripts = append(c.scripts, script)
+	return c.result, nil
+}
+
+func TestPostJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		expectedError string
+	}{
+		{"created", http.StatusCreated, ""},
+		{"conflict", http.StatusConflict, "POST /collections: 409 already exists"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte("already exists\n"))
+			}))
+			defer server.Close()
+
+			c := &stubContainer{endpoint: server.URL}
+			err := postJSON(context.Background(), c, "8108", "/collections", http.Header{"X-Key": []string{"key"}}, map[string]string{})
+			if len(test.expectedError) > 0 {
+				require.ErrorIs(t, err, errSearchEngineAPI)
+				require.ErrorContains(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, "key", header.Get("X-Key"))
+			require.Equal(t, "application/json", header.Get("Content-Type"))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package presets
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+var errInvalidPresetName = errors.New("invalid preset name")
+
+// NewServiceContainer returns a preset [github.com/ygrebnov/testutils/docker.Container] object loaded from
+// the bundled preset values file with the given name, e.g. `toxiproxy` or `toxiproxy.yaml`, so that a new
+// non-database preset needs only a yaml file.
+func NewServiceContainer(yamlName string) (docker.Container, error) {
+	name := strings.TrimSuffix(yamlName, ".yaml")
+	if len(name) == 0 || strings.ContainsAny(name, `/\`) {
+		return nil, errors.Wrap(errInvalidPresetName, yamlName)
+	}
+	dir, err := presetsDir()
+	if err != nil {
+		return nil, err
+	}
+	p := new(defaultContainerPreset)
+	if err = readPresetValues(filepath.Join(dir, name+".yaml"), p); err != nil {
+		return nil, errors.Wrap(err, yamlName)
+	}
+	if err = p.validate(); err != nil {
+		return nil, errors.Wrap(err, yamlName)
+	}
+	return p.asContainer(), nil
+}
@@ -0,0 +1,38 @@
+package presets
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceContainer(t *testing.T) {
+	for _, name := range []string{"toxiproxy", "toxiproxy.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewServiceContainer(name)
+			require.NoError(t, err)
+			require.Equal(t, NewToxiproxyContainer(), c)
+		})
+	}
+}
+
+func TestNewServiceContainerError(t *testing.T) {
+	tests := []struct {
+		name      string
+		yamlName  string
+		expectErr error
+	}{
+		{"empty", "", errInvalidPresetName},
+		{"parent_dir", "../presets/registry", errInvalidPresetName},
+		{"unknown", "unknown", os.ErrNotExist},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := NewServiceContainer(test.yamlName)
+			require.ErrorIs(t, err, test.expectErr)
+			require.Nil(t, c)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package presets
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+var (
+	errInvalidSolrCoreName = errors.New("invalid solr core name")
+	errSolrCoreCreate      = errors.New("solr core creation failure")
+
+	// solrCoreName matches names accepted by Solr for cores.
+	solrCoreName = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+)
+
+var solrPreset = newContainerPreset("solr.yaml")
+
+// NewCustomizedSolrContainer returns a preset Solr [github.com/ygrebnov/testutils/docker.Container] object
+// with customized options values.
+func NewCustomizedSolrContainer(options docker.Options) docker.Container {
+	return solrPreset.asCustomizedContainer(options)
+}
+
+// NewSolrContainer returns a preset Solr [github.com/ygrebnov/testutils/docker.Container] object listening
+// on port 8983 in standalone mode. Cores can be created in the started container using [CreateSolrCore].
+func NewSolrContainer() docker.Container {
+	return solrPreset.asContainer()
+}
+
+// CreateSolrCore creates a core with the given name and the default configset in the started Solr container
+// using `solr create_core` command.
+func CreateSolrCore(ctx context.Context, c docker.Container, name string) error {
+	if !solrCoreName.MatchString(name) {
+		return errors.Wrap(errInvalidSolrCoreName, name)
+	}
+	result, err := c.ExecScript(ctx, "solr create_core -c "+name)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return errors.Wrapf(errSolrCoreCreate, "%s: exit code %d: %s", name, result.ExitCode,
+			strings.TrimSpace(result.Stdout+result.Stderr))
+	}
+	return nil
+}
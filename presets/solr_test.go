@@ -0,0 +1,50 @@
+package presets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestSolrPreset(t *testing.T) {
+	expectedContainer := docker.NewContainerWithOptions(
+		"solr:9",
+		docker.Options{
+			EnvironmentVariables: []string{},
+			ExposedPorts:         []string{"8983:8983"},
+			Healthcheck:          "wget -q -O /dev/null http://localhost:8983/solr/admin/info/system",
+		})
+
+	require.Equal(t, expectedContainer, NewSolrContainer())
+}
+
+func TestCreateSolrCore(t *testing.T) {
+	tests := []struct {
+		name            string
+		core            string
+		result          docker.ExecResult
+		expectedError   error
+		expectedScripts []string
+	}{
+		{"created", "books", docker.ExecResult{}, nil, []string{"solr create_core -c books"}},
+		{"exists", "books", docker.ExecResult{ExitCode: 1, Stdout: "Core 'books' already exists!"}, errSolrCoreCreate,
+			[]string{"solr create_core -c books"}},
+		{"invalid_name", "books; rm -rf /", docker.ExecResult{}, errInvalidSolrCoreName, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &stubContainer{result: test.result}
+			err := CreateSolrCore(context.Background(), c, test.core)
+			if test.expectedError != nil {
+				require.ErrorIs(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.expectedScripts, c.scripts)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var temporalPreset = newContainerPreset("temporal.yaml")
+
+// NewCustomizedTemporalContainer returns a preset Temporal development server
+// [github.com/ygrebnov/testutils/docker.Container] object with customized options values.
+func NewCustomizedTemporalContainer(options docker.Options) docker.Container {
+	return temporalPreset.asCustomizedContainer(options)
+}
+
+// NewTemporalContainer returns a preset Temporal development server [github.com/ygrebnov/testutils/docker.Container]
+// object with gRPC frontend exposed on port 7233 and web UI on port 8233. The server keeps its state in an in-memory
+// SQLite database and creates the `default` namespace. It is considered started once `temporal operator cluster health`
+// reports the frontend as serving.
+func NewTemporalContainer() docker.Container {
+	return temporalPreset.asContainer()
+}
@@ -0,0 +1,25 @@
+//go:build e2e
+
+package presets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// TestTemporalStart starts a Temporal development server container and checks that its frontend is serving.
+// It requires a running Docker daemon and is run with `go test -tags e2e ./presets/`.
+func TestTemporalStart(t *testing.T) {
+	ctx := context.Background()
+	temporal := NewCustomizedTemporalContainer(docker.Options{Name: "testutils-e2e-temporal", ExposedPorts: []string{":7233"}})
+	require.NoError(t, temporal.CreateStart(ctx))
+	defer func() { require.NoError(t, temporal.StopRemove(ctx)) }()
+
+	result, err := temporal.ExecScript(ctx, "temporal operator namespace describe --address 127.0.0.1:7233 default")
+	require.NoError(t, err)
+	require.Equal(t, 0, result.ExitCode, result.Stderr)
+}
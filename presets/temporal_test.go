@@ -0,0 +1,29 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+var expectedTemporalOptions = docker.Options{
+	Healthcheck:          "temporal operator cluster health --address 127.0.0.1:7233",
+	EnvironmentVariables: []string{},
+	ExposedPorts:         []string{"7233:7233", "8233:8233"},
+	Cmd:                  []string{"server", "start-dev", "--ip", "0.0.0.0"},
+}
+
+func TestTemporalPreset(t *testing.T) {
+	require.Equal(t, docker.NewContainerWithOptions("temporalio/temporal", expectedTemporalOptions), NewTemporalContainer())
+}
+
+func TestCustomizedTemporalPreset(t *testing.T) {
+	options := expectedTemporalOptions
+	options.Name = "temporal"
+	options.Cmd = []string{"server", "start-dev", "--ip", "0.0.0.0", "--namespace", "orders"}
+	expectedContainer := docker.NewContainerWithOptions("temporalio/temporal", options)
+
+	require.Equal(t, expectedContainer, NewCustomizedTemporalContainer(docker.Options{Name: "temporal", Cmd: options.Cmd}))
+}
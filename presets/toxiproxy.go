@@ -0,0 +1,18 @@
+package presets
+
+import "github.com/ygrebnov/testutils/docker"
+
+var toxiproxyPreset = newContainerPreset("toxiproxy.yaml")
+
+// NewCustomizedToxiproxyContainer returns a preset toxiproxy [github.com/ygrebnov/testutils/docker.Container]
+// object with customized options values.
+func NewCustomizedToxiproxyContainer(options docker.Options) docker.Container {
+	return toxiproxyPreset.asCustomizedContainer(options)
+}
+
+// NewToxiproxyContainer returns a preset toxiproxy [github.com/ygrebnov/testutils/docker.Container] object
+// with HTTP API exposed on port 8474. Proxies in front of other containers can be created without a separate
+// toxiproxy container using [github.com/ygrebnov/testutils/docker.ProxiedPort].
+func NewToxiproxyContainer() docker.Container {
+	return toxiproxyPreset.asContainer()
+}
@@ -0,0 +1,54 @@
+//go:build e2e
+
+package presets
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+// postgresResponds sends PostgreSQL SSLRequest message to the given address and reports whether a reply is received.
+func postgresResponds(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second)) // nolint: errcheck
+	if _, err = conn.Write([]byte{0, 0, 0, 8, 4, 210, 22, 47}); err != nil {
+		return false
+	}
+	reply := make([]byte, 1)
+	_, err = conn.Read(reply)
+	return err == nil
+}
+
+// TestToxiproxyCutRestore proxies a PostgreSQL container port, cuts the proxy, and restores it.
+// It requires a running Docker daemon and is run with `go test -tags e2e ./presets/`.
+func TestToxiproxyCutRestore(t *testing.T) {
+	ctx := context.Background()
+	db := NewCustomizedPostgresqlContainer(docker.Options{Name: "testutils-e2e-toxiproxy-db", ExposedPorts: []string{":5432"}})
+	require.NoError(t, db.CreateStart(ctx))
+	defer func() { require.NoError(t, db.StopRemove(ctx)) }()
+	defer func() { require.NoError(t, docker.StopToxiproxy(ctx)) }()
+
+	proxy, err := docker.ProxiedPort(ctx, db, "5432")
+	require.NoError(t, err)
+	require.True(t, postgresResponds(proxy.Endpoint()))
+
+	require.NoError(t, proxy.Cut(ctx))
+	require.False(t, postgresResponds(proxy.Endpoint()))
+
+	require.NoError(t, proxy.Restore(ctx))
+	require.NoError(t, proxy.AddLatency(ctx, 100*time.Millisecond))
+	start := time.Now()
+	require.True(t, postgresResponds(proxy.Endpoint()))
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.NoError(t, proxy.Close(ctx))
+}
@@ -0,0 +1,20 @@
+package presets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestToxiproxyPreset(t *testing.T) {
+	expectedContainer := docker.NewContainerWithOptions(
+		"ghcr.io/shopify/toxiproxy:2.5.0",
+		docker.Options{
+			EnvironmentVariables: []string{},
+			ExposedPorts:         []string{"8474:8474"},
+		})
+
+	require.Equal(t, expectedContainer, NewToxiproxyContainer())
+}
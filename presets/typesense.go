@@ -0,0 +1,43 @@
+package presets
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+const (
+	// typesenseAPIPort is the Typesense HTTP API container port.
+	typesenseAPIPort = "8108"
+	// typesenseAPIKeyEnv is the name of the environment variable holding Typesense API key.
+	typesenseAPIKeyEnv = "TYPESENSE_API_KEY"
+)
+
+var typesensePreset = newContainerPreset("typesense.yaml")
+
+// NewCustomizedTypesenseContainer returns a preset Typesense [github.com/ygrebnov/testutils/docker.Container]
+// object with customized options values.
+func NewCustomizedTypesenseContainer(options docker.Options) docker.Container {
+	return typesensePreset.asCustomizedContainer(options)
+}
+
+// NewTypesenseContainer returns a preset Typesense [github.com/ygrebnov/testutils/docker.Container] object
+// with HTTP API exposed on port 8108 and API key set to `typesense`. Collections can be created in the started
+// container using [CreateTypesenseCollection].
+func NewTypesenseContainer() docker.Container {
+	return typesensePreset.asContainer()
+}
+
+// CreateTypesenseCollection creates a collection with the given schema, e.g.
+// `map[string]any{"name": "books", "fields": []map[string]string{{"name": "title", "type": "string"}}}`,
+// in the started Typesense container using its HTTP API. The API key is read from the container
+// `TYPESENSE_API_KEY` environment variable.
+func CreateTypesenseCollection(ctx context.Context, c docker.Container, schema any) error {
+	env, err := c.Env(ctx)
+	if err != nil {
+		return err
+	}
+	header := http.Header{"X-Typesense-Api-Key": []string{env[typesenseAPIKeyEnv]}}
+	return postJSON(ctx, c, typesenseAPIPort, "/collections", header, schema)
+}
@@ -0,0 +1,44 @@
+package presets
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ygrebnov/testutils/docker"
+)
+
+func TestTypesensePreset(t *testing.T) {
+	expectedContainer := docker.NewContainerWithOptions(
+		"typesense/typesense:27.1",
+		docker.Options{
+			EnvironmentVariables: []string{"TYPESENSE_API_KEY=typesense", "TYPESENSE_DATA_DIR=/data"},
+			ExposedPorts:         []string{"8108:8108"},
+			Healthcheck: `bash -c 'exec 3<>/dev/tcp/127.0.0.1/8108 && printf "GET /health HTTP/1.0\r\n\r\n" >&3 && ` +
+				`grep -q true <&3'`,
+		})
+
+	require.Equal(t, expectedContainer, NewTypesenseContainer())
+}
+
+func TestCreateTypesenseCollection(t *testing.T) {
+	var path, apiKey, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, apiKey = r.URL.Path, r.Header.Get("X-Typesense-Api-Key")
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	c := &stubContainer{endpoint: server.URL, env: map[string]string{"TYPESENSE_API_KEY": "secret"}}
+	schema := map[string]any{"name": "books", "fields": []map[string]string{{"name": "title", "type": "string"}}}
+	require.NoError(t, CreateTypesenseCollection(context.Background(), c, schema))
+	require.Equal(t, "/collections", path)
+	require.Equal(t, "secret", apiKey)
+	require.JSONEq(t, `{"name":"books","fields":[{"name":"title","type":"string"}]}`, body)
+}